@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
+)
+
+const (
+	defaultListenAddr = "127.0.0.1:8787"
+	defaultAPIKey     = "fake-api-key"
+	readHeaderTimeout = 10 * time.Second
+)
+
+func main() {
+	listen := flag.String("listen", defaultListenAddr, "address to listen on")
+	apiKey := flag.String("api-key", defaultAPIKey, "root API key accepted by the fake server")
+	teams := flag.String("teams", "", "comma separated list of team IDs the API key is a member of, "+
+		"each optionally followed by =slug")
+	rateLimit := flag.Int("rate-limit", fakevercel.DefaultRateLimit, "requests allowed per endpoint per window")
+	rateLimitWindow := flag.Duration("rate-limit-window", fakevercel.DefaultRateLimitWindow, "rate limit window")
+
+	flag.Parse()
+
+	logger := hclog.New(&hclog.LoggerOptions{Name: "fake-vercel"})
+
+	teamIDs, teamSlugs := parseTeams(*teams)
+
+	srv := &http.Server{
+		Addr: *listen,
+		Handler: fakevercel.New(fakevercel.Config{
+			APIKey:          *apiKey,
+			Teams:           teamIDs,
+			TeamSlugs:       teamSlugs,
+			RateLimit:       *rateLimit,
+			RateLimitWindow: *rateLimitWindow,
+		}),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	logger.Info("fake Vercel API listening", "base_url", "http://"+*listen)
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("fake Vercel API shutting down", "error", err)
+		os.Exit(1)
+	}
+}
+
+func splitList(s string) []string {
+	var out []string
+
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
+
+// parseTeams splits "id=slug" entries into team IDs and their slugs.
+func parseTeams(s string) ([]string, map[string]string) {
+	var ids []string
+
+	slugs := make(map[string]string)
+
+	for _, v := range splitList(s) {
+		id, slug, _ := strings.Cut(v, "=")
+		ids = append(ids, id)
+
+		if slug != "" {
+			slugs[id] = slug
+		}
+	}
+
+	return ids, slugs
+}
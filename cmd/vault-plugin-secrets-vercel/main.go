@@ -1,31 +1,55 @@
 package main
 
 import (
+	"context"
 	"os"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/sdk/plugin"
 	vercelPlugin "github.com/thevilledev/vault-plugin-secrets-vercel/internal/plugin"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/tracing"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/version"
 )
 
+const tracingShutdownTimeout = 5 * time.Second
+
 func main() {
+	if err := run(); err != nil {
+		fatal(err)
+	}
+}
+
+// run serves the plugin until it is stopped. Errors are returned rather than
+// exiting, so that the deferred tracing shutdown flushes pending spans.
+func run() error {
 	apiClientMeta := &api.PluginAPIClientMeta{}
 
 	flags := apiClientMeta.FlagSet()
 	if err := flags.Parse(os.Args[1:]); err != nil {
-		fatal(err)
+		return err
 	}
 
 	tlsConfig := apiClientMeta.GetTLSConfig()
 	tlsProviderFunc := api.VaultPluginTLSProvider(tlsConfig)
 
-	if err := plugin.Serve(&plugin.ServeOpts{
+	shutdownTracing, err := tracing.Setup(context.Background(), version.RunningVersion())
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+
+		_ = shutdownTracing(ctx)
+	}()
+
+	return plugin.Serve(&plugin.ServeOpts{
 		BackendFactoryFunc: vercelPlugin.Factory,
 		TLSProviderFunc:    tlsProviderFunc,
-	}); err != nil {
-		fatal(err)
-	}
+	})
 }
 
 func fatal(err error) {
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/maintenance"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+const (
+	envAPIKey = "VERCEL_API_KEY"
+	// maxAttempts is how often each Vercel API request is tried, as the tool
+	// is typically run while things are already failing.
+	maxAttempts = 3
+	usage       = `Usage: vercel-token-maintenance <list|delete> [flags]
+
+Finds and deletes Vercel tokens created by the Vault plugin directly through the
+Vercel API, for use when Vault is unavailable. The root API key is read from the
+VERCEL_API_KEY environment variable.
+
+Commands:
+  list    List matching tokens with their team, age and expiry.
+  delete  Delete matching tokens. Use -dry-run to only show what would be deleted.
+          Without a -prefix, -team or -older-than filter, -all is required.
+
+Flags:
+`
+)
+
+var (
+	errMissingAPIKey = errors.New(envAPIKey + " is not set")
+	errDryRunList    = errors.New("-dry-run only applies to delete")
+	errNoFilter      = errors.New("delete without a -prefix, -team or -older-than filter " +
+		"deletes every token created by the plugin, pass -all to confirm")
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cmd := os.Args[1]
+
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	baseURL := fs.String("base-url", vercel.DefaultBaseURL, "Vercel API base URL, without an API version")
+	prefix := fs.String("prefix", maintenance.DefaultPrefix, "token name prefix, starting with "+maintenance.DefaultPrefix)
+	team := fs.String("team", "", `only tokens scoped to this team ID, or "personal" for personal account scope`)
+	olderThan := fs.Duration("older-than", 0, "only tokens created at least this long ago, e.g. 24h")
+	dryRun := fs.Bool("dry-run", false, "delete: show what would be deleted without deleting")
+	all := fs.Bool("all", false, "delete: allow deleting every token created by the plugin when no filter is set")
+
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+
+	if cmd != "list" && cmd != "delete" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	_ = fs.Parse(os.Args[2:])
+
+	f := maintenance.Filter{
+		Prefix:    *prefix,
+		TeamID:    *team,
+		OlderThan: *olderThan,
+	}
+
+	if err := validate(cmd, f, *dryRun, *all); err != nil {
+		fmt.Fprintln(os.Stderr, "vercel-token-maintenance:", err)
+		os.Exit(2)
+	}
+
+	if err := run(cmd, *baseURL, f, *dryRun); err != nil {
+		fmt.Fprintln(os.Stderr, "vercel-token-maintenance:", err)
+		os.Exit(1)
+	}
+}
+
+// validate rejects flag combinations that are ignored or too broad: tokens not
+// created by the plugin are never selected, and deleting every plugin token
+// must be asked for explicitly.
+func validate(cmd string, f maintenance.Filter, dryRun, all bool) error {
+	if cmd == "list" && dryRun {
+		return errDryRunList
+	}
+
+	if err := f.Validate(); err != nil {
+		return err
+	}
+
+	filtered := f.Prefix != maintenance.DefaultPrefix || f.TeamID != "" || f.OlderThan > 0
+	if cmd == "delete" && !filtered && !all {
+		return errNoFilter
+	}
+
+	return nil
+}
+
+func run(cmd, baseURL string, f maintenance.Filter, dryRun bool) error {
+	apiKey := os.Getenv(envAPIKey)
+	if apiKey == "" {
+		return errMissingAPIKey
+	}
+
+	ctx := context.Background()
+	c := vercel.NewClient(apiKey,
+		vercel.WithBaseURL(vercel.TrimAPIVersion(baseURL)),
+		vercel.WithRetry(vercel.RetryPolicy{MaxAttempts: maxAttempts}),
+	)
+
+	tokens, err := maintenance.List(ctx, c, f)
+	if err != nil {
+		return err
+	}
+
+	if cmd == "list" {
+		return maintenance.WriteTable(os.Stdout, tokens, time.Now())
+	}
+
+	results := maintenance.Delete(ctx, c, tokens, dryRun)
+	if err = maintenance.WriteResults(os.Stdout, results, dryRun); err != nil {
+		return err
+	}
+
+	failed := 0
+
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d tokens", failed, len(results))
+	}
+
+	return nil
+}
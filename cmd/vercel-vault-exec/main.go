@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/vaultexec"
+)
+
+const usage = `Usage: vercel-vault-exec [flags] -- command [args...]
+
+Leases a Vercel token from the Vault plugin, runs the command with VERCEL_TOKEN,
+VERCEL_ORG_ID and VERCEL_PROJECT_ID set, and revokes the lease when it exits.
+Vault is configured through the standard VAULT_ADDR and VAULT_TOKEN variables.
+
+Flags:
+`
+
+func main() {
+	mount := flag.String("mount", vaultexec.DefaultMount, "path the Vercel secrets plugin is mounted at")
+	ttl := flag.Duration("ttl", 0, "token TTL, defaults to the max_ttl of the mount")
+	teamID := flag.String("team-id", "", "Vercel team ID to scope the token to")
+	projectID := flag.String("project-id", "", "Vercel project ID exported as VERCEL_PROJECT_ID")
+
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	c, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "vercel-vault-exec:", err)
+		os.Exit(1)
+	}
+
+	code, err := vaultexec.Run(context.Background(), vaultexec.Config{
+		Client:    c,
+		Mount:     *mount,
+		TTL:       *ttl,
+		TeamID:    *teamID,
+		ProjectID: *projectID,
+		Command:   flag.Arg(0),
+		Args:      flag.Args()[1:],
+		Stdin:     os.Stdin,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "vercel-vault-exec:", err)
+	}
+
+	os.Exit(code)
+}
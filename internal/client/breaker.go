@@ -0,0 +1,312 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// ErrCircuitOpen is matched by the errors of requests failed fast by an
+// open circuit breaker, without calling Vercel.
+var ErrCircuitOpen = errors.New("upstream unavailable: vercel api circuit breaker is open")
+
+// CircuitOpenError is returned for requests failed fast by a CircuitBreaker.
+type CircuitOpenError struct {
+	// RetryAfter is how long until the breaker lets a probe request through.
+	// It is zero while a probe request is in flight.
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return ErrCircuitOpen.Error()
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState string
+
+const (
+	// BreakerClosed lets every request through.
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen fails every request fast until the cooldown has passed.
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets a single probe request through. Its outcome
+	// closes or opens the breaker again.
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// BreakerConfig controls when a CircuitBreaker opens and recovers.
+// Zero values fall back to the defaults.
+type BreakerConfig struct {
+	// Threshold is the number of consecutive failures that opens the breaker.
+	Threshold int
+	// Cooldown is how long the breaker stays open before probing Vercel again.
+	Cooldown time.Duration
+	// OnStateChange, if set, is called on every state change.
+	OnStateChange func(from, to BreakerState)
+}
+
+// CircuitBreaker is a Client failing fast during Vercel outages. Vercel 5xx
+// responses, timeouts and network errors count as failures. Other responses,
+// including 4xx errors, show that Vercel is up and reset the failure count.
+// It is safe for concurrent use.
+type CircuitBreaker struct {
+	client Client
+	cfg    BreakerConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+var _ Client = (*CircuitBreaker)(nil)
+
+// NewCircuitBreaker wraps c with a circuit breaker.
+func NewCircuitBreaker(c Client, cfg BreakerConfig) *CircuitBreaker {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = DefaultBreakerThreshold
+	}
+
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = DefaultBreakerCooldown
+	}
+
+	return &CircuitBreaker{
+		client: c,
+		cfg:    cfg,
+		now:    time.Now,
+		state:  BreakerClosed,
+	}
+}
+
+// State returns the current state of the breaker.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// allow reports whether a request may be sent, moving an open breaker to
+// half-open once the cooldown has passed.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		retryIn := b.openedAt.Add(b.cfg.Cooldown).Sub(b.now())
+		if retryIn > 0 {
+			return &CircuitOpenError{RetryAfter: retryIn}
+		}
+
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return &CircuitOpenError{}
+		}
+
+		b.probing = true
+
+		return nil
+	default:
+		return nil
+	}
+}
+
+// record updates the breaker with the outcome of a request.
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	probe := b.state == BreakerHalfOpen
+	if probe {
+		b.probing = false
+	}
+
+	// A probe cancelled by the caller says nothing about Vercel, so the next
+	// request probes again.
+	if probe && errors.Is(err, context.Canceled) {
+		return
+	}
+
+	if !isOutage(err) {
+		b.failures = 0
+
+		if probe {
+			b.setState(BreakerClosed)
+		}
+
+		return
+	}
+
+	b.failures++
+
+	if probe || b.failures >= b.cfg.Threshold {
+		b.openedAt = b.now()
+		b.setState(BreakerOpen)
+	}
+}
+
+func (b *CircuitBreaker) setState(s BreakerState) {
+	if b.state == s {
+		return
+	}
+
+	from := b.state
+	b.state = s
+
+	if b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(from, s)
+	}
+}
+
+// isOutage reports whether err suggests that Vercel is unavailable.
+// Requests cancelled by the caller do not count.
+func isOutage(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, vercel.ErrUpstream) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var httpErr *vercel.HTTPError
+	if errors.As(err, &httpErr) {
+		return false
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
+
+func breakerCall[T any](b *CircuitBreaker, fn func() (T, error)) (T, error) {
+	if err := b.allow(); err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	res, err := fn()
+	b.record(err)
+
+	return res, err
+}
+
+func breakerDo(b *CircuitBreaker, fn func() error) error {
+	_, err := breakerCall(b, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+
+	return err
+}
+
+func (b *CircuitBreaker) GetBaseURL() string {
+	return b.client.GetBaseURL()
+}
+
+func (b *CircuitBreaker) RateLimits() []vercel.RateLimit {
+	return b.client.RateLimits()
+}
+
+func (b *CircuitBreaker) CreateAuthToken(ctx context.Context,
+	req *vercel.CreateAuthTokenRequest) (*vercel.CreateAuthTokenResponse, error) {
+	return breakerCall(b, func() (*vercel.CreateAuthTokenResponse, error) {
+		return b.client.CreateAuthToken(ctx, req)
+	})
+}
+
+func (b *CircuitBreaker) DeleteAuthToken(ctx context.Context,
+	req *vercel.DeleteAuthTokenRequest) (*vercel.DeleteAuthTokenResponse, error) {
+	return breakerCall(b, func() (*vercel.DeleteAuthTokenResponse, error) {
+		return b.client.DeleteAuthToken(ctx, req)
+	})
+}
+
+func (b *CircuitBreaker) GetAuthToken(ctx context.Context,
+	req *vercel.GetAuthTokenRequest) (*vercel.GetAuthTokenResponse, error) {
+	return breakerCall(b, func() (*vercel.GetAuthTokenResponse, error) {
+		return b.client.GetAuthToken(ctx, req)
+	})
+}
+
+func (b *CircuitBreaker) ListAuthTokens(ctx context.Context,
+	req *vercel.ListAuthTokensRequest) (*vercel.ListAuthTokensResponse, error) {
+	return breakerCall(b, func() (*vercel.ListAuthTokensResponse, error) {
+		return b.client.ListAuthTokens(ctx, req)
+	})
+}
+
+func (b *CircuitBreaker) ListProjectEnv(ctx context.Context,
+	req *vercel.ListProjectEnvRequest) (*vercel.ListProjectEnvResponse, error) {
+	return breakerCall(b, func() (*vercel.ListProjectEnvResponse, error) {
+		return b.client.ListProjectEnv(ctx, req)
+	})
+}
+
+func (b *CircuitBreaker) CreateProtectionBypass(ctx context.Context,
+	req *vercel.CreateProtectionBypassRequest) (*vercel.ProtectionBypassResponse, error) {
+	return breakerCall(b, func() (*vercel.ProtectionBypassResponse, error) {
+		return b.client.CreateProtectionBypass(ctx, req)
+	})
+}
+
+func (b *CircuitBreaker) RevokeProtectionBypass(ctx context.Context,
+	req *vercel.RevokeProtectionBypassRequest) (*vercel.ProtectionBypassResponse, error) {
+	return breakerCall(b, func() (*vercel.ProtectionBypassResponse, error) {
+		return b.client.RevokeProtectionBypass(ctx, req)
+	})
+}
+
+func (b *CircuitBreaker) CreateEdgeConfigToken(ctx context.Context,
+	req *vercel.CreateEdgeConfigTokenRequest) (*vercel.CreateEdgeConfigTokenResponse, error) {
+	return breakerCall(b, func() (*vercel.CreateEdgeConfigTokenResponse, error) {
+		return b.client.CreateEdgeConfigToken(ctx, req)
+	})
+}
+
+func (b *CircuitBreaker) DeleteEdgeConfigTokens(ctx context.Context, req *vercel.DeleteEdgeConfigTokensRequest) error {
+	return breakerDo(b, func() error {
+		return b.client.DeleteEdgeConfigTokens(ctx, req)
+	})
+}
+
+func (b *CircuitBreaker) CreateWebhook(ctx context.Context,
+	req *vercel.CreateWebhookRequest) (*vercel.CreateWebhookResponse, error) {
+	return breakerCall(b, func() (*vercel.CreateWebhookResponse, error) {
+		return b.client.CreateWebhook(ctx, req)
+	})
+}
+
+func (b *CircuitBreaker) DeleteWebhook(ctx context.Context, req *vercel.DeleteWebhookRequest) error {
+	return breakerDo(b, func() error {
+		return b.client.DeleteWebhook(ctx, req)
+	})
+}
+
+func (b *CircuitBreaker) ListTeams(ctx context.Context,
+	req *vercel.ListTeamsRequest) (*vercel.ListTeamsResponse, error) {
+	return breakerCall(b, func() (*vercel.ListTeamsResponse, error) {
+		return b.client.ListTeams(ctx, req)
+	})
+}
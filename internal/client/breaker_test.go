@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+func TestIsOutage(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		err error
+		exp bool
+	}{
+		"success":      {nil, false},
+		"upstream":     {&vercel.HTTPError{StatusCode: http.StatusServiceUnavailable}, true},
+		"rate limited": {&vercel.HTTPError{StatusCode: http.StatusTooManyRequests}, false},
+		"not found":    {&vercel.HTTPError{StatusCode: http.StatusNotFound}, false},
+		"timeout":      {fmt.Errorf("request: %w", context.DeadlineExceeded), true},
+		"canceled":     {fmt.Errorf("request: %w", context.Canceled), false},
+		"network":      {&netTimeoutError{}, true},
+		"other":        {errors.New("invalid request"), false},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.exp, isOutage(tc.err))
+		})
+	}
+}
+
+type netTimeoutError struct{}
+
+func (e *netTimeoutError) Error() string   { return "i/o timeout" }
+func (e *netTimeoutError) Timeout() bool   { return true }
+func (e *netTimeoutError) Temporary() bool { return true }
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mock := NewMockClientWithConfig(MockConfig{
+		CreateScript: []int{503, 404, 503, 503, 503, 503, 200, 200},
+	})
+
+	var transitions []string
+
+	b := NewCircuitBreaker(mock, BreakerConfig{
+		Threshold: 3,
+		Cooldown:  time.Minute,
+		OnStateChange: func(from, to BreakerState) {
+			transitions = append(transitions, string(from)+"->"+string(to))
+		},
+	})
+
+	now := time.Unix(1_700_000_000, 0)
+	b.now = func() time.Time { return now }
+
+	create := func() error {
+		_, err := b.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{Name: "foo"})
+
+		return err
+	}
+
+	// A 4xx response resets the failure count.
+	require.ErrorIs(t, create(), vercel.ErrUpstream)
+	require.ErrorIs(t, create(), vercel.ErrNotFound)
+	require.ErrorIs(t, create(), vercel.ErrUpstream)
+	require.ErrorIs(t, create(), vercel.ErrUpstream)
+	require.Equal(t, BreakerClosed, b.State())
+
+	// The third consecutive failure opens the breaker.
+	require.ErrorIs(t, create(), vercel.ErrUpstream)
+	require.Equal(t, BreakerOpen, b.State())
+
+	// Requests fail fast without reaching the client.
+	now = now.Add(20 * time.Second)
+
+	err := create()
+	require.ErrorIs(t, err, ErrCircuitOpen)
+
+	var openErr *CircuitOpenError
+
+	require.ErrorAs(t, err, &openErr)
+	require.Equal(t, 40*time.Second, openErr.RetryAfter)
+
+	// After the cooldown a failing probe opens the breaker again.
+	now = now.Add(40 * time.Second)
+
+	require.ErrorIs(t, create(), vercel.ErrUpstream)
+	require.Equal(t, BreakerOpen, b.State())
+	require.ErrorIs(t, create(), ErrCircuitOpen)
+
+	// A successful probe closes it.
+	now = now.Add(time.Minute)
+
+	require.NoError(t, create())
+	require.Equal(t, BreakerClosed, b.State())
+	require.NoError(t, create())
+
+	require.Equal(t, []string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	}, transitions)
+}
+
+func TestCircuitBreaker_SingleProbe(t *testing.T) {
+	t.Parallel()
+
+	b := NewCircuitBreaker(NewMockClient(), BreakerConfig{Threshold: 1})
+
+	now := time.Unix(1_700_000_000, 0)
+	b.now = func() time.Time { return now }
+
+	b.record(&vercel.HTTPError{StatusCode: http.StatusBadGateway})
+	require.Equal(t, BreakerOpen, b.State())
+
+	now = now.Add(DefaultBreakerCooldown)
+
+	// Only one request is let through while the breaker is half-open.
+	require.NoError(t, b.allow())
+	require.Equal(t, BreakerHalfOpen, b.State())
+
+	err := b.allow()
+	require.ErrorIs(t, err, ErrCircuitOpen)
+
+	var openErr *CircuitOpenError
+
+	require.ErrorAs(t, err, &openErr)
+	require.Zero(t, openErr.RetryAfter)
+
+	b.record(nil)
+	require.Equal(t, BreakerClosed, b.State())
+	require.NoError(t, b.allow())
+}
+
+func TestCircuitBreaker_CancelledProbe(t *testing.T) {
+	t.Parallel()
+
+	b := NewCircuitBreaker(NewMockClient(), BreakerConfig{Threshold: 1})
+
+	now := time.Unix(1_700_000_000, 0)
+	b.now = func() time.Time { return now }
+
+	b.record(&vercel.HTTPError{StatusCode: http.StatusBadGateway})
+	now = now.Add(DefaultBreakerCooldown)
+
+	// A cancelled probe neither closes nor reopens the breaker.
+	require.NoError(t, b.allow())
+	b.record(fmt.Errorf("create token: %w", context.Canceled))
+	require.Equal(t, BreakerHalfOpen, b.State())
+
+	// The next request is let through as a new probe.
+	require.NoError(t, b.allow())
+	require.ErrorIs(t, b.allow(), ErrCircuitOpen)
+
+	b.record(&vercel.HTTPError{StatusCode: http.StatusBadGateway})
+	require.Equal(t, BreakerOpen, b.State())
+}
@@ -3,55 +3,473 @@ package client
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"sync"
 	"time"
+
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
 )
 
+const mockFailureBody = `{"error":{"code":"mock_failure","message":"failure injected by mock client"}}`
+
+// MockConfig controls the faults injected by MockClient.
+type MockConfig struct {
+	// Latency is added to every create and delete call.
+	Latency time.Duration
+	// CreateErrorRate is the probability, between 0 and 1, of a create call failing.
+	CreateErrorRate float64
+	// DeleteErrorRate is the probability, between 0 and 1, of a delete call failing.
+	DeleteErrorRate float64
+	// CreateScript lists HTTP status codes returned by consecutive create calls
+	// before the error rate applies. Zero or a 2xx code means success.
+	CreateScript []int
+	// DeleteScript is the delete counterpart of CreateScript.
+	DeleteScript []int
+	// Teams lists the teams the mock API key has access to. Nil uses MockTeams.
+	Teams []vercel.Team
+}
+
+// MockTeams are the teams of a MockClient configured without teams.
+var MockTeams = []vercel.Team{
+	{ID: "team_a", Slug: "team-a", Name: "Team A"},
+	{ID: "team_b", Slug: "team-b", Name: "Team B"},
+}
+
+// MockClient is an in-memory Client that never talks to the Vercel API.
+// It is safe for concurrent use.
 type MockClient struct {
-	tokens map[string]any
+	mu           sync.Mutex
+	cfg          MockConfig
+	tokens       map[string]vercel.Token
+	bypasses     map[string]map[string]vercel.ProtectionBypass
+	edgeTokens   map[string]map[string]string
+	webhooks     map[string]vercel.Webhook
+	createScript []int
+	deleteScript []int
 }
 
 func NewMockClient() *MockClient {
+	return NewMockClientWithConfig(MockConfig{})
+}
+
+func NewMockClientWithConfig(cfg MockConfig) *MockClient {
+	if cfg.Teams == nil {
+		cfg.Teams = MockTeams
+	}
+
 	return &MockClient{
-		tokens: make(map[string]any, 0),
+		cfg:          cfg,
+		tokens:       make(map[string]vercel.Token, 0),
+		bypasses:     make(map[string]map[string]vercel.ProtectionBypass),
+		edgeTokens:   make(map[string]map[string]string),
+		webhooks:     make(map[string]vercel.Webhook),
+		createScript: append([]int(nil), cfg.CreateScript...),
+		deleteScript: append([]int(nil), cfg.DeleteScript...),
 	}
 }
 
-func (m *MockClient) CreateAuthToken(_ context.Context,
-	req *CreateAuthTokenRequest) (*CreateAuthTokenResponse, error) {
+func (m *MockClient) CreateAuthToken(ctx context.Context,
+	req *vercel.CreateAuthTokenRequest) (*vercel.CreateAuthTokenResponse, error) {
 	if req.Name == "" {
 		return nil, fmt.Errorf("empty name for token")
 	}
 
-	if req.TeamID == "force-fail" {
-		return nil, fmt.Errorf("force fail")
+	if err := m.inject(ctx, &m.createScript, m.cfg.CreateErrorRate); err != nil {
+		return nil, err
 	}
 
-	r := &CreateAuthTokenResponse{
-		Token: Token{
-			ID:   fmt.Sprintf("%s-%d", req.Name, time.Now().UnixNano()),
-			Name: req.Name,
+	now := time.Now()
+	r := &vercel.CreateAuthTokenResponse{
+		Token: vercel.Token{
+			ID:        fmt.Sprintf("%s-%d", req.Name, now.UnixNano()),
+			Name:      req.Name,
+			CreatedAt: now.UnixMilli(),
+			ActiveAt:  now.UnixMilli(),
+			ExpiresAt: req.ExpiresAt,
 		},
 		BearerToken: "some-bearer-token",
 	}
 
-	m.tokens[r.Token.ID] = req.Name
+	if req.TeamID != "" {
+		r.Token.Scopes = []vercel.TokenScope{{Type: "team", TeamID: req.TeamID}}
+	}
+
+	m.mu.Lock()
+	m.tokens[r.Token.ID] = r.Token
+	m.mu.Unlock()
 
 	return r, nil
 }
 
-func (m *MockClient) DeleteAuthToken(_ context.Context,
-	req *DeleteAuthTokenRequest) (*DeleteAuthTokenResponse, error) {
+func (m *MockClient) DeleteAuthToken(ctx context.Context,
+	req *vercel.DeleteAuthTokenRequest) (*vercel.DeleteAuthTokenResponse, error) {
 	if req.ID == "" {
 		return nil, fmt.Errorf("empty id for token")
 	}
 
+	if err := m.inject(ctx, &m.deleteScript, m.cfg.DeleteErrorRate); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
 	delete(m.tokens, req.ID)
+	m.mu.Unlock()
 
-	return &DeleteAuthTokenResponse{
+	return &vercel.DeleteAuthTokenResponse{
 		ID: req.ID,
 	}, nil
 }
 
+func (m *MockClient) GetAuthToken(_ context.Context,
+	req *vercel.GetAuthTokenRequest) (*vercel.GetAuthTokenResponse, error) {
+	if req.ID == "" {
+		return nil, fmt.Errorf("empty id for token")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tokens[req.ID]
+	if !ok {
+		return nil, vercel.NewHTTPError(http.StatusNotFound,
+			[]byte(`{"error":{"code":"not_found","message":"Token not found"}}`))
+	}
+
+	return &vercel.GetAuthTokenResponse{Token: t}, nil
+}
+
+// ListAuthTokens returns all tokens in a single page, newest first.
+func (m *MockClient) ListAuthTokens(_ context.Context,
+	_ *vercel.ListAuthTokensRequest) (*vercel.ListAuthTokensResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tokens := make([]vercel.Token, 0, len(m.tokens))
+	for _, t := range m.tokens {
+		tokens = append(tokens, t)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt > tokens[j].CreatedAt
+	})
+
+	return &vercel.ListAuthTokensResponse{
+		Tokens:     tokens,
+		Pagination: vercel.Pagination{Count: len(tokens)},
+	}, nil
+}
+
+// MarkAuthTokenUsed records a use of the token at the given time, as if the
+// bearer token had been used against the Vercel API.
+func (m *MockClient) MarkAuthTokenUsed(id string, at time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.tokens[id]; ok {
+		t.ActiveAt = at.UnixMilli()
+		m.tokens[id] = t
+	}
+}
+
+// ListProjectEnv returns a fixed set of variables for any project.
+func (m *MockClient) ListProjectEnv(_ context.Context,
+	req *vercel.ListProjectEnvRequest) (*vercel.ListProjectEnvResponse, error) {
+	if req.ProjectID == "" {
+		return nil, fmt.Errorf("empty project id")
+	}
+
+	return &vercel.ListProjectEnvResponse{
+		Envs: []vercel.ProjectEnv{
+			{
+				ID:        "mock-env-1",
+				Key:       "MOCK_SHARED",
+				Value:     "shared-value",
+				Type:      "encrypted",
+				Target:    vercel.EnvTarget{"production", "preview", "development"},
+				Decrypted: true,
+			},
+			{
+				ID:        "mock-env-2",
+				Key:       "MOCK_BRANCH",
+				Value:     "preview-value",
+				Type:      "encrypted",
+				Target:    vercel.EnvTarget{"preview"},
+				Decrypted: true,
+			},
+			{
+				ID:        "mock-env-3",
+				Key:       "MOCK_BRANCH",
+				Value:     "branch-value",
+				Type:      "encrypted",
+				Target:    vercel.EnvTarget{"preview"},
+				GitBranch: "mock-branch",
+				Decrypted: true,
+			},
+			{
+				ID:     "mock-env-4",
+				Key:    "MOCK_SENSITIVE",
+				Type:   "sensitive",
+				Target: vercel.EnvTarget{"production"},
+			},
+		},
+	}, nil
+}
+
+// CreateProtectionBypass adds a bypass secret to any project. Faults are
+// injected as for token creation.
+func (m *MockClient) CreateProtectionBypass(ctx context.Context,
+	req *vercel.CreateProtectionBypassRequest) (*vercel.ProtectionBypassResponse, error) {
+	if req.ProjectID == "" {
+		return nil, fmt.Errorf("empty project id")
+	}
+
+	if err := m.inject(ctx, &m.createScript, m.cfg.CreateErrorRate); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = fmt.Sprintf("mock-bypass-%d", time.Now().UnixNano())
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.bypasses[req.ProjectID] == nil {
+		m.bypasses[req.ProjectID] = make(map[string]vercel.ProtectionBypass)
+	}
+
+	m.bypasses[req.ProjectID][secret] = vercel.ProtectionBypass{
+		CreatedAt: time.Now().UnixMilli(),
+		CreatedBy: "mock",
+		Scope:     "automation-bypass",
+		Note:      req.Note,
+	}
+
+	return &vercel.ProtectionBypassResponse{ProtectionBypass: copyBypasses(m.bypasses[req.ProjectID])}, nil
+}
+
+// RevokeProtectionBypass removes a bypass secret, failing with 404 when the
+// project has no such secret. Faults are injected as for token deletion.
+func (m *MockClient) RevokeProtectionBypass(ctx context.Context,
+	req *vercel.RevokeProtectionBypassRequest) (*vercel.ProtectionBypassResponse, error) {
+	if req.ProjectID == "" || req.Secret == "" {
+		return nil, fmt.Errorf("empty project id or secret")
+	}
+
+	if err := m.inject(ctx, &m.deleteScript, m.cfg.DeleteErrorRate); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.bypasses[req.ProjectID][req.Secret]; !ok {
+		return nil, vercel.NewHTTPError(http.StatusNotFound,
+			[]byte(`{"error":{"code":"not_found","message":"Protection bypass not found"}}`))
+	}
+
+	delete(m.bypasses[req.ProjectID], req.Secret)
+
+	return &vercel.ProtectionBypassResponse{ProtectionBypass: copyBypasses(m.bypasses[req.ProjectID])}, nil
+}
+
+// ProtectionBypassCount returns the number of bypass secrets of a project.
+func (m *MockClient) ProtectionBypassCount(projectID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.bypasses[projectID])
+}
+
+// CreateEdgeConfigToken creates a read token for any Edge Config store.
+// Faults are injected as for token creation.
+func (m *MockClient) CreateEdgeConfigToken(ctx context.Context,
+	req *vercel.CreateEdgeConfigTokenRequest) (*vercel.CreateEdgeConfigTokenResponse, error) {
+	if req.EdgeConfigID == "" {
+		return nil, fmt.Errorf("empty edge config id")
+	}
+
+	if err := m.inject(ctx, &m.createScript, m.cfg.CreateErrorRate); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixNano()
+	r := &vercel.CreateEdgeConfigTokenResponse{
+		Token: fmt.Sprintf("mock-edge-config-token-%d", now),
+		ID:    fmt.Sprintf("mock-edge-config-token-id-%d", now),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.edgeTokens[req.EdgeConfigID] == nil {
+		m.edgeTokens[req.EdgeConfigID] = make(map[string]string)
+	}
+
+	m.edgeTokens[req.EdgeConfigID][r.Token] = r.ID
+
+	return r, nil
+}
+
+// DeleteEdgeConfigTokens deletes read tokens, ignoring unknown ones.
+// Faults are injected as for token deletion.
+func (m *MockClient) DeleteEdgeConfigTokens(ctx context.Context, req *vercel.DeleteEdgeConfigTokensRequest) error {
+	if req.EdgeConfigID == "" || len(req.Tokens) == 0 {
+		return fmt.Errorf("empty edge config id or tokens")
+	}
+
+	if err := m.inject(ctx, &m.deleteScript, m.cfg.DeleteErrorRate); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range req.Tokens {
+		delete(m.edgeTokens[req.EdgeConfigID], t)
+	}
+
+	return nil
+}
+
+// EdgeConfigTokenCount returns the number of read tokens of an Edge Config store.
+func (m *MockClient) EdgeConfigTokenCount(edgeConfigID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.edgeTokens[edgeConfigID])
+}
+
+// CreateWebhook registers a webhook in memory. Faults are injected as for
+// token creation.
+func (m *MockClient) CreateWebhook(ctx context.Context,
+	req *vercel.CreateWebhookRequest) (*vercel.CreateWebhookResponse, error) {
+	if req.URL == "" || len(req.Events) == 0 {
+		return nil, fmt.Errorf("empty url or events")
+	}
+
+	if err := m.inject(ctx, &m.createScript, m.cfg.CreateErrorRate); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	w := vercel.Webhook{
+		ID:         fmt.Sprintf("mock-webhook-%d", now.UnixNano()),
+		URL:        req.URL,
+		Events:     append([]string(nil), req.Events...),
+		ProjectIDs: append([]string(nil), req.ProjectIDs...),
+		OwnerID:    req.TeamID,
+		CreatedAt:  now.UnixMilli(),
+	}
+
+	m.mu.Lock()
+	m.webhooks[w.ID] = w
+	m.mu.Unlock()
+
+	return &vercel.CreateWebhookResponse{Webhook: w, Secret: "mock-webhook-secret"}, nil
+}
+
+// DeleteWebhook removes a webhook, failing with 404 when it does not exist.
+// Faults are injected as for token deletion.
+func (m *MockClient) DeleteWebhook(ctx context.Context, req *vercel.DeleteWebhookRequest) error {
+	if req.ID == "" {
+		return fmt.Errorf("empty id for webhook")
+	}
+
+	if err := m.inject(ctx, &m.deleteScript, m.cfg.DeleteErrorRate); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.webhooks[req.ID]; !ok {
+		return vercel.NewHTTPError(http.StatusNotFound,
+			[]byte(`{"error":{"code":"not_found","message":"Webhook not found"}}`))
+	}
+
+	delete(m.webhooks, req.ID)
+
+	return nil
+}
+
+// WebhookCount returns the number of registered webhooks.
+func (m *MockClient) WebhookCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.webhooks)
+}
+
+// ListTeams returns the configured teams in a single page.
+func (m *MockClient) ListTeams(_ context.Context, _ *vercel.ListTeamsRequest) (*vercel.ListTeamsResponse, error) {
+	return &vercel.ListTeamsResponse{
+		Teams:      append([]vercel.Team(nil), m.cfg.Teams...),
+		Pagination: vercel.Pagination{Count: len(m.cfg.Teams)},
+	}, nil
+}
+
+// RateLimits returns nil, as the mock client is not rate limited.
+func (m *MockClient) RateLimits() []vercel.RateLimit {
+	return nil
+}
+
+func copyBypasses(in map[string]vercel.ProtectionBypass) map[string]vercel.ProtectionBypass {
+	out := make(map[string]vercel.ProtectionBypass, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+
+	return out
+}
+
 func (m *MockClient) GetBaseURL() string {
 	return ""
 }
+
+// TokenCount returns the number of tokens created and not yet deleted.
+func (m *MockClient) TokenCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.tokens)
+}
+
+// inject applies the configured latency and returns an injected failure, if any.
+// Scripted outcomes take precedence over the random error rate.
+func (m *MockClient) inject(ctx context.Context, script *[]int, errorRate float64) error {
+	if m.cfg.Latency > 0 {
+		t := time.NewTimer(m.cfg.Latency)
+		defer t.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(*script) > 0 {
+		code := (*script)[0]
+		*script = (*script)[1:]
+
+		if code == 0 || code/100 == 2 {
+			return nil
+		}
+
+		return vercel.NewHTTPError(code, []byte(mockFailureBody))
+	}
+
+	// #nosec G404 -- fault injection does not need a cryptographically secure source
+	if errorRate > 0 && rand.Float64() < errorRate {
+		return vercel.NewHTTPError(http.StatusServiceUnavailable, []byte(mockFailureBody))
+	}
+
+	return nil
+}
@@ -2,9 +2,14 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
 )
 
 func TestMock_New(t *testing.T) {
@@ -24,31 +29,67 @@ func TestMock_BaseURL(t *testing.T) {
 	require.Empty(t, u)
 }
 
+func TestMock_ListTeams(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	res, err := NewMockClient().ListTeams(ctx, &vercel.ListTeamsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, MockTeams, res.Teams)
+
+	teams := []vercel.Team{{ID: "team_c", Slug: "team-c", Name: "Team C"}}
+
+	res, err = NewMockClientWithConfig(MockConfig{Teams: teams}).ListTeams(ctx, &vercel.ListTeamsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, teams, res.Teams)
+}
+
 func TestMock_CreateToken(t *testing.T) {
 	t.Parallel()
 
 	cases := map[string]struct {
-		req      *CreateAuthTokenRequest
+		cfg      MockConfig
+		req      *vercel.CreateAuthTokenRequest
 		expError string
 	}{
 		"empty token": {
-			req:      &CreateAuthTokenRequest{},
+			req:      &vercel.CreateAuthTokenRequest{},
 			expError: "empty name for token",
 		},
-		"force fail": {
-			req: &CreateAuthTokenRequest{
-				Name:   "foo",
-				TeamID: "force-fail",
+		"scripted failure": {
+			cfg: MockConfig{
+				CreateScript: []int{http.StatusTooManyRequests},
+			},
+			req: &vercel.CreateAuthTokenRequest{
+				Name: "foo",
+			},
+			expError: vercel.NewHTTPError(http.StatusTooManyRequests, []byte(mockFailureBody)).Error(),
+		},
+		"scripted success": {
+			cfg: MockConfig{
+				CreateScript: []int{http.StatusOK},
+			},
+			req: &vercel.CreateAuthTokenRequest{
+				Name: "foo",
 			},
-			expError: "force fail",
+		},
+		"error rate": {
+			cfg: MockConfig{
+				CreateErrorRate: 1,
+			},
+			req: &vercel.CreateAuthTokenRequest{
+				Name: "foo",
+			},
+			expError: vercel.NewHTTPError(http.StatusServiceUnavailable, []byte(mockFailureBody)).Error(),
 		},
 		"success with just the name": {
-			req: &CreateAuthTokenRequest{
+			req: &vercel.CreateAuthTokenRequest{
 				Name: "foo",
 			},
 		},
 		"success with name + team id": {
-			req: &CreateAuthTokenRequest{
+			req: &vercel.CreateAuthTokenRequest{
 				Name:   "foo",
 				TeamID: "bar",
 			},
@@ -61,7 +102,7 @@ func TestMock_CreateToken(t *testing.T) {
 			t.Parallel()
 
 			ctx := context.Background()
-			m := NewMockClient()
+			m := NewMockClientWithConfig(tc.cfg)
 			require.NotNil(t, m)
 
 			r, err := m.CreateAuthToken(ctx, tc.req)
@@ -78,15 +119,34 @@ func TestMock_DeleteToken(t *testing.T) {
 	t.Parallel()
 
 	cases := map[string]struct {
-		req      *DeleteAuthTokenRequest
+		cfg      MockConfig
+		req      *vercel.DeleteAuthTokenRequest
 		expError string
 	}{
 		"empty id": {
-			req:      &DeleteAuthTokenRequest{},
+			req:      &vercel.DeleteAuthTokenRequest{},
 			expError: "empty id for token",
 		},
+		"scripted failure": {
+			cfg: MockConfig{
+				DeleteScript: []int{http.StatusBadGateway},
+			},
+			req: &vercel.DeleteAuthTokenRequest{
+				ID: "foo",
+			},
+			expError: vercel.NewHTTPError(http.StatusBadGateway, []byte(mockFailureBody)).Error(),
+		},
+		"error rate": {
+			cfg: MockConfig{
+				DeleteErrorRate: 1,
+			},
+			req: &vercel.DeleteAuthTokenRequest{
+				ID: "foo",
+			},
+			expError: vercel.NewHTTPError(http.StatusServiceUnavailable, []byte(mockFailureBody)).Error(),
+		},
 		"success with just the name": {
-			req: &DeleteAuthTokenRequest{
+			req: &vercel.DeleteAuthTokenRequest{
 				ID: "foo",
 			},
 		},
@@ -98,7 +158,7 @@ func TestMock_DeleteToken(t *testing.T) {
 			t.Parallel()
 
 			ctx := context.Background()
-			m := NewMockClient()
+			m := NewMockClientWithConfig(tc.cfg)
 			require.NotNil(t, m)
 
 			r, err := m.DeleteAuthToken(ctx, tc.req)
@@ -110,3 +170,95 @@ func TestMock_DeleteToken(t *testing.T) {
 		})
 	}
 }
+
+func TestMock_GetToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := NewMockClient()
+
+	_, err := m.GetAuthToken(ctx, &vercel.GetAuthTokenRequest{})
+	require.EqualError(t, err, "empty id for token")
+
+	_, err = m.GetAuthToken(ctx, &vercel.GetAuthTokenRequest{ID: "foo"})
+	require.ErrorIs(t, err, vercel.ErrNotFound)
+
+	c, err := m.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{Name: "foo"})
+	require.NoError(t, err)
+
+	r, err := m.GetAuthToken(ctx, &vercel.GetAuthTokenRequest{ID: c.Token.ID})
+	require.NoError(t, err)
+	require.Equal(t, r.Token.CreatedAt, r.Token.ActiveAt)
+
+	used := time.UnixMilli(r.Token.CreatedAt).Add(time.Minute)
+	m.MarkAuthTokenUsed(c.Token.ID, used)
+
+	r, err = m.GetAuthToken(ctx, &vercel.GetAuthTokenRequest{ID: c.Token.ID})
+	require.NoError(t, err)
+	require.Equal(t, used.UnixMilli(), r.Token.ActiveAt)
+}
+
+func TestMock_Script(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := NewMockClientWithConfig(MockConfig{
+		CreateScript: []int{http.StatusServiceUnavailable, 0},
+	})
+
+	_, err := m.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{Name: "foo"})
+	require.Error(t, err)
+
+	_, err = m.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{Name: "foo"})
+	require.NoError(t, err)
+
+	_, err = m.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{Name: "foo"})
+	require.NoError(t, err)
+	require.Equal(t, 2, m.TokenCount())
+}
+
+func TestMock_Latency(t *testing.T) {
+	t.Parallel()
+
+	m := NewMockClientWithConfig(MockConfig{
+		Latency: time.Minute,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := m.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{Name: "foo"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Zero(t, m.TokenCount())
+}
+
+func TestMock_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := NewMockClient()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			r, err := m.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{Name: fmt.Sprintf("foo-%d", i)})
+			if err != nil {
+				t.Error(err)
+
+				return
+			}
+
+			if _, err = m.DeleteAuthToken(ctx, &vercel.DeleteAuthTokenRequest{ID: r.Token.ID}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	require.Zero(t, m.TokenCount())
+}
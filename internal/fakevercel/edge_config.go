@@ -0,0 +1,110 @@
+package fakevercel
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	endpointCreateEdgeConfigToken  = "POST /edge-config/:id/token"
+	endpointDeleteEdgeConfigTokens = "DELETE /edge-config/:id/tokens"
+	edgeConfigTokenBytes           = 18
+)
+
+type edgeConfigToken struct {
+	id        string
+	label     string
+	createdAt int64
+}
+
+// EdgeConfigToken is an Edge Config read token known to the server.
+type EdgeConfigToken struct {
+	ID        string
+	Token     string
+	Label     string
+	CreatedAt int64
+}
+
+type createEdgeConfigTokenRequest struct {
+	Label string `json:"label"`
+}
+
+type createEdgeConfigTokenResponse struct {
+	Token string `json:"token"`
+	ID    string `json:"id"`
+}
+
+type deleteEdgeConfigTokensRequest struct {
+	Tokens []string `json:"tokens"`
+}
+
+// EdgeConfigTokens returns the read tokens of an Edge Config store.
+func (s *Server) EdgeConfigTokens(edgeConfigID string) []EdgeConfigToken {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]EdgeConfigToken, 0, len(s.edgeTokens[edgeConfigID]))
+	for value, t := range s.edgeTokens[edgeConfigID] {
+		out = append(out, EdgeConfigToken{
+			ID:        t.id,
+			Token:     value,
+			Label:     t.label,
+			CreatedAt: t.createdAt,
+		})
+	}
+
+	return out
+}
+
+func (s *Server) createEdgeConfigToken(w http.ResponseWriter, r *http.Request, edgeConfigID string) {
+	var req createEdgeConfigTokenRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Label == "" {
+		writeError(w, http.StatusBadRequest, apiError{
+			Code:    "bad_request",
+			Message: "Invalid request: missing required property `label`",
+		})
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.edgeTokens[edgeConfigID] == nil {
+		s.edgeTokens[edgeConfigID] = make(map[string]*edgeConfigToken)
+	}
+
+	value := randomHex(edgeConfigTokenBytes)
+	t := &edgeConfigToken{
+		id:        randomHex(tokenIDBytes),
+		label:     req.Label,
+		createdAt: s.cfg.Now().UnixMilli(),
+	}
+	s.edgeTokens[edgeConfigID][value] = t
+
+	writeJSON(w, http.StatusCreated, createEdgeConfigTokenResponse{Token: value, ID: t.id})
+}
+
+// deleteEdgeConfigTokens deletes the given tokens. Unknown tokens are ignored.
+func (s *Server) deleteEdgeConfigTokens(w http.ResponseWriter, r *http.Request, edgeConfigID string) {
+	var req deleteEdgeConfigTokensRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Tokens) == 0 {
+		writeError(w, http.StatusBadRequest, apiError{
+			Code:    "bad_request",
+			Message: "Invalid request: missing required property `tokens`",
+		})
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range req.Tokens {
+		delete(s.edgeTokens[edgeConfigID], t)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
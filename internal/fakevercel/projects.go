@@ -0,0 +1,124 @@
+package fakevercel
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+)
+
+const (
+	endpointProtectionBypass = "PATCH /projects/:id/protection-bypass"
+	bypassScopeAutomation    = "automation-bypass"
+	bypassSecretBytes        = 16
+)
+
+var bypassSecretPattern = regexp.MustCompile(`^[A-Za-z0-9]{32}$`)
+
+type bypass struct {
+	createdAt int64
+	note      string
+}
+
+// ProtectionBypass is the JSON representation of a protection bypass secret.
+type ProtectionBypass struct {
+	CreatedAt int64  `json:"createdAt"`
+	CreatedBy string `json:"createdBy"`
+	Scope     string `json:"scope"`
+	Note      string `json:"note,omitempty"`
+}
+
+type protectionBypassRequest struct {
+	Generate *struct {
+		Secret string `json:"secret"`
+		Note   string `json:"note"`
+	} `json:"generate"`
+	Revoke *struct {
+		Secret     string `json:"secret"`
+		Regenerate bool   `json:"regenerate"`
+	} `json:"revoke"`
+}
+
+type protectionBypassResponse struct {
+	ProtectionBypass map[string]ProtectionBypass `json:"protectionBypass"`
+}
+
+// ProtectionBypasses returns the bypass secrets of a project, keyed by secret.
+func (s *Server) ProtectionBypasses(projectID string) map[string]ProtectionBypass {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.bypassView(projectID)
+}
+
+// bypassView renders the bypass secrets of a project. Callers must hold s.mu.
+func (s *Server) bypassView(projectID string) map[string]ProtectionBypass {
+	out := make(map[string]ProtectionBypass, len(s.bypasses[projectID]))
+	for secret, b := range s.bypasses[projectID] {
+		out[secret] = ProtectionBypass{
+			CreatedAt: b.createdAt,
+			CreatedBy: "fake",
+			Scope:     bypassScopeAutomation,
+			Note:      b.note,
+		}
+	}
+
+	return out
+}
+
+func (s *Server) updateProtectionBypass(w http.ResponseWriter, r *http.Request, projectID string) {
+	var req protectionBypassRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || (req.Generate == nil) == (req.Revoke == nil) {
+		writeError(w, http.StatusBadRequest, apiError{
+			Code:    "bad_request",
+			Message: "Invalid request: exactly one of `generate` or `revoke` is required",
+		})
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Generate != nil {
+		secret := req.Generate.Secret
+		if secret == "" {
+			secret = randomHex(bypassSecretBytes)
+		}
+
+		if !bypassSecretPattern.MatchString(secret) {
+			writeError(w, http.StatusBadRequest, apiError{
+				Code:    "bad_request",
+				Message: "Invalid request: `secret` must be 32 alphanumeric characters",
+			})
+
+			return
+		}
+
+		if s.bypasses[projectID] == nil {
+			s.bypasses[projectID] = make(map[string]*bypass)
+		}
+
+		s.bypasses[projectID][secret] = &bypass{
+			createdAt: s.cfg.Now().UnixMilli(),
+			note:      req.Generate.Note,
+		}
+
+		writeJSON(w, http.StatusOK, protectionBypassResponse{ProtectionBypass: s.bypassView(projectID)})
+
+		return
+	}
+
+	if _, ok := s.bypasses[projectID][req.Revoke.Secret]; !ok {
+		writeError(w, http.StatusNotFound, apiError{
+			Code:    "not_found",
+			Message: "Protection bypass not found",
+		})
+
+		return
+	}
+
+	delete(s.bypasses[projectID], req.Revoke.Secret)
+
+	writeJSON(w, http.StatusOK, protectionBypassResponse{ProtectionBypass: s.bypassView(projectID)})
+}
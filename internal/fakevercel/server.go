@@ -0,0 +1,364 @@
+// Package fakevercel implements an in-process fake of the Vercel API token,
+// project protection bypass, Edge Config token, webhook and team endpoints.
+// It is meant for offline testing of the plugin and of Vault setups pointing base_url at it.
+package fakevercel
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultRateLimit       = 100
+	DefaultRateLimitWindow = time.Minute
+	defaultPageSize        = 20
+	maxPageSize            = 100
+	tokenIDBytes           = 32
+	bearerTokenBytes       = 12
+
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+	headerRetryAfter         = "Retry-After"
+)
+
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+`)
+
+// Config controls the behaviour of the fake server.
+// Zero values fall back to sensible defaults.
+type Config struct {
+	// APIKey is the root key accepted by the server. Tokens issued through
+	// the server are accepted as well until they expire or are deleted.
+	APIKey string
+	// Teams lists the team IDs the API key is a member of.
+	Teams []string
+	// TeamSlugs maps team IDs in Teams to their slugs. Teams without an
+	// entry use their ID as slug.
+	TeamSlugs map[string]string
+	// RateLimit is the number of requests allowed per endpoint within RateLimitWindow.
+	RateLimit int
+	// RateLimitWindow is the length of a single rate limit window.
+	RateLimitWindow time.Duration
+	// Now overrides the clock used for expiry and rate limiting.
+	Now func() time.Time
+}
+
+// Server is an http.Handler serving a subset of the Vercel API.
+type Server struct {
+	cfg Config
+
+	mu          sync.Mutex
+	tokens      map[string]*token
+	bypasses    map[string]map[string]*bypass
+	edgeTokens  map[string]map[string]*edgeConfigToken
+	webhooks    map[string]*webhook
+	buckets     map[string]*bucket
+	lastCreated int64
+}
+
+type bucket struct {
+	reset     time.Time
+	remaining int
+}
+
+type principal struct {
+	teamID string
+}
+
+type errorBody struct {
+	Error apiError `json:"error"`
+}
+
+type apiError struct {
+	Code         string     `json:"code"`
+	Message      string     `json:"message"`
+	InvalidToken bool       `json:"invalidToken,omitempty"`
+	Limit        *rateLimit `json:"limit,omitempty"`
+}
+
+type rateLimit struct {
+	Remaining int   `json:"remaining"`
+	Reset     int64 `json:"reset"`
+	ResetMs   int64 `json:"resetMs"`
+	Total     int   `json:"total"`
+}
+
+// New returns a fake server using the given configuration.
+func New(cfg Config) *Server {
+	if cfg.RateLimit <= 0 {
+		cfg.RateLimit = DefaultRateLimit
+	}
+
+	if cfg.RateLimitWindow <= 0 {
+		cfg.RateLimitWindow = DefaultRateLimitWindow
+	}
+
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	return &Server{
+		cfg:        cfg,
+		tokens:     make(map[string]*token),
+		bypasses:   make(map[string]map[string]*bypass),
+		edgeTokens: make(map[string]map[string]*edgeConfigToken),
+		webhooks:   make(map[string]*webhook),
+		buckets:    make(map[string]*bucket),
+	}
+}
+
+// NewHTTPTestServer starts a fake server on a local loopback address.
+// The caller is responsible for closing the returned httptest.Server.
+func NewHTTPTestServer(cfg Config) (*Server, *httptest.Server) {
+	s := New(cfg)
+
+	return s, httptest.NewServer(s)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimRight(versionPrefix.ReplaceAllString(r.URL.Path, ""), "/")
+	endpoint, id := route(r.Method, path)
+
+	if endpoint == "" {
+		writeError(w, http.StatusNotFound, apiError{
+			Code:    "not_found",
+			Message: "The requested path was not found",
+		})
+
+		return
+	}
+
+	if !s.allow(w, endpoint) {
+		return
+	}
+
+	p, ok := s.authenticate(r)
+	if !ok {
+		writeError(w, http.StatusForbidden, apiError{
+			Code:         "forbidden",
+			Message:      "Not authorized",
+			InvalidToken: true,
+		})
+
+		return
+	}
+
+	teamID := r.URL.Query().Get("teamId")
+	if !s.teamAllowed(p, teamID) {
+		writeError(w, http.StatusForbidden, apiError{
+			Code:    "forbidden",
+			Message: "Not authorized",
+		})
+
+		return
+	}
+
+	switch endpoint {
+	case endpointCreateToken:
+		s.createToken(w, r, teamID)
+	case endpointListTokens:
+		s.listTokens(w, r, teamID)
+	case endpointGetToken:
+		s.getToken(w, id)
+	case endpointDeleteToken:
+		s.deleteToken(w, id)
+	case endpointProtectionBypass:
+		s.updateProtectionBypass(w, r, id)
+	case endpointCreateEdgeConfigToken:
+		s.createEdgeConfigToken(w, r, id)
+	case endpointDeleteEdgeConfigTokens:
+		s.deleteEdgeConfigTokens(w, r, id)
+	case endpointCreateWebhook:
+		s.createWebhook(w, r, teamID)
+	case endpointDeleteWebhook:
+		s.deleteWebhook(w, id, teamID)
+	case endpointListTeams:
+		s.listTeams(w, p)
+	}
+}
+
+// route returns the endpoint serving the request and the token, project,
+// Edge Config or webhook ID taken from the path, if any.
+func route(method, path string) (string, string) {
+	const (
+		tokensPath   = "/user/tokens"
+		projectsPath = "/projects/"
+		bypassSuffix = "/protection-bypass"
+		edgePath     = "/edge-config/"
+		webhooksPath = "/webhooks"
+		teamsPath    = "/teams"
+	)
+
+	switch {
+	case path == tokensPath && method == http.MethodPost:
+		return endpointCreateToken, ""
+	case path == tokensPath && method == http.MethodGet:
+		return endpointListTokens, ""
+	case strings.HasPrefix(path, tokensPath+"/"):
+		id := strings.TrimPrefix(path, tokensPath+"/")
+		if id == "" || strings.Contains(id, "/") {
+			return "", ""
+		}
+
+		switch method {
+		case http.MethodGet:
+			return endpointGetToken, id
+		case http.MethodDelete:
+			return endpointDeleteToken, id
+		}
+	case strings.HasPrefix(path, projectsPath) && strings.HasSuffix(path, bypassSuffix) &&
+		method == http.MethodPatch:
+		id := strings.TrimSuffix(strings.TrimPrefix(path, projectsPath), bypassSuffix)
+		if id == "" || strings.Contains(id, "/") {
+			return "", ""
+		}
+
+		return endpointProtectionBypass, id
+	case strings.HasPrefix(path, edgePath):
+		id, rest, _ := strings.Cut(strings.TrimPrefix(path, edgePath), "/")
+		if id == "" {
+			return "", ""
+		}
+
+		switch {
+		case rest == "token" && method == http.MethodPost:
+			return endpointCreateEdgeConfigToken, id
+		case rest == "tokens" && method == http.MethodDelete:
+			return endpointDeleteEdgeConfigTokens, id
+		}
+	case path == webhooksPath && method == http.MethodPost:
+		return endpointCreateWebhook, ""
+	case strings.HasPrefix(path, webhooksPath+"/") && method == http.MethodDelete:
+		id := strings.TrimPrefix(path, webhooksPath+"/")
+		if id == "" || strings.Contains(id, "/") {
+			return "", ""
+		}
+
+		return endpointDeleteWebhook, id
+	case path == teamsPath && method == http.MethodGet:
+		return endpointListTeams, ""
+	}
+
+	return "", ""
+}
+
+func (s *Server) allow(w http.ResponseWriter, endpoint string) bool {
+	s.mu.Lock()
+	now := s.cfg.Now()
+
+	b, ok := s.buckets[endpoint]
+	if !ok || !now.Before(b.reset) {
+		b = &bucket{
+			reset:     now.Add(s.cfg.RateLimitWindow),
+			remaining: s.cfg.RateLimit,
+		}
+		s.buckets[endpoint] = b
+	}
+
+	limited := b.remaining <= 0
+	if !limited {
+		b.remaining--
+	}
+
+	remaining, reset := b.remaining, b.reset
+	s.mu.Unlock()
+
+	w.Header().Set(headerRateLimitLimit, strconv.Itoa(s.cfg.RateLimit))
+	w.Header().Set(headerRateLimitRemaining, strconv.Itoa(remaining))
+	w.Header().Set(headerRateLimitReset, strconv.FormatInt(reset.Unix(), 10))
+
+	if !limited {
+		return true
+	}
+
+	retryAfter := int64(reset.Sub(now).Round(time.Second) / time.Second)
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	w.Header().Set(headerRetryAfter, strconv.FormatInt(retryAfter, 10))
+	writeError(w, http.StatusTooManyRequests, apiError{
+		Code:    "rate_limited",
+		Message: "Rate limit exceeded",
+		Limit: &rateLimit{
+			Remaining: 0,
+			Reset:     reset.Unix(),
+			ResetMs:   reset.UnixMilli(),
+			Total:     s.cfg.RateLimit,
+		},
+	})
+
+	return false
+}
+
+func (s *Server) authenticate(r *http.Request) (principal, bool) {
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || bearer == "" {
+		return principal{}, false
+	}
+
+	if s.cfg.APIKey != "" && bearer == s.cfg.APIKey {
+		return principal{}, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.cfg.Now()
+
+	for _, t := range s.tokens {
+		if t.bearerToken == bearer && !t.expired(now) {
+			t.activeAt = now.UnixMilli()
+
+			return principal{teamID: t.teamID}, true
+		}
+	}
+
+	return principal{}, false
+}
+
+func (s *Server) teamAllowed(p principal, teamID string) bool {
+	if p.teamID != "" {
+		return teamID == "" || teamID == p.teamID
+	}
+
+	if teamID == "" {
+		return true
+	}
+
+	for _, t := range s.cfg.Teams {
+		if t == teamID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, e apiError) {
+	writeJSON(w, status, errorBody{Error: e})
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+
+	return hex.EncodeToString(b)
+}
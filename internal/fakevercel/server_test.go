@@ -0,0 +1,469 @@
+package fakevercel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+const testAPIKey = "root-key"
+
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func doRequest(t *testing.T, method, url, apiKey string, body any) (*http.Response, map[string]any) {
+	t.Helper()
+
+	var b []byte
+
+	if body != nil {
+		var err error
+
+		b, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), method, url, bytes.NewReader(b))
+	require.NoError(t, err)
+
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer res.Body.Close()
+
+	var out map[string]any
+
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+
+	return res, out
+}
+
+func TestServer_CreateGetDelete(t *testing.T) {
+	t.Parallel()
+
+	s, ts := NewHTTPTestServer(Config{APIKey: testAPIKey})
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	c := vercel.NewClient(testAPIKey, vercel.WithBaseURL(ts.URL))
+
+	res, err := c.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{
+		Name:      "foo",
+		ExpiresAt: time.Now().Add(time.Hour).UnixMilli(),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.BearerToken)
+	require.Len(t, s.Tokens(), 1)
+
+	r, body := doRequest(t, http.MethodGet, ts.URL+"/v5/user/tokens/"+res.Token.ID, testAPIKey, nil)
+	require.Equal(t, http.StatusOK, r.StatusCode)
+	require.Equal(t, "foo", body["token"].(map[string]any)["name"])
+
+	d, err := c.DeleteAuthToken(ctx, &vercel.DeleteAuthTokenRequest{ID: res.Token.ID})
+	require.NoError(t, err)
+	require.Equal(t, res.Token.ID, d.ID)
+	require.Empty(t, s.Tokens())
+
+	_, err = c.DeleteAuthToken(ctx, &vercel.DeleteAuthTokenRequest{ID: res.Token.ID})
+
+	var httpErr *vercel.HTTPError
+
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+}
+
+func TestServer_ProtectionBypass(t *testing.T) {
+	t.Parallel()
+
+	s, ts := NewHTTPTestServer(Config{APIKey: testAPIKey, Teams: []string{"team_a"}})
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	c := vercel.NewClient(testAPIKey, vercel.WithBaseURL(ts.URL))
+	secret := "abcdefghijklmnopqrstuvwxyz012345"
+
+	res, err := c.CreateProtectionBypass(ctx, &vercel.CreateProtectionBypassRequest{
+		ProjectID: "prj_a",
+		TeamID:    "team_a",
+		Secret:    secret,
+		Note:      "e2e",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "e2e", res.ProtectionBypass[secret].Note)
+	require.Contains(t, s.ProtectionBypasses("prj_a"), secret)
+
+	_, err = c.CreateProtectionBypass(ctx, &vercel.CreateProtectionBypassRequest{ProjectID: "prj_a"})
+	require.NoError(t, err)
+	require.Len(t, s.ProtectionBypasses("prj_a"), 2)
+
+	_, err = c.CreateProtectionBypass(ctx, &vercel.CreateProtectionBypassRequest{ProjectID: "prj_a", Secret: "short"})
+	require.ErrorIs(t, err, vercel.ErrBadRequest)
+
+	res, err = c.RevokeProtectionBypass(ctx, &vercel.RevokeProtectionBypassRequest{ProjectID: "prj_a", Secret: secret})
+	require.NoError(t, err)
+	require.NotContains(t, res.ProtectionBypass, secret)
+	require.Len(t, s.ProtectionBypasses("prj_a"), 1)
+
+	_, err = c.RevokeProtectionBypass(ctx, &vercel.RevokeProtectionBypassRequest{ProjectID: "prj_a", Secret: secret})
+	require.ErrorIs(t, err, vercel.ErrNotFound)
+}
+
+func TestServer_EdgeConfigTokens(t *testing.T) {
+	t.Parallel()
+
+	s, ts := NewHTTPTestServer(Config{APIKey: testAPIKey})
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	c := vercel.NewClient(testAPIKey, vercel.WithBaseURL(ts.URL))
+
+	res, err := c.CreateEdgeConfigToken(ctx, &vercel.CreateEdgeConfigTokenRequest{
+		EdgeConfigID: "ecfg_a",
+		Label:        "foo",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []EdgeConfigToken{{
+		ID:        res.ID,
+		Token:     res.Token,
+		Label:     "foo",
+		CreatedAt: s.EdgeConfigTokens("ecfg_a")[0].CreatedAt,
+	}}, s.EdgeConfigTokens("ecfg_a"))
+
+	_, err = c.CreateEdgeConfigToken(ctx, &vercel.CreateEdgeConfigTokenRequest{EdgeConfigID: "ecfg_a"})
+	require.ErrorIs(t, err, vercel.ErrBadRequest)
+
+	req := &vercel.DeleteEdgeConfigTokensRequest{EdgeConfigID: "ecfg_a", Tokens: []string{res.Token}}
+	require.NoError(t, c.DeleteEdgeConfigTokens(ctx, req))
+	require.Empty(t, s.EdgeConfigTokens("ecfg_a"))
+	require.NoError(t, c.DeleteEdgeConfigTokens(ctx, req))
+}
+
+func TestServer_Webhooks(t *testing.T) {
+	t.Parallel()
+
+	s, ts := NewHTTPTestServer(Config{APIKey: testAPIKey, Teams: []string{"team_a"}})
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	c := vercel.NewClient(testAPIKey, vercel.WithBaseURL(ts.URL))
+
+	res, err := c.CreateWebhook(ctx, &vercel.CreateWebhookRequest{
+		TeamID:     "team_a",
+		URL:        "https://example.com/hook",
+		Events:     []string{"deployment.created"},
+		ProjectIDs: []string{"prj_a"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.Secret)
+	require.Equal(t, []Webhook{{
+		ID:         res.ID,
+		URL:        "https://example.com/hook",
+		Events:     []string{"deployment.created"},
+		ProjectIDs: []string{"prj_a"},
+		OwnerID:    "team_a",
+		CreatedAt:  res.CreatedAt,
+	}}, s.Webhooks())
+
+	_, err = c.CreateWebhook(ctx, &vercel.CreateWebhookRequest{URL: "http://example.com", Events: []string{"a"}})
+	require.ErrorIs(t, err, vercel.ErrBadRequest)
+
+	// Webhooks are owned by the team they were created for.
+	require.ErrorIs(t, c.DeleteWebhook(ctx, &vercel.DeleteWebhookRequest{ID: res.ID}), vercel.ErrNotFound)
+
+	require.NoError(t, c.DeleteWebhook(ctx, &vercel.DeleteWebhookRequest{ID: res.ID, TeamID: "team_a"}))
+	require.Empty(t, s.Webhooks())
+}
+
+func TestServer_Teams(t *testing.T) {
+	t.Parallel()
+
+	_, ts := NewHTTPTestServer(Config{
+		APIKey:    testAPIKey,
+		Teams:     []string{"team_a", "team_b"},
+		TeamSlugs: map[string]string{"team_a": "acme"},
+	})
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	c := vercel.NewClient(testAPIKey, vercel.WithBaseURL(ts.URL))
+
+	res, err := c.ListTeams(ctx, &vercel.ListTeamsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []vercel.Team{
+		{ID: "team_a", Slug: "acme", Name: "acme"},
+		{ID: "team_b", Slug: "team_b", Name: "team_b"},
+	}, res.Teams)
+	require.Nil(t, res.Pagination.Next)
+
+	// Tokens scoped to a team only see that team.
+	issued, err := c.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{Name: "scoped", TeamID: "team_b"})
+	require.NoError(t, err)
+
+	res, err = vercel.NewClient(issued.BearerToken, vercel.WithBaseURL(ts.URL)).
+		ListTeams(ctx, &vercel.ListTeamsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []vercel.Team{{ID: "team_b", Slug: "team_b", Name: "team_b"}}, res.Teams)
+}
+
+func TestServer_Auth(t *testing.T) {
+	t.Parallel()
+
+	_, ts := NewHTTPTestServer(Config{APIKey: testAPIKey, Teams: []string{"team_a", "team_b"}})
+	t.Cleanup(ts.Close)
+
+	cases := map[string]struct {
+		apiKey    string
+		teamID    string
+		expStatus int
+	}{
+		"missing key": {
+			expStatus: http.StatusForbidden,
+		},
+		"invalid key": {
+			apiKey:    "bogus",
+			expStatus: http.StatusForbidden,
+		},
+		"user scope": {
+			apiKey:    testAPIKey,
+			expStatus: http.StatusOK,
+		},
+		"member team": {
+			apiKey:    testAPIKey,
+			teamID:    "team_a",
+			expStatus: http.StatusOK,
+		},
+		"non-member team": {
+			apiKey:    testAPIKey,
+			teamID:    "team_c",
+			expStatus: http.StatusForbidden,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			u := ts.URL + "/v3/user/tokens"
+			if tc.teamID != "" {
+				u += "?teamId=" + tc.teamID
+			}
+
+			r, body := doRequest(t, http.MethodPost, u, tc.apiKey, map[string]any{"name": "foo"})
+			require.Equal(t, tc.expStatus, r.StatusCode)
+
+			if tc.expStatus != http.StatusOK {
+				require.Equal(t, "forbidden", body["error"].(map[string]any)["code"])
+
+				return
+			}
+
+			scope := body["token"].(map[string]any)["scopes"].([]any)[0].(map[string]any)
+			if tc.teamID != "" {
+				require.Equal(t, "team", scope["type"])
+				require.Equal(t, tc.teamID, scope["teamId"])
+			} else {
+				require.Equal(t, "user", scope["type"])
+			}
+		})
+	}
+}
+
+func TestServer_IssuedTokenScope(t *testing.T) {
+	t.Parallel()
+
+	_, ts := NewHTTPTestServer(Config{APIKey: testAPIKey, Teams: []string{"team_a", "team_b"}})
+	t.Cleanup(ts.Close)
+
+	r, body := doRequest(t, http.MethodPost, ts.URL+"/v3/user/tokens?teamId=team_a", testAPIKey,
+		map[string]any{"name": "foo"})
+	require.Equal(t, http.StatusOK, r.StatusCode)
+
+	bearer, _ := body["bearerToken"].(string)
+
+	r, _ = doRequest(t, http.MethodGet, ts.URL+"/v5/user/tokens?teamId=team_a", bearer, nil)
+	require.Equal(t, http.StatusOK, r.StatusCode)
+
+	r, _ = doRequest(t, http.MethodGet, ts.URL+"/v5/user/tokens?teamId=team_b", bearer, nil)
+	require.Equal(t, http.StatusForbidden, r.StatusCode)
+}
+
+func TestServer_Expiry(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	s, ts := NewHTTPTestServer(Config{APIKey: testAPIKey, Now: clock.Now})
+
+	t.Cleanup(ts.Close)
+
+	r, _ := doRequest(t, http.MethodPost, ts.URL+"/v3/user/tokens", testAPIKey, map[string]any{
+		"name":      "foo",
+		"expiresAt": clock.Now().Add(-time.Second).UnixMilli(),
+	})
+	require.Equal(t, http.StatusBadRequest, r.StatusCode)
+
+	r, body := doRequest(t, http.MethodPost, ts.URL+"/v3/user/tokens", testAPIKey, map[string]any{
+		"name":      "foo",
+		"expiresAt": clock.Now().Add(time.Minute).UnixMilli(),
+	})
+	require.Equal(t, http.StatusOK, r.StatusCode)
+	require.Len(t, s.Tokens(), 1)
+
+	id, _ := body["token"].(map[string]any)["id"].(string)
+	bearer, _ := body["bearerToken"].(string)
+
+	clock.Advance(time.Minute)
+
+	require.Empty(t, s.Tokens())
+
+	r, _ = doRequest(t, http.MethodGet, ts.URL+"/v5/user/tokens/"+id, testAPIKey, nil)
+	require.Equal(t, http.StatusNotFound, r.StatusCode)
+
+	r, _ = doRequest(t, http.MethodGet, ts.URL+"/v5/user/tokens", bearer, nil)
+	require.Equal(t, http.StatusForbidden, r.StatusCode)
+}
+
+func TestServer_ActiveAt(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	_, ts := NewHTTPTestServer(Config{APIKey: testAPIKey, Now: clock.Now})
+
+	t.Cleanup(ts.Close)
+
+	r, body := doRequest(t, http.MethodPost, ts.URL+"/v3/user/tokens", testAPIKey, map[string]any{"name": "foo"})
+	require.Equal(t, http.StatusOK, r.StatusCode)
+
+	id, _ := body["token"].(map[string]any)["id"].(string)
+	bearer, _ := body["bearerToken"].(string)
+	created := body["token"].(map[string]any)["createdAt"]
+
+	_, body = doRequest(t, http.MethodGet, ts.URL+"/v5/user/tokens/"+id, testAPIKey, nil)
+	require.Equal(t, created, body["token"].(map[string]any)["activeAt"])
+
+	clock.Advance(time.Minute)
+
+	r, _ = doRequest(t, http.MethodGet, ts.URL+"/v5/user/tokens", bearer, nil)
+	require.Equal(t, http.StatusOK, r.StatusCode)
+
+	_, body = doRequest(t, http.MethodGet, ts.URL+"/v5/user/tokens/"+id, testAPIKey, nil)
+	require.InDelta(t, float64(clock.Now().UnixMilli()), body["token"].(map[string]any)["activeAt"], 0)
+}
+
+func TestServer_Pagination(t *testing.T) {
+	t.Parallel()
+
+	_, ts := NewHTTPTestServer(Config{APIKey: testAPIKey})
+	t.Cleanup(ts.Close)
+
+	total := 5
+	for i := 0; i < total; i++ {
+		r, _ := doRequest(t, http.MethodPost, ts.URL+"/v3/user/tokens", testAPIKey,
+			map[string]any{"name": fmt.Sprintf("token-%d", i)})
+		require.Equal(t, http.StatusOK, r.StatusCode)
+	}
+
+	seen := make([]string, 0, total)
+	u := ts.URL + "/v5/user/tokens?limit=2"
+
+	for {
+		r, body := doRequest(t, http.MethodGet, u, testAPIKey, nil)
+		require.Equal(t, http.StatusOK, r.StatusCode)
+
+		for _, tok := range body["tokens"].([]any) {
+			seen = append(seen, tok.(map[string]any)["name"].(string))
+		}
+
+		next, ok := body["pagination"].(map[string]any)["next"].(float64)
+		if !ok {
+			break
+		}
+
+		u = ts.URL + "/v5/user/tokens?limit=2&until=" + strconv.FormatInt(int64(next), 10)
+	}
+
+	require.Equal(t, []string{"token-4", "token-3", "token-2", "token-1", "token-0"}, seen)
+
+	r, _ := doRequest(t, http.MethodGet, ts.URL+"/v5/user/tokens?limit=0", testAPIKey, nil)
+	require.Equal(t, http.StatusBadRequest, r.StatusCode)
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	_, ts := NewHTTPTestServer(Config{
+		APIKey:          testAPIKey,
+		RateLimit:       2,
+		RateLimitWindow: time.Minute,
+		Now:             clock.Now,
+	})
+
+	t.Cleanup(ts.Close)
+
+	reset := strconv.FormatInt(clock.Now().Add(time.Minute).Unix(), 10)
+
+	for i := 1; i >= 0; i-- {
+		r, _ := doRequest(t, http.MethodGet, ts.URL+"/v5/user/tokens", testAPIKey, nil)
+		require.Equal(t, http.StatusOK, r.StatusCode)
+		require.Equal(t, "2", r.Header.Get("X-RateLimit-Limit"))
+		require.Equal(t, strconv.Itoa(i), r.Header.Get("X-RateLimit-Remaining"))
+		require.Equal(t, reset, r.Header.Get("X-RateLimit-Reset"))
+	}
+
+	r, body := doRequest(t, http.MethodGet, ts.URL+"/v5/user/tokens", testAPIKey, nil)
+	require.Equal(t, http.StatusTooManyRequests, r.StatusCode)
+	require.Equal(t, "0", r.Header.Get("X-RateLimit-Remaining"))
+	require.Equal(t, "60", r.Header.Get("Retry-After"))
+	require.Equal(t, "rate_limited", body["error"].(map[string]any)["code"])
+
+	// Limits are tracked per endpoint.
+	r, _ = doRequest(t, http.MethodPost, ts.URL+"/v3/user/tokens", testAPIKey, map[string]any{"name": "foo"})
+	require.Equal(t, http.StatusOK, r.StatusCode)
+
+	clock.Advance(time.Minute)
+
+	r, _ = doRequest(t, http.MethodGet, ts.URL+"/v5/user/tokens", testAPIKey, nil)
+	require.Equal(t, http.StatusOK, r.StatusCode)
+	require.Equal(t, "1", r.Header.Get("X-RateLimit-Remaining"))
+}
+
+func TestServer_UnknownPath(t *testing.T) {
+	t.Parallel()
+
+	_, ts := NewHTTPTestServer(Config{APIKey: testAPIKey})
+	t.Cleanup(ts.Close)
+
+	r, body := doRequest(t, http.MethodGet, ts.URL+"/v9/projects", testAPIKey, nil)
+	require.Equal(t, http.StatusNotFound, r.StatusCode)
+	require.Equal(t, "not_found", body["error"].(map[string]any)["code"])
+}
@@ -0,0 +1,49 @@
+package fakevercel
+
+import (
+	"net/http"
+)
+
+const endpointListTeams = "GET /teams"
+
+// Team is the JSON representation of a team returned by the fake server.
+type Team struct {
+	ID   string `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+type listTeamsResponse struct {
+	Teams      []Team     `json:"teams"`
+	Pagination pagination `json:"pagination"`
+}
+
+// teamView returns the team with the given ID. Teams without a configured
+// slug use their ID as slug and name.
+func (s *Server) teamView(id string) Team {
+	slug := s.cfg.TeamSlugs[id]
+	if slug == "" {
+		slug = id
+	}
+
+	return Team{ID: id, Slug: slug, Name: slug}
+}
+
+// listTeams returns all teams the caller is a member of in a single page.
+// Tokens scoped to a team only see that team.
+func (s *Server) listTeams(w http.ResponseWriter, p principal) {
+	teams := make([]Team, 0, len(s.cfg.Teams))
+
+	for _, id := range s.cfg.Teams {
+		if p.teamID != "" && p.teamID != id {
+			continue
+		}
+
+		teams = append(teams, s.teamView(id))
+	}
+
+	writeJSON(w, http.StatusOK, listTeamsResponse{
+		Teams:      teams,
+		Pagination: pagination{Count: len(teams)},
+	})
+}
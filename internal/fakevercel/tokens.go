@@ -0,0 +1,327 @@
+package fakevercel
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	endpointCreateToken = "POST /user/tokens"
+	endpointListTokens  = "GET /user/tokens"
+	endpointGetToken    = "GET /user/tokens/:id"
+	endpointDeleteToken = "DELETE /user/tokens/:id"
+)
+
+type token struct {
+	id          string
+	name        string
+	bearerToken string
+	teamID      string
+	createdAt   int64
+	activeAt    int64
+	expiresAt   int64
+}
+
+// Token is the JSON representation of a token returned by the fake server.
+type Token struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Origin    string  `json:"origin"`
+	Scopes    []Scope `json:"scopes"`
+	ExpiresAt int64   `json:"expiresAt,omitempty"`
+	ActiveAt  int64   `json:"activeAt"`
+	CreatedAt int64   `json:"createdAt"`
+}
+
+// Scope is the JSON representation of a token scope.
+type Scope struct {
+	Type      string `json:"type"`
+	Origin    string `json:"origin"`
+	TeamID    string `json:"teamId,omitempty"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+type createTokenRequest struct {
+	Name      string `json:"name"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
+
+type createTokenResponse struct {
+	Token       Token  `json:"token"`
+	BearerToken string `json:"bearerToken"`
+}
+
+type getTokenResponse struct {
+	Token Token `json:"token"`
+}
+
+type listTokensResponse struct {
+	Tokens     []Token    `json:"tokens"`
+	Pagination pagination `json:"pagination"`
+}
+
+type pagination struct {
+	Count int    `json:"count"`
+	Next  *int64 `json:"next"`
+	Prev  *int64 `json:"prev"`
+}
+
+type deleteTokenResponse struct {
+	TokenID string `json:"tokenId"`
+}
+
+func (t *token) expired(now time.Time) bool {
+	return t.expiresAt != 0 && t.expiresAt <= now.UnixMilli()
+}
+
+func (t *token) view() Token {
+	scope := Scope{
+		Type:      "user",
+		Origin:    "manual",
+		CreatedAt: t.createdAt,
+	}
+
+	if t.teamID != "" {
+		scope.Type = "team"
+		scope.TeamID = t.teamID
+	}
+
+	return Token{
+		ID:        t.id,
+		Name:      t.name,
+		Type:      "token",
+		Origin:    "manual",
+		Scopes:    []Scope{scope},
+		ExpiresAt: t.expiresAt,
+		ActiveAt:  t.activeAt,
+		CreatedAt: t.createdAt,
+	}
+}
+
+// Tokens returns the live tokens currently known to the server, newest first.
+func (s *Server) Tokens() []Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	live := s.liveTokens("")
+	out := make([]Token, 0, len(live))
+
+	for _, t := range live {
+		out = append(out, t.view())
+	}
+
+	return out
+}
+
+// liveTokens returns unexpired tokens sorted by creation time, newest first.
+// Expired tokens are purged as a side effect. Callers must hold s.mu.
+func (s *Server) liveTokens(teamID string) []*token {
+	now := s.cfg.Now()
+	out := make([]*token, 0, len(s.tokens))
+
+	for id, t := range s.tokens {
+		if t.expired(now) {
+			delete(s.tokens, id)
+
+			continue
+		}
+
+		if teamID != "" && t.teamID != teamID {
+			continue
+		}
+
+		out = append(out, t)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].createdAt > out[j].createdAt
+	})
+
+	return out
+}
+
+func (s *Server) createToken(w http.ResponseWriter, r *http.Request, teamID string) {
+	var req createTokenRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, apiError{
+			Code:    "bad_request",
+			Message: "Invalid request body",
+		})
+
+		return
+	}
+
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, apiError{
+			Code:    "bad_request",
+			Message: "Invalid request: missing required property `name`",
+		})
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.cfg.Now().UnixMilli()
+	if req.ExpiresAt != 0 && req.ExpiresAt <= now {
+		writeError(w, http.StatusBadRequest, apiError{
+			Code:    "bad_request",
+			Message: "Invalid request: `expiresAt` must be in the future",
+		})
+
+		return
+	}
+
+	// Creation timestamps double as pagination cursors, so keep them unique.
+	if now <= s.lastCreated {
+		now = s.lastCreated + 1
+	}
+
+	s.lastCreated = now
+
+	t := &token{
+		id:          randomHex(tokenIDBytes),
+		name:        req.Name,
+		bearerToken: randomHex(bearerTokenBytes),
+		teamID:      teamID,
+		createdAt:   now,
+		activeAt:    now,
+		expiresAt:   req.ExpiresAt,
+	}
+	s.tokens[t.id] = t
+
+	writeJSON(w, http.StatusOK, createTokenResponse{
+		Token:       t.view(),
+		BearerToken: t.bearerToken,
+	})
+}
+
+func (s *Server) listTokens(w http.ResponseWriter, r *http.Request, teamID string) {
+	q := r.URL.Query()
+
+	limit, err := queryInt(q.Get("limit"), defaultPageSize)
+	if err != nil || limit <= 0 || limit > maxPageSize {
+		writeError(w, http.StatusBadRequest, apiError{
+			Code:    "bad_request",
+			Message: "Invalid request: `limit` must be between 1 and 100",
+		})
+
+		return
+	}
+
+	until, err := queryInt(q.Get("until"), 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, apiError{
+			Code:    "bad_request",
+			Message: "Invalid request: `until` must be a timestamp",
+		})
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all := s.liveTokens(teamID)
+	page := make([]Token, 0, limit)
+	more := false
+
+	for _, t := range all {
+		if until != 0 && t.createdAt >= int64(until) {
+			continue
+		}
+
+		if len(page) == limit {
+			more = true
+
+			break
+		}
+
+		page = append(page, t.view())
+	}
+
+	res := listTokensResponse{
+		Tokens: page,
+		Pagination: pagination{
+			Count: len(page),
+		},
+	}
+
+	if len(page) > 0 {
+		prev := page[0].CreatedAt
+		res.Pagination.Prev = &prev
+
+		if more {
+			next := page[len(page)-1].CreatedAt
+			res.Pagination.Next = &next
+		}
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) getToken(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.lookup(id)
+	if !ok {
+		writeTokenNotFound(w)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, getTokenResponse{Token: t.view()})
+}
+
+func (s *Server) deleteToken(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lookup(id); !ok {
+		writeTokenNotFound(w)
+
+		return
+	}
+
+	delete(s.tokens, id)
+
+	writeJSON(w, http.StatusOK, deleteTokenResponse{TokenID: id})
+}
+
+// lookup returns a live token by ID. Callers must hold s.mu.
+func (s *Server) lookup(id string) (*token, bool) {
+	t, ok := s.tokens[id]
+	if !ok {
+		return nil, false
+	}
+
+	if t.expired(s.cfg.Now()) {
+		delete(s.tokens, id)
+
+		return nil, false
+	}
+
+	return t, true
+}
+
+func writeTokenNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, apiError{
+		Code:    "not_found",
+		Message: "Token not found",
+	})
+}
+
+func queryInt(v string, def int) (int, error) {
+	if v == "" {
+		return def, nil
+	}
+
+	return strconv.Atoi(v)
+}
@@ -0,0 +1,124 @@
+package fakevercel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+const (
+	endpointCreateWebhook = "POST /webhooks"
+	endpointDeleteWebhook = "DELETE /webhooks/:id"
+	webhookIDBytes        = 12
+	webhookSecretBytes    = 12
+)
+
+type webhook struct {
+	id         string
+	url        string
+	events     []string
+	projectIDs []string
+	teamID     string
+	createdAt  int64
+}
+
+// Webhook is the JSON representation of a webhook returned by the fake server.
+type Webhook struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	Events     []string `json:"events"`
+	ProjectIDs []string `json:"projectIds,omitempty"`
+	OwnerID    string   `json:"ownerId"`
+	CreatedAt  int64    `json:"createdAt"`
+}
+
+type createWebhookRequest struct {
+	URL        string   `json:"url"`
+	Events     []string `json:"events"`
+	ProjectIDs []string `json:"projectIds"`
+}
+
+type createWebhookResponse struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+func (h *webhook) view() Webhook {
+	return Webhook{
+		ID:         h.id,
+		URL:        h.url,
+		Events:     h.events,
+		ProjectIDs: h.projectIDs,
+		OwnerID:    h.teamID,
+		CreatedAt:  h.createdAt,
+	}
+}
+
+// Webhooks returns the webhooks currently registered with the server.
+func (s *Server) Webhooks() []Webhook {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]Webhook, 0, len(s.webhooks))
+	for _, h := range s.webhooks {
+		out = append(out, h.view())
+	}
+
+	return out
+}
+
+func (s *Server) createWebhook(w http.ResponseWriter, r *http.Request, teamID string) {
+	var req createWebhookRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Events) == 0 {
+		writeError(w, http.StatusBadRequest, apiError{
+			Code:    "bad_request",
+			Message: "Invalid request: missing required property `events`",
+		})
+
+		return
+	}
+
+	if u, err := url.Parse(req.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+		writeError(w, http.StatusBadRequest, apiError{
+			Code:    "bad_request",
+			Message: "Invalid request: `url` must be an https URL",
+		})
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := &webhook{
+		id:         "hook_" + randomHex(webhookIDBytes),
+		url:        req.URL,
+		events:     req.Events,
+		projectIDs: req.ProjectIDs,
+		teamID:     teamID,
+		createdAt:  s.cfg.Now().UnixMilli(),
+	}
+	s.webhooks[h.id] = h
+
+	writeJSON(w, http.StatusOK, createWebhookResponse{Webhook: h.view(), Secret: randomHex(webhookSecretBytes)})
+}
+
+func (s *Server) deleteWebhook(w http.ResponseWriter, id, teamID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, ok := s.webhooks[id]
+	if !ok || h.teamID != teamID {
+		writeError(w, http.StatusNotFound, apiError{
+			Code:    "not_found",
+			Message: "Webhook not found",
+		})
+
+		return
+	}
+
+	delete(s.webhooks, id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
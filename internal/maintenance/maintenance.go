@@ -0,0 +1,240 @@
+// Package maintenance finds and deletes Vercel tokens created by the plugin
+// directly through the Vercel API, without Vault. It is meant for recovering
+// from a Vault outage or a lost Vault installation.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/client"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+const (
+	// DefaultPrefix matches the names the plugin gives to the tokens it creates.
+	DefaultPrefix = "vault-plugin-secrets-vercel-"
+	// PersonalScope is shown as the team of tokens with personal account scope.
+	PersonalScope = "personal"
+
+	listPageSize = 100
+)
+
+var (
+	// ErrInvalidPrefix is returned for a filter that could select tokens not
+	// created by the plugin.
+	ErrInvalidPrefix = fmt.Errorf("token name prefix must start with %q", DefaultPrefix)
+)
+
+// Filter selects tokens created by the plugin. Zero values other than Prefix
+// match every such token.
+type Filter struct {
+	// Prefix is the required token name prefix. It must start with
+	// DefaultPrefix.
+	Prefix string
+	// TeamID limits the selection to tokens scoped to this team.
+	// PersonalScope selects tokens with personal account scope.
+	TeamID string
+	// OlderThan selects tokens created at least this long ago.
+	OlderThan time.Duration
+	// Now overrides the clock used for age calculations.
+	Now func() time.Time
+}
+
+// Result is the outcome of deleting a single token.
+type Result struct {
+	Token   vercel.Token
+	Deleted bool
+	Err     error
+}
+
+func (f Filter) now() time.Time {
+	if f.Now != nil {
+		return f.Now()
+	}
+
+	return time.Now()
+}
+
+// Validate rejects a filter whose prefix could select tokens not created by
+// the plugin.
+func (f Filter) Validate() error {
+	if !strings.HasPrefix(f.Prefix, DefaultPrefix) {
+		return ErrInvalidPrefix
+	}
+
+	return nil
+}
+
+func (f Filter) match(t vercel.Token, now time.Time) bool {
+	if f.Validate() != nil || !strings.HasPrefix(t.Name, f.Prefix) {
+		return false
+	}
+
+	switch f.TeamID {
+	case "":
+	case PersonalScope:
+		if t.TeamID() != "" {
+			return false
+		}
+	default:
+		if t.TeamID() != f.TeamID {
+			return false
+		}
+	}
+
+	if f.OlderThan > 0 && now.Sub(time.UnixMilli(t.CreatedAt)) < f.OlderThan {
+		return false
+	}
+
+	return true
+}
+
+// List returns every token matching the filter, walking all pages.
+func List(ctx context.Context, c client.Client, f Filter) ([]vercel.Token, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := f.now()
+	out := make([]vercel.Token, 0)
+
+	for t, err := range vercel.AuthTokens(ctx, c, listPageSize) {
+		if err != nil {
+			return nil, err
+		}
+
+		if f.match(t, now) {
+			out = append(out, t)
+		}
+	}
+
+	return out, nil
+}
+
+// Delete deletes the given tokens and reports the outcome per token. Nothing
+// is deleted on a dry run. Tokens already gone from Vercel count as deleted.
+func Delete(ctx context.Context, c client.Client, tokens []vercel.Token, dryRun bool) []Result {
+	return DeleteParallel(ctx, c, tokens, dryRun, 1)
+}
+
+// DeleteParallel is Delete with up to parallelism deletions in flight at once.
+// Results are returned in the order of tokens.
+func DeleteParallel(ctx context.Context, c client.Client, tokens []vercel.Token, dryRun bool,
+	parallelism int) []Result {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	out := make([]Result, len(tokens))
+	sem := make(chan struct{}, parallelism)
+
+	var wg sync.WaitGroup
+
+	for i, t := range tokens {
+		out[i] = Result{Token: t}
+
+		if dryRun {
+			continue
+		}
+
+		// Once the context is done, the remaining tokens are reported as
+		// failed instead of waiting for a free slot.
+		if err := ctx.Err(); err != nil {
+			out[i].Err = err
+
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			out[i].Err = ctx.Err()
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(r *Result) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			_, err := c.DeleteAuthToken(ctx, &vercel.DeleteAuthTokenRequest{ID: r.Token.ID})
+			if err == nil || errors.Is(err, vercel.ErrNotFound) {
+				r.Deleted = true
+			} else {
+				r.Err = err
+			}
+		}(&out[i])
+	}
+
+	wg.Wait()
+
+	return out
+}
+
+// WriteTable writes the tokens as a table with their team, age and expiry.
+func WriteTable(w io.Writer, tokens []vercel.Token, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ID\tNAME\tTEAM\tAGE\tEXPIRES")
+
+	for _, t := range tokens {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", t.ID, t.Name, teamName(t), age(t, now), expiry(t))
+	}
+
+	return tw.Flush()
+}
+
+// WriteResults writes the outcome of Delete, one line per token.
+func WriteResults(w io.Writer, results []Result, dryRun bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ID\tNAME\tTEAM\tRESULT")
+
+	for _, r := range results {
+		status := "deleted"
+
+		switch {
+		case dryRun:
+			status = "would delete"
+		case r.Err != nil:
+			status = "failed: " + r.Err.Error()
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Token.ID, r.Token.Name, teamName(r.Token), status)
+	}
+
+	return tw.Flush()
+}
+
+func teamName(t vercel.Token) string {
+	if id := t.TeamID(); id != "" {
+		return id
+	}
+
+	return PersonalScope
+}
+
+func age(t vercel.Token, now time.Time) string {
+	if t.CreatedAt == 0 {
+		return "-"
+	}
+
+	return now.Sub(time.UnixMilli(t.CreatedAt)).Round(time.Second).String()
+}
+
+func expiry(t vercel.Token) string {
+	if t.ExpiresAt == 0 {
+		return "never"
+	}
+
+	return time.UnixMilli(t.ExpiresAt).UTC().Format(time.RFC3339)
+}
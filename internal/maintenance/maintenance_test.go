@@ -0,0 +1,285 @@
+package maintenance
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+const testAPIKey = "root-key"
+
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// newTestServer creates two plugin tokens, one per scope, and an unrelated
+// token, followed two hours later by a recent plugin token.
+func newTestServer(t *testing.T) (*fakevercel.Server, *vercel.Client, *testClock) {
+	t.Helper()
+
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	s, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{
+		APIKey: testAPIKey,
+		Teams:  []string{"team_a"},
+		Now:    clock.Now,
+	})
+	t.Cleanup(ts.Close)
+
+	c := vercel.NewClient(testAPIKey, vercel.WithBaseURL(ts.URL))
+
+	create := func(name, teamID string) {
+		t.Helper()
+
+		_, err := c.CreateAuthToken(context.Background(), &vercel.CreateAuthTokenRequest{
+			Name:      name,
+			TeamID:    teamID,
+			ExpiresAt: clock.Now().Add(24 * time.Hour).UnixMilli(),
+		})
+		require.NoError(t, err)
+	}
+
+	create(DefaultPrefix+"1", "")
+	create(DefaultPrefix+"2", "team_a")
+	create("manual-token", "")
+	clock.Advance(2 * time.Hour)
+	create(DefaultPrefix+"3", "team_a")
+
+	return s, c, clock
+}
+
+func names(tokens []vercel.Token) []string {
+	out := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, t.Name)
+	}
+
+	return out
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	_, c, clock := newTestServer(t)
+
+	cases := map[string]struct {
+		filter   Filter
+		expNames []string
+		expErr   error
+	}{
+		"no prefix": {
+			expErr: ErrInvalidPrefix,
+		},
+		"prefix of other tokens": {
+			filter: Filter{Prefix: "manual"},
+			expErr: ErrInvalidPrefix,
+		},
+		"prefix shorter than the plugin prefix": {
+			filter: Filter{Prefix: "v"},
+			expErr: ErrInvalidPrefix,
+		},
+		"plugin tokens": {
+			filter:   Filter{Prefix: DefaultPrefix},
+			expNames: []string{DefaultPrefix + "3", DefaultPrefix + "2", DefaultPrefix + "1"},
+		},
+		"team": {
+			filter:   Filter{Prefix: DefaultPrefix, TeamID: "team_a"},
+			expNames: []string{DefaultPrefix + "3", DefaultPrefix + "2"},
+		},
+		"personal scope": {
+			filter:   Filter{Prefix: DefaultPrefix, TeamID: PersonalScope},
+			expNames: []string{DefaultPrefix + "1"},
+		},
+		"older than": {
+			filter:   Filter{Prefix: DefaultPrefix, OlderThan: time.Hour, Now: clock.Now},
+			expNames: []string{DefaultPrefix + "2", DefaultPrefix + "1"},
+		},
+		"longer prefix": {
+			filter:   Filter{Prefix: DefaultPrefix + "3"},
+			expNames: []string{DefaultPrefix + "3"},
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tokens, err := List(context.Background(), c, tc.filter)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expNames, names(tokens))
+		})
+	}
+}
+
+func TestList_Pagination(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	_, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{APIKey: testAPIKey, RateLimit: 1000})
+	t.Cleanup(ts.Close)
+
+	c := vercel.NewClient(testAPIKey, vercel.WithBaseURL(ts.URL))
+
+	total := listPageSize + 5
+	for i := 0; i < total; i++ {
+		_, err := c.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{Name: DefaultPrefix + "x"})
+		require.NoError(t, err)
+	}
+
+	tokens, err := List(ctx, c, Filter{Prefix: DefaultPrefix})
+	require.NoError(t, err)
+	require.Len(t, tokens, total)
+}
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, c, clock := newTestServer(t)
+
+	tokens, err := List(ctx, c, Filter{Prefix: DefaultPrefix, OlderThan: time.Hour, Now: clock.Now})
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+
+	results := Delete(ctx, c, tokens, true)
+	require.Len(t, results, 2)
+	require.False(t, results[0].Deleted)
+	require.Len(t, s.Tokens(), 4)
+
+	var out bytes.Buffer
+
+	require.NoError(t, WriteResults(&out, results, true))
+	require.Equal(t, 2, strings.Count(out.String(), "would delete"))
+
+	// One token disappears between listing and deleting.
+	_, err = c.DeleteAuthToken(ctx, &vercel.DeleteAuthTokenRequest{ID: tokens[0].ID})
+	require.NoError(t, err)
+
+	results = Delete(ctx, c, tokens, false)
+	for _, r := range results {
+		require.True(t, r.Deleted)
+		require.NoError(t, r.Err)
+	}
+
+	require.Equal(t, []string{DefaultPrefix + "3", "manual-token"}, names(toClientTokens(s.Tokens())))
+
+	results = Delete(ctx, vercel.NewClient("bogus", vercel.WithBaseURL(c.GetBaseURL())), tokens[:1], false)
+	require.False(t, results[0].Deleted)
+	require.ErrorIs(t, results[0].Err, vercel.ErrInvalidAPIKey)
+
+	out.Reset()
+	require.NoError(t, WriteResults(&out, results, false))
+	require.Contains(t, out.String(), "failed: ")
+}
+
+func TestWriteTable(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700003600, 0)
+	tokens := []vercel.Token{
+		{
+			ID:        "tok_a",
+			Name:      DefaultPrefix + "1",
+			CreatedAt: time.Unix(1700000000, 0).UnixMilli(),
+			ExpiresAt: time.Unix(1700007200, 0).UnixMilli(),
+			Scopes:    []vercel.TokenScope{{Type: "team", TeamID: "team_a"}},
+		},
+		{
+			ID:   "tok_b",
+			Name: DefaultPrefix + "2",
+		},
+	}
+
+	var out bytes.Buffer
+
+	require.NoError(t, WriteTable(&out, tokens, now))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"ID", "NAME", "TEAM", "AGE", "EXPIRES"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"tok_a", DefaultPrefix + "1", "team_a", "1h0m0s", "2023-11-15T00:13:20Z"},
+		strings.Fields(lines[1]))
+	require.Equal(t, []string{"tok_b", DefaultPrefix + "2", PersonalScope, "-", "never"}, strings.Fields(lines[2]))
+}
+
+func toClientTokens(in []fakevercel.Token) []vercel.Token {
+	out := make([]vercel.Token, 0, len(in))
+	for _, t := range in {
+		out = append(out, vercel.Token{ID: t.ID, Name: t.Name})
+	}
+
+	return out
+}
+
+func TestDeleteParallel(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, c, _ := newTestServer(t)
+
+	tokens, err := List(ctx, c, Filter{Prefix: DefaultPrefix})
+	require.NoError(t, err)
+	require.Len(t, tokens, 3)
+
+	results := DeleteParallel(ctx, c, tokens, false, 2)
+	require.Len(t, results, 3)
+
+	for i, r := range results {
+		require.Equal(t, tokens[i].ID, r.Token.ID)
+		require.True(t, r.Deleted)
+		require.NoError(t, r.Err)
+	}
+
+	require.Equal(t, []string{"manual-token"}, names(toClientTokens(s.Tokens())))
+}
+
+func TestDeleteParallel_Canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s, c, _ := newTestServer(t)
+
+	tokens, err := List(ctx, c, Filter{Prefix: DefaultPrefix})
+	require.NoError(t, err)
+	require.Len(t, tokens, 3)
+
+	cancel()
+
+	results := DeleteParallel(ctx, c, tokens, false, 1)
+	require.Len(t, results, 3)
+
+	for _, r := range results {
+		require.False(t, r.Deleted)
+		require.ErrorIs(t, r.Err, context.Canceled)
+	}
+
+	require.Len(t, s.Tokens(), 4)
+}
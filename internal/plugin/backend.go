@@ -3,29 +3,56 @@ package plugin
 import (
 	"context"
 	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/client"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/service"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/tracing"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/version"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
 )
 
 const (
 	// #nosec G101
 	backendSecretType = "vercel_token"
-	backendPathHelp   = `
-Vercel Secrets backend is a secrets backend for dynamically managing Vercel tokens.`
+	// operationPrefixVercel prefixes the OpenAPI operation IDs of all paths.
+	operationPrefixVercel = "vercel"
+	backendPathHelp       = `
+Vercel Secrets backend is a secrets backend for dynamically managing Vercel tokens,
+project protection bypass secrets, Edge Config read tokens and webhooks.`
 	// #nosec G101
 	secretTokenIDDescription = `
 Token ID of the generated API key is stored in the plugin backend.
 This ID is used for revocation purposes. It can only be used to identify a key,
 and cannot be used to do API operations.`
+	// envAllowMockClient must be set to a true value in the plugin environment
+	// before client_type=mock is accepted.
+	envAllowMockClient = "VAULT_PLUGIN_SECRETS_VERCEL_ALLOW_MOCK"
 )
 
 var (
 	errBackendEmptyConfig = errors.New("configuration passed into backend is nil")
+	errMockClientDisabled = errors.New("mock client is disabled, set " + envAllowMockClient +
+		"=true in the plugin environment to enable it")
 )
 
 type backend struct {
 	*framework.Backend
+
+	lock            sync.RWMutex
+	svc             *service.Service
+	teams           *teamCache
+	allowMockClient bool
+	// configLock serializes config writes and deletions, which add versions
+	// to the config history.
+	configLock sync.Mutex
+	// issuancesPrunedAt is when issuance records were last pruned.
+	issuancesPrunedAt time.Time
 }
 
 var _ logical.Factory = Factory
@@ -45,15 +72,47 @@ func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend,
 }
 
 func newBackend() *backend {
-	b := &backend{}
+	allowMock, _ := strconv.ParseBool(os.Getenv(envAllowMockClient))
+
+	b := &backend{
+		allowMockClient: allowMock,
+	}
 
 	b.Backend = &framework.Backend{
-		Help:        backendPathHelp,
-		BackendType: logical.TypeLogical,
+		Help:           backendPathHelp,
+		BackendType:    logical.TypeLogical,
+		Invalidate:     b.invalidate,
+		RunningVersion: version.RunningVersion(),
+		PathsSpecial: &logical.Paths{
+			// The config entries hold the Vercel root API key.
+			SealWrapStorage: []string{
+				pathPatternConfig,
+				revokeOnlyConfigPrefix,
+			},
+			// Leases are tracked per cluster, so are their failed revocations
+			// and issuance and lease records.
+			LocalStorage: []string{
+				revocationQueuePrefix,
+				issuancePrefix,
+				leaseRecordPrefix,
+			},
+		},
+		PeriodicFunc: b.periodicFunc,
 		Paths: framework.PathAppend(
 			b.pathConfig(),
+			b.pathConfigHistory(),
 			b.pathToken(),
 			b.pathInfo(),
+			b.pathRevocations(),
+			b.pathProjectEnv(),
+			b.pathUsage(),
+			b.pathProtectionBypass(),
+			b.pathEdgeConfig(),
+			b.pathWebhook(),
+			b.pathLockdown(),
+			b.pathRevokeAll(),
+			b.pathTeams(),
+			b.pathRateLimits(),
 		),
 		Secrets: []*framework.Secret{
 			{
@@ -64,10 +123,95 @@ func newBackend() *backend {
 						Description: secretTokenIDDescription,
 					},
 				},
-				Revoke: b.Revoke,
+				Revoke: traced("vercel.token.revoke", b.Revoke),
 			},
+			b.protectionBypassSecret(),
+			b.edgeConfigTokenSecret(),
+			b.webhookSecret(),
 		},
 	}
 
 	return b
 }
+
+// getService returns the cached service client, creating it from the given
+// configuration when the cache is empty.
+func (b *backend) getService(cfg *backendConfig) (*service.Service, error) {
+	b.lock.RLock()
+	svc := b.svc
+	b.lock.RUnlock()
+
+	if svc != nil {
+		return svc, nil
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.svc != nil {
+		return b.svc, nil
+	}
+
+	svc, err := b.newService(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	b.svc = svc
+
+	return b.svc, nil
+}
+
+// newService creates a service client for the given configuration.
+func (b *backend) newService(cfg *backendConfig) (*service.Service, error) {
+	var c client.Client
+
+	switch cfg.ClientType {
+	case clientTypeMock:
+		if !b.allowMockClient {
+			return nil, errMockClientDisabled
+		}
+
+		c = client.NewMockClientWithConfig(cfg.Mock.clientConfig())
+	default:
+		// Configs written before client_type existed selected the mock
+		// client with api_key=mock. Refuse them rather than sending the
+		// placeholder key to Vercel.
+		if cfg.APIKey == legacyMockAPIKey {
+			return nil, errLegacyMockAPIKey
+		}
+
+		c = vercel.NewClient(cfg.APIKey,
+			vercel.WithBaseURL(cfg.BaseURL),
+			vercel.WithTracerProvider(tracing.TracerProvider()),
+		)
+	}
+
+	breakerCfg := cfg.breakerConfig()
+	breakerCfg.OnStateChange = func(from, to client.BreakerState) {
+		if to == client.BreakerOpen {
+			b.Logger().Warn("vercel api circuit breaker opened, failing requests fast", "from", from)
+
+			return
+		}
+
+		b.Logger().Info("vercel api circuit breaker state changed", "from", from, "to", to)
+	}
+
+	return service.NewWithClient(client.NewCircuitBreaker(c, breakerCfg)), nil
+}
+
+func (b *backend) reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.svc = nil
+	b.teams = nil
+}
+
+func (b *backend) invalidate(_ context.Context, key string) {
+	switch key {
+	case pathPatternConfig:
+		b.reset()
+	}
+}
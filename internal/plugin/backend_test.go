@@ -2,10 +2,14 @@ package plugin
 
 import (
 	"context"
+	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/version"
 )
 
 func TestFactory(t *testing.T) {
@@ -43,3 +47,178 @@ func TestFactory(t *testing.T) {
 		})
 	}
 }
+
+func TestBackend_PluginVersion(t *testing.T) {
+	t.Parallel()
+
+	b, _ := newTestBackend(t, nil)
+
+	var pv logical.PluginVersioner = b
+
+	v := pv.PluginVersion().Version
+	require.Equal(t, version.RunningVersion(), v)
+	require.True(t, strings.HasPrefix(v, "v"))
+}
+
+func TestBackend_PathsSpecial(t *testing.T) {
+	t.Parallel()
+
+	b, _ := newTestBackend(t, nil)
+	require.NotNil(t, b.PathsSpecial)
+	require.Contains(t, b.PathsSpecial.SealWrapStorage, pathPatternConfig)
+}
+
+func TestBackend_GetService(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	writeConfig := func(data map[string]any) {
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.CreateOperation,
+			Path:      pathPatternConfig,
+			Data:      data,
+		})
+		require.NoError(t, err)
+	}
+
+	writeConfig(map[string]any{"client_type": "mock"})
+
+	cfg, err := b.getConfig(ctx, storage)
+	require.NoError(t, err)
+
+	s1, err := b.getService(cfg)
+	require.NoError(t, err)
+
+	s2, err := b.getService(cfg)
+	require.NoError(t, err)
+	require.Same(t, s1, s2)
+
+	writeConfig(map[string]any{"client_type": "mock"})
+
+	s3, err := b.getService(cfg)
+	require.NoError(t, err)
+	require.NotSame(t, s1, s3)
+
+	b.invalidate(ctx, pathPatternConfig)
+	b.allowMockClient = false
+
+	_, err = b.getService(cfg)
+	require.ErrorIs(t, err, errMockClientDisabled)
+
+	// A config stored before client_type existed selected the mock client
+	// with api_key=mock, and must not be sent to Vercel.
+	_, err = b.getService(&backendConfig{APIKey: legacyMockAPIKey})
+	require.ErrorIs(t, err, errLegacyMockAPIKey)
+}
+
+func TestBackend_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"client_type":               "mock",
+			"mock_create_script":        "503,503,200",
+			"circuit_breaker_threshold": 2,
+		},
+	})
+	require.NoError(t, err)
+
+	createToken := func() error {
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathPatternToken,
+			Data:      map[string]any{"name": "foo"},
+		})
+
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		err = createToken()
+
+		var coded logical.HTTPCodedError
+
+		require.ErrorAs(t, err, &coded)
+		require.Equal(t, http.StatusBadGateway, coded.Code())
+	}
+
+	// The breaker is open, so the successful response left in the script
+	// is not consumed.
+	err = createToken()
+	require.ErrorContains(t, err, "failed to create token: upstream unavailable: "+
+		"vercel api circuit breaker is open, retry after")
+
+	var coded logical.HTTPCodedError
+
+	require.ErrorAs(t, err, &coded)
+	require.Equal(t, http.StatusServiceUnavailable, coded.Code())
+
+	// Writing the configuration again starts with a closed breaker.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"client_type": "mock",
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, createToken())
+}
+
+func TestBackend_OpenAPI(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	sys, ok := b.System().(*logical.StaticSystemView)
+	require.True(t, ok)
+
+	sys.PluginEnvironment = &logical.PluginEnvironment{VaultVersion: "1.15.0"}
+
+	res, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.HelpOperation,
+		Path:      "",
+	})
+	require.NoError(t, err)
+
+	doc, ok := res.Data["openapi"].(*framework.OASDocument)
+	require.True(t, ok)
+
+	expOperationIDs := map[string][]string{
+		"/" + pathPatternConfig: {"vercel-configure", "vercel-delete-configuration"},
+		"/" + pathPatternToken:  {"vercel-generate-token", "vercel-generate-token-with-parameters"},
+		"/" + pathPatternInfo:   {"vercel-read-build-info"},
+	}
+
+	for path, expIDs := range expOperationIDs {
+		p, ok := doc.Paths[path]
+		require.True(t, ok, path)
+
+		var ids []string
+
+		for _, op := range []*framework.OASOperation{p.Get, p.Post, p.Delete} {
+			if op != nil {
+				ids = append(ids, op.OperationID)
+			}
+		}
+
+		require.ElementsMatch(t, expIDs, ids, path)
+	}
+
+	tokenResponse := doc.Paths["/"+pathPatternToken].Get.Responses[200]
+	require.NotNil(t, tokenResponse)
+	require.NotNil(t, tokenResponse.Content)
+}
@@ -0,0 +1,194 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/helper/identitytpl"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+var (
+	errInvalidTeamTemplate = errors.New("invalid identity template")
+	errResolveTeamTemplate = errors.New("failed to resolve identity template")
+	errMissingEntity       = errors.New("identity template requires a request with an entity")
+	errTeamNotAllowed      = errors.New("team is not in allowed_teams")
+)
+
+// identityTemplater resolves identity templates for the entity making a request.
+// The entity and its groups are looked up once, on first use.
+type identityTemplater struct {
+	sys      logical.SystemView
+	entityID string
+	loaded   bool
+	entity   *logical.Entity
+	groups   []*logical.Group
+}
+
+func (b *backend) templater(req *logical.Request) *identityTemplater {
+	return &identityTemplater{
+		sys:      b.System(),
+		entityID: req.EntityID,
+	}
+}
+
+func isTemplate(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+// validateTemplate reports whether s is a well-formed identity template.
+// Plain strings are always valid.
+func validateTemplate(s string) error {
+	if !isTemplate(s) {
+		return nil
+	}
+
+	_, _, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+		String:            s,
+		ValidityCheckOnly: true,
+		Mode:              identitytpl.ACLTemplating,
+	})
+	if err != nil {
+		return fmt.Errorf("%w %q: %w", errInvalidTeamTemplate, s, err)
+	}
+
+	return nil
+}
+
+// populate returns s with its identity templates resolved. Plain strings are
+// returned as they are.
+func (t *identityTemplater) populate(s string) (string, error) {
+	if !isTemplate(s) {
+		return s, nil
+	}
+
+	if err := t.load(); err != nil {
+		return "", err
+	}
+
+	_, out, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+		String:      s,
+		Entity:      t.entity,
+		Groups:      t.groups,
+		NamespaceID: t.entity.NamespaceID,
+		Mode:        identitytpl.ACLTemplating,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %w", errResolveTeamTemplate, s, err)
+	}
+
+	return out, nil
+}
+
+func (t *identityTemplater) load() error {
+	if t.loaded {
+		return nil
+	}
+
+	if t.entityID == "" {
+		return errMissingEntity
+	}
+
+	entity, err := t.sys.EntityInfo(t.entityID)
+	if err != nil {
+		return err
+	}
+
+	if entity == nil {
+		return errMissingEntity
+	}
+
+	groups, err := t.sys.GroupsForEntity(t.entityID)
+	if err != nil {
+		return err
+	}
+
+	t.entity, t.groups, t.loaded = entity, groups, true
+
+	return nil
+}
+
+// resolveTeamID returns the ID of the team a request operates on. The requested
+// team, if set, may be an identity template and cannot override default_team_id.
+// Teams may be given by ID or slug and are resolved through the cached teams of
+// the API key, so unknown teams are rejected before calling Vercel. When
+// allowed_teams is configured the team must match one of its entries by ID or
+// slug. Entries that do not resolve for the requesting entity match nothing.
+func (b *backend) resolveTeamID(ctx context.Context, req *logical.Request, cfg *backendConfig,
+	requested string, set bool) (string, error) {
+	t := b.templater(req)
+	ref := cfg.DefaultTeamID
+
+	if set {
+		v, err := t.populate(requested)
+		if err != nil {
+			return "", err
+		}
+
+		if ref != "" && v != "" && ref != v {
+			if err = b.checkSameTeam(ctx, cfg, ref, v); err != nil {
+				return "", err
+			}
+		}
+
+		ref = v
+	}
+
+	var team vercel.Team
+
+	if ref != "" {
+		var err error
+
+		team, err = b.lookupTeam(ctx, cfg, ref)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if len(cfg.AllowedTeams) == 0 {
+		return team.ID, nil
+	}
+
+	for _, a := range cfg.AllowedTeams {
+		v, err := t.populate(a)
+		if errors.Is(err, errResolveTeamTemplate) || errors.Is(err, errMissingEntity) {
+			continue
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if v != "" && (v == team.ID || v == team.Slug) {
+			return team.ID, nil
+		}
+	}
+
+	return "", errTeamNotAllowed
+}
+
+// checkSameTeam returns errCannotOverrideDefaultTeamID unless the requested
+// team is the default team given by its other identifier.
+func (b *backend) checkSameTeam(ctx context.Context, cfg *backendConfig, defaultRef, requested string) error {
+	def, err := b.lookupTeam(ctx, cfg, defaultRef)
+	if err != nil {
+		return err
+	}
+
+	if requested != def.ID && requested != def.Slug {
+		// Without the listing the slug of the default team is unknown, so
+		// tell that the requested slug could not be resolved.
+		if def.Slug == "" && !strings.HasPrefix(requested, teamIDPrefix) {
+			if _, err = b.lookupTeam(ctx, cfg, requested); err != nil {
+				return err
+			}
+		}
+
+		return errCannotOverrideDefaultTeamID
+	}
+
+	return nil
+}
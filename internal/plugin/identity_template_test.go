@@ -0,0 +1,246 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityTemplate_ValidateTemplate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, validateTemplate("team_a"))
+	require.NoError(t, validateTemplate("{{identity.entity.metadata.vercel_team}}"))
+	require.ErrorIs(t, validateTemplate("{{identity.entity.metadata.vercel_team"), errInvalidTeamTemplate)
+}
+
+func TestIdentityTemplate_ResolveTeamID(t *testing.T) {
+	t.Parallel()
+
+	entity := &logical.Entity{
+		ID:   "entity-1",
+		Name: "repo-a",
+		Metadata: map[string]string{
+			"vercel_team": "team_a",
+		},
+	}
+
+	cases := map[string]struct {
+		cfg       *backendConfig
+		entityID  string
+		entity    *logical.Entity
+		requested string
+		set       bool
+		expTeamID string
+		expError  error
+	}{
+		"no team": {
+			cfg: &backendConfig{ClientType: clientTypeMock},
+		},
+		"default team": {
+			cfg:       &backendConfig{ClientType: clientTypeMock, DefaultTeamID: "team_a"},
+			expTeamID: "team_a",
+		},
+		"plain team": {
+			cfg:       &backendConfig{ClientType: clientTypeMock},
+			requested: "team_b",
+			set:       true,
+			expTeamID: "team_b",
+		},
+		"templated team": {
+			cfg:       &backendConfig{ClientType: clientTypeMock},
+			entityID:  entity.ID,
+			entity:    entity,
+			requested: "{{identity.entity.metadata.vercel_team}}",
+			set:       true,
+			expTeamID: "team_a",
+		},
+		"templated team without entity": {
+			cfg:       &backendConfig{ClientType: clientTypeMock},
+			requested: "{{identity.entity.metadata.vercel_team}}",
+			set:       true,
+			expError:  errMissingEntity,
+		},
+		"templated team with missing metadata": {
+			cfg:       &backendConfig{ClientType: clientTypeMock},
+			entityID:  entity.ID,
+			entity:    entity,
+			requested: "{{identity.entity.metadata.other}}",
+			set:       true,
+			expError:  errResolveTeamTemplate,
+		},
+		"templated team overriding default": {
+			cfg:       &backendConfig{ClientType: clientTypeMock, DefaultTeamID: "team_b"},
+			entityID:  entity.ID,
+			entity:    entity,
+			requested: "{{identity.entity.metadata.vercel_team}}",
+			set:       true,
+			expError:  errCannotOverrideDefaultTeamID,
+		},
+		"allowed templated team": {
+			cfg: &backendConfig{
+				ClientType:   clientTypeMock,
+				AllowedTeams: []string{"{{identity.entity.metadata.vercel_team}}"},
+			},
+			entityID:  entity.ID,
+			entity:    entity,
+			requested: "team_a",
+			set:       true,
+			expTeamID: "team_a",
+		},
+		"other entity's team": {
+			cfg: &backendConfig{
+				ClientType:   clientTypeMock,
+				AllowedTeams: []string{"{{identity.entity.metadata.vercel_team}}"},
+			},
+			entityID:  entity.ID,
+			entity:    entity,
+			requested: "team_b",
+			set:       true,
+			expError:  errTeamNotAllowed,
+		},
+		"allowed plain team after unresolved template": {
+			cfg: &backendConfig{
+				ClientType:   clientTypeMock,
+				AllowedTeams: []string{"{{identity.entity.metadata.vercel_team}}", "team_b"},
+			},
+			requested: "team_b",
+			set:       true,
+			expTeamID: "team_b",
+		},
+		"team slug": {
+			cfg:       &backendConfig{ClientType: clientTypeMock},
+			requested: "team-b",
+			set:       true,
+			expTeamID: "team_b",
+		},
+		"default team slug": {
+			cfg:       &backendConfig{ClientType: clientTypeMock, DefaultTeamID: "team-a"},
+			expTeamID: "team_a",
+		},
+		"default team slug requested by id": {
+			cfg:       &backendConfig{ClientType: clientTypeMock, DefaultTeamID: "team-a"},
+			requested: "team_a",
+			set:       true,
+			expTeamID: "team_a",
+		},
+		"other team overriding default slug": {
+			cfg:       &backendConfig{ClientType: clientTypeMock, DefaultTeamID: "team-a"},
+			requested: "team-b",
+			set:       true,
+			expError:  errCannotOverrideDefaultTeamID,
+		},
+		"unknown team": {
+			cfg:       &backendConfig{ClientType: clientTypeMock},
+			requested: "team_unknown",
+			set:       true,
+			expError:  errUnknownTeam,
+		},
+		"unknown default team": {
+			cfg:      &backendConfig{ClientType: clientTypeMock, DefaultTeamID: "team_unknown"},
+			expError: errUnknownTeam,
+		},
+		"allowed team slug": {
+			cfg: &backendConfig{
+				ClientType:   clientTypeMock,
+				AllowedTeams: []string{"team-a"},
+			},
+			requested: "team_a",
+			set:       true,
+			expTeamID: "team_a",
+		},
+		"personal scope with allowed teams": {
+			cfg: &backendConfig{
+				ClientType:   clientTypeMock,
+				AllowedTeams: []string{"team_a"},
+			},
+			expError: errTeamNotAllowed,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			b, _ := newTestBackend(t, nil)
+
+			sys, ok := b.System().(*logical.StaticSystemView)
+			require.True(t, ok)
+
+			sys.EntityVal = tc.entity
+
+			teamID, err := b.resolveTeamID(context.Background(), &logical.Request{EntityID: tc.entityID}, tc.cfg, tc.requested, tc.set)
+			if tc.expError != nil {
+				require.ErrorIs(t, err, tc.expError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expTeamID, teamID)
+		})
+	}
+}
+
+func TestIdentityTemplate_Token(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	sys, ok := b.System().(*logical.StaticSystemView)
+	require.True(t, ok)
+
+	sys.EntityVal = &logical.Entity{
+		ID:       "entity-1",
+		Metadata: map[string]string{"vercel_team": "team_a"},
+	}
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"client_type":   "mock",
+			"allowed_teams": "{{identity.entity.metadata.vercel_team}}",
+		},
+	})
+	require.NoError(t, err)
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternToken,
+		EntityID:  "entity-1",
+		Data: map[string]any{
+			"team_id": "{{identity.entity.metadata.vercel_team}}",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "team_a", r.Data[pathTokenTeamID])
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternToken,
+		EntityID:  "entity-1",
+		Data: map[string]any{
+			"team_id": "team_b",
+		},
+	})
+	require.ErrorIs(t, err, errTeamNotAllowed)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"client_type":   "mock",
+			"allowed_teams": "{{identity.entity.metadata.vercel_team",
+		},
+	})
+	require.ErrorIs(t, err, errInvalidTeamTemplate)
+}
@@ -0,0 +1,192 @@
+package plugin
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/service"
+)
+
+const (
+	issuancePrefix = "issuances/"
+	// issuanceRetention is how long issuance records are kept after the token was issued.
+	issuanceRetention = 90 * 24 * time.Hour
+	// issuancePruneInterval is how often the periodic function prunes issuance
+	// records, as pruning reads every record.
+	issuancePruneInterval = 24 * time.Hour
+)
+
+// issuanceRecord tracks a token issued by the plugin and, once its lease is
+// revoked, whether the token was ever used.
+type issuanceRecord struct {
+	TokenID     string    `json:"token_id"`
+	TeamID      string    `json:"team_id,omitempty"`
+	EntityID    string    `json:"entity_id,omitempty"`
+	DisplayName string    `json:"display_name,omitempty"`
+	IssuedAt    time.Time `json:"issued_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	RevokedAt   time.Time `json:"revoked_at,omitempty"`
+	// UsageKnown is set once the token activity has been fetched from Vercel.
+	UsageKnown bool      `json:"usage_known,omitempty"`
+	Used       bool      `json:"used,omitempty"`
+	ActiveAt   time.Time `json:"active_at,omitempty"`
+	// CredentialID identifies the configuration the token was issued with.
+	CredentialID string `json:"credential_id,omitempty"`
+}
+
+func issuanceKey(tokenID string) string {
+	return issuancePrefix + url.PathEscape(tokenID)
+}
+
+// requester returns a human readable name for whoever requested the token.
+func (r *issuanceRecord) requester() string {
+	switch {
+	case r.DisplayName != "":
+		return r.DisplayName
+	case r.EntityID != "":
+		return r.EntityID
+	default:
+		return "unknown"
+	}
+}
+
+func (b *backend) putIssuance(ctx context.Context, s logical.Storage, r *issuanceRecord) error {
+	e, err := logical.StorageEntryJSON(issuanceKey(r.TokenID), r)
+	if err != nil {
+		return err
+	}
+
+	return s.Put(ctx, e)
+}
+
+func (b *backend) getIssuance(ctx context.Context, s logical.Storage, tokenID string) (*issuanceRecord, error) {
+	e, err := s.Get(ctx, issuanceKey(tokenID))
+	if err != nil || e == nil {
+		return nil, err
+	}
+
+	var r issuanceRecord
+	if err = e.DecodeJSON(&r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+func (b *backend) listIssuances(ctx context.Context, s logical.Storage) ([]*issuanceRecord, error) {
+	keys, err := s.List(ctx, issuancePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*issuanceRecord, 0, len(keys))
+
+	for _, k := range keys {
+		e, err := s.Get(ctx, issuancePrefix+k)
+		if err != nil {
+			return nil, err
+		}
+
+		if e == nil {
+			continue
+		}
+
+		var r issuanceRecord
+		if err = e.DecodeJSON(&r); err != nil {
+			return nil, err
+		}
+
+		out = append(out, &r)
+	}
+
+	return out, nil
+}
+
+// recordUsage fetches the token activity from Vercel and stores it in the
+// issuance record. It must run before the token is deleted. Failures are
+// logged and never block revocation.
+func (b *backend) recordUsage(ctx context.Context, s logical.Storage, svc *service.Service, tokenID string) {
+	r, err := b.getIssuance(ctx, s, tokenID)
+	if err != nil {
+		b.Logger().Warn("failed to read issuance record", "token_id", tokenID, "error", err)
+
+		return
+	}
+
+	if r == nil || !r.RevokedAt.IsZero() {
+		return
+	}
+
+	activeAt, used, err := svc.AuthTokenLastUsed(ctx, tokenID)
+	if err != nil {
+		b.Logger().Warn("failed to fetch token activity from Vercel", "token_id", tokenID, "error", err)
+	} else {
+		r.UsageKnown, r.Used, r.ActiveAt = true, used, activeAt
+	}
+
+	if err = b.putIssuance(ctx, s, r); err != nil {
+		b.Logger().Warn("failed to write issuance record", "token_id", tokenID, "error", err)
+	}
+}
+
+// markRevoked records that the token has been deleted from Vercel. Failures
+// are logged and never fail the revocation.
+func (b *backend) markRevoked(ctx context.Context, s logical.Storage, tokenID string) {
+	r, err := b.getIssuance(ctx, s, tokenID)
+	if err != nil {
+		b.Logger().Warn("failed to read issuance record", "token_id", tokenID, "error", err)
+
+		return
+	}
+
+	if r == nil || !r.RevokedAt.IsZero() {
+		return
+	}
+
+	r.RevokedAt = time.Now().UTC()
+
+	if err = b.putIssuance(ctx, s, r); err != nil {
+		b.Logger().Warn("failed to write issuance record", "token_id", tokenID, "error", err)
+	}
+}
+
+// pruneIssuances deletes issuance records past the retention period and the
+// records of expired leases. It runs at most once per issuancePruneInterval.
+func (b *backend) pruneIssuances(ctx context.Context, s logical.Storage) error {
+	now := time.Now()
+
+	b.lock.RLock()
+	prunedAt := b.issuancesPrunedAt
+	b.lock.RUnlock()
+
+	if now.Sub(prunedAt) < issuancePruneInterval {
+		return nil
+	}
+
+	records, err := b.listIssuances(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	cutoff := now.Add(-issuanceRetention)
+
+	for _, r := range records {
+		if r.IssuedAt.Before(cutoff) {
+			if err = s.Delete(ctx, issuanceKey(r.TokenID)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = b.pruneLeaseRecords(ctx, s); err != nil {
+		return err
+	}
+
+	b.lock.Lock()
+	b.issuancesPrunedAt = now
+	b.lock.Unlock()
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package plugin
+
+import (
+	"context"
+	"time"
+
+	uuid "github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	leaseRecordPrefix = "leases/"
+	// secretLeaseRecordID is the lease internal data key of the lease record ID.
+	secretLeaseRecordID = "lease_record_id"
+)
+
+// leaseRecord tracks a protection bypass secret, Edge Config token or webhook
+// issued by the plugin until its lease is revoked. Tokens are tracked by
+// issuance records instead.
+type leaseRecord struct {
+	ID           string    `json:"id"`
+	SecretType   string    `json:"secret_type"`
+	CredentialID string    `json:"credential_id,omitempty"`
+	IssuedAt     time.Time `json:"issued_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// recordLease adds the credential ID of cfg to the internal data of a new
+// lease and stores a record of it. Failures to store the record are logged and
+// returned as a warning, as the credential has been issued.
+func (b *backend) recordLease(ctx context.Context, s logical.Storage, cfg *backendConfig,
+	secret *logical.Secret) string {
+	secret.InternalData[secretCredentialID] = cfg.credentialID()
+	secretType, _ := secret.InternalData["secret_type"].(string)
+
+	id, err := uuid.GenerateUUID()
+	if err == nil {
+		now := time.Now().UTC()
+		err = b.putLeaseRecord(ctx, s, &leaseRecord{
+			ID:           id,
+			SecretType:   secretType,
+			CredentialID: cfg.credentialID(),
+			IssuedAt:     now,
+			ExpiresAt:    now.Add(secret.TTL),
+		})
+	}
+
+	if err != nil {
+		b.Logger().Warn("failed to write lease record", "secret_type", secretType, "error", err)
+
+		return "failed to record the lease, it is not counted as outstanding when the configuration is deleted"
+	}
+
+	secret.InternalData[secretLeaseRecordID] = id
+
+	return ""
+}
+
+func (b *backend) putLeaseRecord(ctx context.Context, s logical.Storage, r *leaseRecord) error {
+	e, err := logical.StorageEntryJSON(leaseRecordPrefix+r.ID, r)
+	if err != nil {
+		return err
+	}
+
+	return s.Put(ctx, e)
+}
+
+func (b *backend) listLeaseRecords(ctx context.Context, s logical.Storage) ([]*leaseRecord, error) {
+	keys, err := s.List(ctx, leaseRecordPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*leaseRecord, 0, len(keys))
+
+	for _, k := range keys {
+		e, err := s.Get(ctx, leaseRecordPrefix+k)
+		if err != nil {
+			return nil, err
+		}
+
+		if e == nil {
+			continue
+		}
+
+		var r leaseRecord
+		if err = e.DecodeJSON(&r); err != nil {
+			return nil, err
+		}
+
+		out = append(out, &r)
+	}
+
+	return out, nil
+}
+
+// extendLeaseRecord moves the expiry of the record of a renewed lease to ttl
+// from now. Failures are logged and returned as a warning, as the lease has
+// been renewed.
+func (b *backend) extendLeaseRecord(ctx context.Context, s logical.Storage, secret *logical.Secret,
+	ttl time.Duration) string {
+	id, _ := secret.InternalData[secretLeaseRecordID].(string)
+	if id == "" {
+		return ""
+	}
+
+	e, err := s.Get(ctx, leaseRecordPrefix+id)
+	if err == nil && e != nil {
+		var r leaseRecord
+		if err = e.DecodeJSON(&r); err == nil {
+			r.ExpiresAt = time.Now().UTC().Add(ttl)
+			err = b.putLeaseRecord(ctx, s, &r)
+		}
+	}
+
+	if err != nil {
+		b.Logger().Warn("failed to extend lease record", "id", id, "error", err)
+
+		return "failed to update the lease record, the lease may not be counted as outstanding " +
+			"when the configuration is deleted"
+	}
+
+	return ""
+}
+
+// deleteLeaseRecord removes the record of a revoked lease. Failures are logged
+// and never fail the revocation, as the record expires with the lease.
+func (b *backend) deleteLeaseRecord(ctx context.Context, s logical.Storage, secret *logical.Secret) {
+	id, _ := secret.InternalData[secretLeaseRecordID].(string)
+	if id == "" {
+		return
+	}
+
+	if err := s.Delete(ctx, leaseRecordPrefix+id); err != nil {
+		b.Logger().Warn("failed to delete lease record", "id", id, "error", err)
+	}
+}
+
+// pruneLeaseRecords deletes the records of expired leases, whose revocation
+// failed or was never confirmed.
+func (b *backend) pruneLeaseRecords(ctx context.Context, s logical.Storage) error {
+	records, err := b.listLeaseRecords(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	for _, r := range records {
+		if now.After(r.ExpiresAt) {
+			if err = s.Delete(ctx, leaseRecordPrefix+r.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
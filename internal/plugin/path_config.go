@@ -3,10 +3,14 @@ package plugin
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/client"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
 )
 
 const (
@@ -15,25 +19,75 @@ const (
 	pathConfigBaseURL       = "base_url"
 	pathConfigMaxTTL        = "max_ttl"
 	pathConfigDefaultTeamID = "default_team_id"
-	defaultMaxTTL           = int64(600)
+	pathConfigDefaultProjID = "default_project_id"
+	pathConfigAllowedTeams  = "allowed_teams"
+	pathConfigClientType    = "client_type"
+	pathConfigMockLatency   = "mock_latency"
+	pathConfigMockCreateErr = "mock_create_error_rate"
+	pathConfigMockDeleteErr = "mock_delete_error_rate"
+	pathConfigMockCreateSeq = "mock_create_script"
+	pathConfigMockDeleteSeq = "mock_delete_script"
+	pathConfigBreakerThresh = "circuit_breaker_threshold"
+	pathConfigBreakerCool   = "circuit_breaker_cooldown"
+	pathConfigForce         = "force"
+	clientTypeVercel        = "vercel"
+	clientTypeMock          = "mock"
+	// legacyMockAPIKey selected the mock client before client_type existed.
+	legacyMockAPIKey = "mock"
+	defaultMaxTTL    = int64(600)
 
 	pathConfigHelpDescription = `
 Configuration path used to set the API key that the plugin uses to communicate with the Vercel API.
 Read operations are not supported. If you want to update the configuration, write it again.
-Delete operation is supported.`
+Previous versions are listed by config/history and can be restored with config/rollback.
+Delete operation is supported. It is refused while tokens issued by the plugin are outstanding,
+unless force is set. After deletion the configuration is kept for revocations only, until the
+leases issued with it have expired, so that they can still be revoked.`
 	pathConfigHelpSynopsis = `
 Configure the Vercel plugin backend.`
 	//nolint:gosec
 	pathConfigAPIKeyDescription = `
-(Required) Vercel API key used to generate new tokens.
-Setting this key to "mock" enables mock service client for development purposes.`
+(Required) Vercel API key used to generate new tokens. Optional when client_type is "mock".`
 	pathConfigBaseURLDescription = `
-(Optional) Base URL for the Vercel API. Used by mock tests mostly.`
+(Optional) Base URL for the Vercel API, without an API version. Each endpoint adds its own
+version, e.g. /v10/projects. A trailing version such as /v3 is stripped. Used by mock tests mostly.`
 	pathConfigMaxTTLDescription = `
 (Optional) Maximum TTL for all the API tokens generated by this plugin. Defaults to 600 seconds.`
 	pathConfigDefaultTeamIDDescription = `
-(Optional) Default Team ID used for all token creation actions.
+(Optional) Default Team ID or slug used for all token creation actions.
 If set, individual tokens cannot override this value per token.`
+	pathConfigDefaultProjIDDescription = `
+(Optional) Default Vercel project ID used when rendering token output formats that
+link a project, such as project_json and dotenv. Can be overridden per token.`
+	pathConfigAllowedTeamsDescription = `
+(Optional) Comma-separated list of team IDs or slugs tokens may be scoped to. Entries may be identity
+templates such as {{identity.entity.metadata.vercel_team}}, resolved for the requesting entity.
+If set, every token must be scoped to a team that matches one of the entries.`
+	pathConfigClientTypeDescription = `
+(Optional) API client used by the plugin. Either "vercel" (default) or "mock".
+The mock client never talks to the Vercel API and is only accepted when the plugin
+process has VAULT_PLUGIN_SECRETS_VERCEL_ALLOW_MOCK=true in its environment.`
+	pathConfigMockLatencyDescription = `
+(Optional) Mock client only. Latency added to every create and delete call, e.g. "250ms".`
+	pathConfigMockCreateErrDescription = `
+(Optional) Mock client only. Probability between 0 and 1 of a token creation failing.`
+	pathConfigMockDeleteErrDescription = `
+(Optional) Mock client only. Probability between 0 and 1 of a token deletion failing.`
+	pathConfigMockCreateSeqDescription = `
+(Optional) Mock client only. HTTP status codes returned by consecutive token creations,
+e.g. "503,503,200". A 2xx code means success. Applied before the error rate.`
+	pathConfigMockDeleteSeqDescription = `
+(Optional) Mock client only. HTTP status codes returned by consecutive token deletions,
+e.g. "429,200". A 2xx code means success. Applied before the error rate.`
+	pathConfigForceDescription = `
+(Optional) Delete the configuration even while leases issued with it are outstanding.
+They are still revoked with the deleted configuration.`
+	pathConfigBreakerThreshDescription = `
+(Optional) Number of consecutive Vercel API failures or timeouts after which requests
+fail fast with an "upstream unavailable" error. Defaults to 5.`
+	pathConfigBreakerCoolDescription = `
+(Optional) How long requests fail fast once the circuit breaker opens, before a single
+probe request is sent to Vercel to check whether it has recovered. Defaults to 30 seconds.`
 )
 
 var (
@@ -44,13 +98,75 @@ var (
 	errWriteConfig          = errors.New("failed to write config to storage")
 	errDeleteConfig         = errors.New("failed to delete config from storage")
 	errInvalidMaxTTL        = errors.New("invalid max_ttl")
+	errInvalidClientType    = errors.New("invalid client_type")
+	errInvalidMockLatency   = errors.New("invalid mock_latency")
+	errInvalidMockErrorRate = errors.New("mock error rates must be between 0 and 1")
+	errMockOptionsNotMock   = errors.New("mock options require client_type to be mock")
+	errLegacyMockAPIKey     = errors.New("api_key=mock no longer selects the mock client, " +
+		"write the config with client_type=mock instead")
+	errInvalidBreakerThresh = errors.New("invalid circuit_breaker_threshold")
+	errInvalidBreakerCool   = errors.New("invalid circuit_breaker_cooldown")
+	errLeasesOutstanding    = errors.New("leases issued with the configuration are outstanding, revoke them " +
+		"or set force=true to delete the configuration anyway")
 )
 
 type backendConfig struct {
-	APIKey        string `json:"api_key"`
-	BaseURL       string `json:"base_url"`
-	MaxTTL        int64  `json:"max_ttl"`
-	DefaultTeamID string `json:"default_team_id"`
+	APIKey        string      `json:"api_key"`
+	BaseURL       string      `json:"base_url"`
+	MaxTTL        int64       `json:"max_ttl"`
+	DefaultTeamID string      `json:"default_team_id"`
+	DefaultProjID string      `json:"default_project_id,omitempty"`
+	AllowedTeams  []string    `json:"allowed_teams,omitempty"`
+	ClientType    string      `json:"client_type,omitempty"`
+	Mock          *mockConfig `json:"mock,omitempty"`
+	// BreakerThreshold and BreakerCooldown override the circuit breaker
+	// defaults when set. BreakerCooldown is in seconds.
+	BreakerThreshold int   `json:"circuit_breaker_threshold,omitempty"`
+	BreakerCooldown  int64 `json:"circuit_breaker_cooldown,omitempty"`
+}
+
+// breakerConfig returns the circuit breaker settings of the configuration.
+func (c *backendConfig) breakerConfig() client.BreakerConfig {
+	return client.BreakerConfig{
+		Threshold: c.BreakerThreshold,
+		Cooldown:  time.Duration(c.BreakerCooldown) * time.Second,
+	}
+}
+
+// migrateBaseURL strips the API version that base URLs used to carry, e.g.
+// "https://api.vercel.com/v3", as each endpoint now declares its own version.
+// It reports whether the base URL was changed.
+func (c *backendConfig) migrateBaseURL() bool {
+	migrated := vercel.TrimAPIVersion(c.BaseURL)
+	if migrated == c.BaseURL {
+		return false
+	}
+
+	c.BaseURL = migrated
+
+	return true
+}
+
+type mockConfig struct {
+	Latency         time.Duration `json:"latency,omitempty"`
+	CreateErrorRate float64       `json:"create_error_rate,omitempty"`
+	DeleteErrorRate float64       `json:"delete_error_rate,omitempty"`
+	CreateScript    []int         `json:"create_script,omitempty"`
+	DeleteScript    []int         `json:"delete_script,omitempty"`
+}
+
+func (m *mockConfig) clientConfig() client.MockConfig {
+	if m == nil {
+		return client.MockConfig{}
+	}
+
+	return client.MockConfig{
+		Latency:         m.Latency,
+		CreateErrorRate: m.CreateErrorRate,
+		DeleteErrorRate: m.DeleteErrorRate,
+		CreateScript:    m.CreateScript,
+		DeleteScript:    m.DeleteScript,
+	}
 }
 
 func (b *backend) pathConfig() []*framework.Path {
@@ -59,17 +175,24 @@ func (b *backend) pathConfig() []*framework.Path {
 			Pattern:         pathPatternConfig,
 			HelpSynopsis:    pathConfigHelpSynopsis,
 			HelpDescription: pathConfigHelpDescription,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+			},
 
 			Fields: map[string]*framework.FieldSchema{
 				pathConfigAPIKey: {
 					Type:        framework.TypeString,
 					Description: pathConfigAPIKeyDescription,
 					Required:    true,
+					DisplayAttrs: &framework.DisplayAttributes{
+						Name:      "API key",
+						Sensitive: true,
+					},
 				},
 				pathConfigBaseURL: {
 					Type:        framework.TypeString,
 					Description: pathConfigBaseURLDescription,
-					Default:     client.DefaultBaseURL,
+					Default:     vercel.DefaultBaseURL,
 				},
 				pathConfigMaxTTL: {
 					Type:        framework.TypeDurationSecond,
@@ -80,17 +203,79 @@ func (b *backend) pathConfig() []*framework.Path {
 					Type:        framework.TypeString,
 					Description: pathConfigDefaultTeamIDDescription,
 				},
+				pathConfigDefaultProjID: {
+					Type:        framework.TypeString,
+					Description: pathConfigDefaultProjIDDescription,
+				},
+				pathConfigAllowedTeams: {
+					Type:        framework.TypeCommaStringSlice,
+					Description: pathConfigAllowedTeamsDescription,
+				},
+				pathConfigClientType: {
+					Type:          framework.TypeString,
+					Description:   pathConfigClientTypeDescription,
+					Default:       clientTypeVercel,
+					AllowedValues: []any{clientTypeVercel, clientTypeMock},
+				},
+				pathConfigMockLatency: {
+					Type:        framework.TypeString,
+					Description: pathConfigMockLatencyDescription,
+				},
+				pathConfigMockCreateErr: {
+					Type:        framework.TypeFloat,
+					Description: pathConfigMockCreateErrDescription,
+				},
+				pathConfigMockDeleteErr: {
+					Type:        framework.TypeFloat,
+					Description: pathConfigMockDeleteErrDescription,
+				},
+				pathConfigMockCreateSeq: {
+					Type:        framework.TypeCommaIntSlice,
+					Description: pathConfigMockCreateSeqDescription,
+				},
+				pathConfigMockDeleteSeq: {
+					Type:        framework.TypeCommaIntSlice,
+					Description: pathConfigMockDeleteSeqDescription,
+				},
+				pathConfigBreakerThresh: {
+					Type:        framework.TypeInt,
+					Description: pathConfigBreakerThreshDescription,
+				},
+				pathConfigBreakerCool: {
+					Type:        framework.TypeDurationSecond,
+					Description: pathConfigBreakerCoolDescription,
+				},
+				pathConfigForce: {
+					Type:        framework.TypeBool,
+					Description: pathConfigForceDescription,
+				},
 			},
 
 			Operations: map[logical.Operation]framework.OperationHandler{
 				logical.UpdateOperation: &framework.PathOperation{
-					Callback: b.pathConfigWrite,
+					Callback:  traced("vercel.config.write", b.pathConfigWrite),
+					Summary:   "Configure the Vercel API client.",
+					Responses: noContentResponses(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "configure",
+					},
 				},
 				logical.CreateOperation: &framework.PathOperation{
-					Callback: b.pathConfigWrite,
+					Callback:  traced("vercel.config.write", b.pathConfigWrite),
+					Summary:   "Configure the Vercel API client.",
+					Responses: noContentResponses(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "configure",
+					},
 				},
 				logical.DeleteOperation: &framework.PathOperation{
-					Callback: b.pathConfigDelete,
+					Callback:  traced("vercel.config.delete", b.pathConfigDelete),
+					Summary:   "Delete the Vercel API client configuration.",
+					Responses: noContentResponses(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "delete",
+						OperationSuffix: "configuration",
+					},
 				},
 			},
 			ExistenceCheck: b.pathConfigExistence(),
@@ -114,6 +299,8 @@ func (b *backend) getConfig(ctx context.Context, storage logical.Storage) (*back
 		return nil, errDecode
 	}
 
+	config.migrateBaseURL()
+
 	return &config, nil
 }
 
@@ -129,6 +316,20 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request,
 		config.DefaultTeamID, _ = v.(string)
 	}
 
+	if v, ok := data.GetOk(pathConfigDefaultProjID); ok {
+		config.DefaultProjID, _ = v.(string)
+	}
+
+	if v, ok := data.GetOk(pathConfigAllowedTeams); ok {
+		config.AllowedTeams, _ = v.([]string)
+
+		for _, t := range config.AllowedTeams {
+			if err := validateTemplate(t); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if v, ok := data.GetOk(pathConfigBaseURL); ok {
 		config.BaseURL, _ = v.(string)
 	}
@@ -143,36 +344,145 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request,
 		config.MaxTTL = int64(v)
 	}
 
-	if config.APIKey == "" {
-		return nil, errMissingAPIKey
+	if v, ok := data.GetOk(pathConfigBreakerThresh); ok {
+		config.BreakerThreshold, _ = v.(int)
+		if config.BreakerThreshold <= 0 {
+			return nil, errInvalidBreakerThresh
+		}
+	}
+
+	if v, ok, err := durationSeconds(data, pathConfigBreakerCool); err != nil {
+		return nil, errInvalidBreakerCool
+	} else if ok {
+		if v <= 0 {
+			return nil, errInvalidBreakerCool
+		}
+
+		config.BreakerCooldown = int64(v)
+	}
+
+	if v, ok := data.GetOk(pathConfigClientType); ok {
+		config.ClientType, _ = v.(string)
+	}
+
+	if config.ClientType == "" {
+		config.ClientType = clientTypeVercel
+	}
+
+	mock, err := mockConfigFromData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.ClientType {
+	case clientTypeVercel:
+		if mock != nil {
+			return nil, errMockOptionsNotMock
+		}
+
+		if config.APIKey == "" {
+			return nil, errMissingAPIKey
+		}
+
+		if config.APIKey == legacyMockAPIKey {
+			return nil, errLegacyMockAPIKey
+		}
+	case clientTypeMock:
+		if !b.allowMockClient {
+			return nil, errMockClientDisabled
+		}
+
+		config.Mock = mock
+	default:
+		return nil, errInvalidClientType
 	}
 
 	if config.BaseURL == "" {
-		config.BaseURL = client.DefaultBaseURL
+		config.BaseURL = vercel.DefaultBaseURL
 	}
 
 	if config.MaxTTL == 0 {
 		config.MaxTTL = defaultMaxTTL
 	}
 
-	e, err := logical.StorageEntryJSON(pathPatternConfig, config)
+	warning, err := b.resolveDefaultTeam(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, _, err := b.putConfig(ctx, req, config, 0)
 	if err != nil {
 		return nil, err
 	}
 
+	if warning != "" {
+		resp.AddWarning(warning)
+	}
+
+	b.Logger().Info("config initialised", "client_type", config.ClientType)
+
+	return resp, nil
+}
+
+// putConfig stores the configuration and records it as a new version in the
+// config history. A replaced configuration with another credential is kept to
+// revoke the leases issued with it. A failure to record the version is
+// returned as a warning, as the configuration itself has been written.
+func (b *backend) putConfig(ctx context.Context, req *logical.Request, config *backendConfig,
+	rolledBackFrom int) (*logical.Response, int, error) {
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
+	legacyBaseURL := config.BaseURL
+	migrated := config.migrateBaseURL()
+
+	prev, err := b.getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if prev != nil && prev.credentialID() != config.credentialID() {
+		if _, err = b.putRevokeOnlyConfig(ctx, req.Storage, prev); err != nil {
+			b.Logger().Error("failed to write revoke-only config to storage", "error", err)
+
+			return nil, 0, errWriteConfig
+		}
+	}
+
+	e, err := logical.StorageEntryJSON(pathPatternConfig, config)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	if err = req.Storage.Put(ctx, e); err != nil {
 		b.Logger().Error("failed to write config to storage", "error", err)
 
-		return nil, errWriteConfig
+		return nil, 0, errWriteConfig
+	}
+
+	b.reset()
+
+	resp := &logical.Response{}
+
+	if migrated {
+		resp.AddWarning(fmt.Sprintf("base_url %q carries an API version, stored as %q as each endpoint "+
+			"now selects its own version", legacyBaseURL, config.BaseURL))
 	}
 
-	b.Logger().Info("config initialised")
+	version, err := b.recordConfigVersion(ctx, req, config, rolledBackFrom)
+	if err != nil {
+		b.Logger().Error("failed to record config version", "error", err)
+		resp.AddWarning("configuration written, but it could not be recorded in config/history")
+	}
 
-	return &logical.Response{}, nil
+	return resp, version, nil
 }
 
 func (b *backend) pathConfigDelete(ctx context.Context, req *logical.Request,
-	_ *framework.FieldData) (*logical.Response, error) {
+	data *framework.FieldData) (*logical.Response, error) {
+	b.configLock.Lock()
+	defer b.configLock.Unlock()
+
 	cfg, err := b.getConfig(ctx, req.Storage)
 	if err != nil {
 		return nil, err
@@ -182,13 +492,49 @@ func (b *backend) pathConfigDelete(ctx context.Context, req *logical.Request,
 		return nil, errBackendNotConfigured
 	}
 
+	outstanding, err := b.outstandingLeases(ctx, req.Storage, cfg.credentialID())
+	if err != nil {
+		b.Logger().Error("failed to count outstanding leases", "error", err)
+
+		return nil, errDeleteConfig
+	}
+
+	force, _ := data.Get(pathConfigForce).(bool)
+	if outstanding > 0 && !force {
+		return nil, fmt.Errorf("%w: %d outstanding", errLeasesOutstanding, outstanding)
+	}
+
+	// Leases issued with the configuration are revoked with it until they
+	// have expired, whether they are tracked or not.
+	revokeOnly, err := b.putRevokeOnlyConfig(ctx, req.Storage, cfg)
+	if err != nil {
+		b.Logger().Error("failed to write revoke-only config to storage", "error", err)
+
+		return nil, errDeleteConfig
+	}
+
 	if err = req.Storage.Delete(ctx, pathPatternConfig); err != nil {
 		b.Logger().Error("failed to delete config from storage", "error", err)
 
 		return nil, errDeleteConfig
 	}
 
-	return &logical.Response{}, nil
+	b.reset()
+
+	resp := &logical.Response{}
+
+	if outstanding > 0 {
+		b.Logger().Warn("config deleted with outstanding leases", "outstanding", outstanding)
+		resp.AddWarning(fmt.Sprintf("%d leases are outstanding, the deleted configuration is kept to revoke "+
+			"them until %s", outstanding, revokeOnly.ExpiresAt.Format(time.RFC3339)))
+	}
+
+	if _, err = b.recordConfigDeletion(ctx, req); err != nil {
+		b.Logger().Error("failed to record config deletion", "error", err)
+		resp.AddWarning("configuration deleted, but the deletion could not be recorded in config/history")
+	}
+
+	return resp, nil
 }
 
 func (b *backend) pathConfigExistence() framework.ExistenceFunc {
@@ -202,6 +548,14 @@ func (b *backend) pathConfigExistence() framework.ExistenceFunc {
 	}
 }
 
+func noContentResponses() map[int][]framework.Response {
+	return map[int][]framework.Response{
+		http.StatusNoContent: {{
+			Description: http.StatusText(http.StatusNoContent),
+		}},
+	}
+}
+
 func durationSeconds(data *framework.FieldData, key string) (int, bool, error) {
 	if _, ok := data.Raw[key]; !ok {
 		return 0, false, nil
@@ -220,3 +574,51 @@ func durationSeconds(data *framework.FieldData, key string) (int, bool, error) {
 
 	return seconds, true, nil
 }
+
+// mockConfigFromData collects the mock_* fields. It returns nil when none of them are set.
+func mockConfigFromData(data *framework.FieldData) (*mockConfig, error) {
+	m := &mockConfig{}
+	set := false
+
+	if v, ok := data.GetOk(pathConfigMockLatency); ok {
+		s, _ := v.(string)
+
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return nil, errInvalidMockLatency
+		}
+
+		m.Latency = d
+		set = true
+	}
+
+	for key, rate := range map[string]*float64{
+		pathConfigMockCreateErr: &m.CreateErrorRate,
+		pathConfigMockDeleteErr: &m.DeleteErrorRate,
+	} {
+		if v, ok := data.GetOk(key); ok {
+			*rate, _ = v.(float64)
+			if *rate < 0 || *rate > 1 {
+				return nil, errInvalidMockErrorRate
+			}
+
+			set = true
+		}
+	}
+
+	if v, ok := data.GetOk(pathConfigMockCreateSeq); ok {
+		m.CreateScript, _ = v.([]int)
+		set = true
+	}
+
+	if v, ok := data.GetOk(pathConfigMockDeleteSeq); ok {
+		m.DeleteScript, _ = v.([]int)
+		set = true
+	}
+
+	if !set {
+		return nil, nil
+	}
+
+	return m, nil
+}
@@ -0,0 +1,384 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	pathPatternConfigHistory  = "config/history"
+	pathPatternConfigRollback = "config/rollback"
+	pathConfigVersion         = "version"
+	pathConfigVersions        = "versions"
+	pathConfigRestored        = "restored_version"
+	configHistoryPrefix       = "config-history/"
+	// configHistorySize is the number of configuration versions kept in storage.
+	configHistorySize = 10
+	// redactedValue replaces secrets in configuration history.
+	redactedValue = "<redacted>"
+
+	pathConfigHistoryHelpSynopsis = `
+List previous versions of the plugin configuration.`
+	pathConfigHistoryHelpDescription = `
+Every configuration write and deletion is kept as a version, together with when it was written
+and by whom. The last 10 versions are kept, newest first. API keys are never kept in the history.
+Supports only read operations.`
+	pathConfigRollbackHelpSynopsis = `
+Restore a previous version of the plugin configuration.`
+	pathConfigRollbackHelpDescription = `
+Writes the configuration of a version listed by config/history as a new version.
+The API key is not part of the history, so the current API key is kept unless
+api_key is given. Supports only update operations.`
+	pathConfigVersionDescription = `
+Configuration version to restore, as listed by config/history.`
+	//nolint:gosec
+	pathConfigRollbackAPIKeyDescription = `
+(Optional) Vercel API key of the restored configuration. Defaults to the current API key.`
+	pathConfigVersionsDescription = `
+Configuration versions, newest first. API keys are redacted.`
+	pathConfigNewVersionDescription = `
+Version the restored configuration was written as.`
+	pathConfigRestoredDescription = `
+Version that was restored.`
+)
+
+var (
+	errConfigVersionNotFound = errors.New("configuration version not found")
+	errConfigVersionDeleted  = errors.New("configuration version records a deletion and cannot be restored")
+	errReadConfigHistory     = errors.New("failed to read config history from storage")
+)
+
+// configVersion is a configuration write or deletion kept in the history.
+// The API key is removed before the version is stored.
+type configVersion struct {
+	Version        int           `json:"version"`
+	Config         backendConfig `json:"config"`
+	APIKeySet      bool          `json:"api_key_set,omitempty"`
+	WrittenAt      time.Time     `json:"written_at"`
+	EntityID       string        `json:"entity_id,omitempty"`
+	DisplayName    string        `json:"display_name,omitempty"`
+	RolledBackFrom int           `json:"rolled_back_from,omitempty"`
+	// Deleted marks the deletion of the configuration. Config is empty.
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+func configVersionKey(version int) string {
+	return configHistoryPrefix + strconv.Itoa(version)
+}
+
+func (b *backend) pathConfigHistory() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         pathPatternConfigHistory,
+			HelpSynopsis:    pathConfigHistoryHelpSynopsis,
+			HelpDescription: pathConfigHistoryHelpDescription,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "configuration-history",
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathConfigHistoryRead,
+					Summary:  "List previous versions of the plugin configuration.",
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: http.StatusText(http.StatusOK),
+							Fields: map[string]*framework.FieldSchema{
+								pathConfigVersions: {
+									Type:        framework.TypeSlice,
+									Description: pathConfigVersionsDescription,
+									Required:    true,
+								},
+							},
+						}},
+					},
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "read",
+					},
+				},
+			},
+		},
+		{
+			Pattern:         pathPatternConfigRollback,
+			HelpSynopsis:    pathConfigRollbackHelpSynopsis,
+			HelpDescription: pathConfigRollbackHelpDescription,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "configuration",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				pathConfigVersion: {
+					Type:        framework.TypeInt,
+					Description: pathConfigVersionDescription,
+					Required:    true,
+				},
+				pathConfigAPIKey: {
+					Type:        framework.TypeString,
+					Description: pathConfigRollbackAPIKeyDescription,
+					DisplayAttrs: &framework.DisplayAttributes{
+						Name:      "API key",
+						Sensitive: true,
+					},
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: traced("vercel.config.rollback", b.pathConfigRollback),
+					Summary:  "Restore a previous version of the plugin configuration.",
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: http.StatusText(http.StatusOK),
+							Fields: map[string]*framework.FieldSchema{
+								pathConfigVersion: {
+									Type:        framework.TypeInt,
+									Description: pathConfigNewVersionDescription,
+									Required:    true,
+								},
+								pathConfigRestored: {
+									Type:        framework.TypeInt,
+									Description: pathConfigRestoredDescription,
+									Required:    true,
+								},
+							},
+						}},
+					},
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "roll-back",
+					},
+				},
+			},
+		},
+	}
+}
+
+// listConfigVersions returns the stored configuration versions, newest first.
+func (b *backend) listConfigVersions(ctx context.Context, s logical.Storage) ([]*configVersion, error) {
+	keys, err := s.List(ctx, configHistoryPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*configVersion, 0, len(keys))
+
+	for _, k := range keys {
+		e, err := s.Get(ctx, configHistoryPrefix+k)
+		if err != nil {
+			return nil, err
+		}
+
+		if e == nil {
+			continue
+		}
+
+		var v configVersion
+		if err = e.DecodeJSON(&v); err != nil {
+			return nil, err
+		}
+
+		out = append(out, &v)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Version > out[j].Version
+	})
+
+	return out, nil
+}
+
+// recordConfigVersion adds cfg to the history as a new version. Callers hold
+// configLock, so that concurrent writes do not get the same version.
+func (b *backend) recordConfigVersion(ctx context.Context, req *logical.Request, cfg *backendConfig,
+	rolledBackFrom int) (int, error) {
+	v := &configVersion{
+		Config:         *cfg,
+		APIKeySet:      cfg.APIKey != "",
+		RolledBackFrom: rolledBackFrom,
+	}
+	v.Config.APIKey = ""
+
+	return b.addConfigVersion(ctx, req, v)
+}
+
+// recordConfigDeletion adds the deletion of the configuration to the history
+// as a new version. Callers hold configLock.
+func (b *backend) recordConfigDeletion(ctx context.Context, req *logical.Request) (int, error) {
+	return b.addConfigVersion(ctx, req, &configVersion{Deleted: true})
+}
+
+// addConfigVersion stores v as the next version, written now by the requester,
+// and removes the versions beyond configHistorySize.
+func (b *backend) addConfigVersion(ctx context.Context, req *logical.Request, v *configVersion) (int, error) {
+	versions, err := b.listConfigVersions(ctx, req.Storage)
+	if err != nil {
+		return 0, err
+	}
+
+	v.Version = 1
+	v.WrittenAt = time.Now().UTC()
+	v.EntityID = req.EntityID
+	v.DisplayName = req.DisplayName
+
+	if len(versions) > 0 {
+		v.Version = versions[0].Version + 1
+	}
+
+	e, err := logical.StorageEntryJSON(configVersionKey(v.Version), v)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = req.Storage.Put(ctx, e); err != nil {
+		return 0, err
+	}
+
+	for i := configHistorySize - 1; i < len(versions); i++ {
+		if err = req.Storage.Delete(ctx, configVersionKey(versions[i].Version)); err != nil {
+			return 0, err
+		}
+	}
+
+	return v.Version, nil
+}
+
+func (b *backend) pathConfigHistoryRead(ctx context.Context, req *logical.Request,
+	_ *framework.FieldData) (*logical.Response, error) {
+	versions, err := b.listConfigVersions(ctx, req.Storage)
+	if err != nil {
+		b.Logger().Error("failed to read config history", "error", err)
+
+		return nil, errReadConfigHistory
+	}
+
+	out := make([]map[string]any, 0, len(versions))
+
+	for _, v := range versions {
+		e := map[string]any{
+			pathConfigVersion: v.Version,
+			"written_at":      v.WrittenAt.Format(time.RFC3339),
+		}
+
+		if v.Deleted {
+			e["deleted"] = true
+		} else if e["config"], err = v.view(); err != nil {
+			b.Logger().Error("failed to read config history", "version", v.Version, "error", err)
+
+			return nil, errReadConfigHistory
+		}
+
+		if v.EntityID != "" {
+			e["entity_id"] = v.EntityID
+		}
+
+		if v.DisplayName != "" {
+			e["display_name"] = v.DisplayName
+		}
+
+		if v.RolledBackFrom != 0 {
+			e["rolled_back_from"] = v.RolledBackFrom
+		}
+
+		out = append(out, e)
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			pathConfigVersions: out,
+		},
+	}, nil
+}
+
+// view returns the configuration of the version as stored, so that fields
+// added later are listed as well.
+func (v *configVersion) view() (map[string]any, error) {
+	var m map[string]any
+
+	bs, err := json.Marshal(v.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(bs, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(m, pathConfigAPIKey)
+
+	if v.APIKeySet {
+		m[pathConfigAPIKey] = redactedValue
+	}
+
+	return m, nil
+}
+
+func (b *backend) pathConfigRollback(ctx context.Context, req *logical.Request,
+	data *framework.FieldData) (*logical.Response, error) {
+	version, _ := data.Get(pathConfigVersion).(int)
+
+	e, err := req.Storage.Get(ctx, configVersionKey(version))
+	if err != nil {
+		return nil, errReadConfigHistory
+	}
+
+	if e == nil {
+		return nil, fmt.Errorf("%w: %d", errConfigVersionNotFound, version)
+	}
+
+	var v configVersion
+	if err = e.DecodeJSON(&v); err != nil {
+		return nil, errDecode
+	}
+
+	if v.Deleted {
+		return nil, fmt.Errorf("%w: %d", errConfigVersionDeleted, version)
+	}
+
+	cfg := v.Config
+
+	if key, ok := data.GetOk(pathConfigAPIKey); ok {
+		cfg.APIKey, _ = key.(string)
+	} else {
+		current, err := b.getConfig(ctx, req.Storage)
+		if err != nil {
+			return nil, err
+		}
+
+		if current != nil {
+			cfg.APIKey = current.APIKey
+		}
+	}
+
+	switch cfg.ClientType {
+	case clientTypeMock:
+		if !b.allowMockClient {
+			return nil, errMockClientDisabled
+		}
+	default:
+		if cfg.APIKey == "" {
+			return nil, errMissingAPIKey
+		}
+	}
+
+	resp, newVersion, err := b.putConfig(ctx, req, &cfg, version)
+	if err != nil {
+		return nil, err
+	}
+
+	b.Logger().Warn("config rolled back", "version", version, "new_version", newVersion)
+
+	resp.Data = map[string]any{
+		pathConfigVersion:  newVersion,
+		pathConfigRestored: version,
+	}
+
+	return resp, nil
+}
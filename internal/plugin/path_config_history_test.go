@@ -0,0 +1,264 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigHistory_Rollback(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	for _, w := range []struct {
+		by   string
+		data map[string]any
+	}{
+		{by: "alice", data: map[string]any{"api_key": "key-1", "max_ttl": 600, "default_team_id": "team_a"}},
+		{by: "bob", data: map[string]any{"api_key": "key-2", "max_ttl": 60}},
+	} {
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:     storage,
+			Operation:   logical.CreateOperation,
+			Path:        pathPatternConfig,
+			Data:        w.data,
+			DisplayName: w.by,
+			EntityID:    "entity-" + w.by,
+		})
+		require.NoError(t, err)
+	}
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternConfigHistory,
+	})
+	require.NoError(t, err)
+
+	versions, _ := r.Data["versions"].([]map[string]any)
+	require.Len(t, versions, 2)
+	require.Equal(t, 2, versions[0]["version"])
+	require.Equal(t, "bob", versions[0]["display_name"])
+	require.Equal(t, "entity-bob", versions[0]["entity_id"])
+	require.NotEmpty(t, versions[0]["written_at"])
+	require.Equal(t, 1, versions[1]["version"])
+
+	cfg, _ := versions[1]["config"].(map[string]any)
+	require.Equal(t, redactedValue, cfg["api_key"])
+	require.InDelta(t, 600, cfg["max_ttl"], 0)
+	require.Equal(t, "team_a", cfg["default_team_id"])
+
+	// API keys never reach the history entries.
+	keys, err := storage.List(ctx, configHistoryPrefix)
+	require.NoError(t, err)
+
+	for _, k := range keys {
+		e, err := storage.Get(ctx, configHistoryPrefix+k)
+		require.NoError(t, err)
+		require.NotContains(t, string(e.Value), "key-")
+	}
+
+	rollback := func(data map[string]any) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Storage:     storage,
+			Operation:   logical.UpdateOperation,
+			Path:        pathPatternConfigRollback,
+			Data:        data,
+			DisplayName: "carol",
+		})
+	}
+
+	r, err = rollback(map[string]any{"version": 1})
+	require.NoError(t, err)
+	require.Equal(t, 3, r.Data["version"])
+	require.Equal(t, 1, r.Data["restored_version"])
+
+	current, err := b.getConfig(ctx, storage)
+	require.NoError(t, err)
+	require.Equal(t, "key-2", current.APIKey)
+	require.Equal(t, int64(600), current.MaxTTL)
+	require.Equal(t, "team_a", current.DefaultTeamID)
+
+	_, err = rollback(map[string]any{"version": 2, "api_key": "key-3"})
+	require.NoError(t, err)
+
+	current, err = b.getConfig(ctx, storage)
+	require.NoError(t, err)
+	require.Equal(t, "key-3", current.APIKey)
+	require.Equal(t, int64(60), current.MaxTTL)
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternConfigHistory,
+	})
+	require.NoError(t, err)
+
+	versions, _ = r.Data["versions"].([]map[string]any)
+	require.Len(t, versions, 4)
+	require.Equal(t, 2, versions[0]["rolled_back_from"])
+	require.Equal(t, "carol", versions[0]["display_name"])
+
+	_, err = rollback(map[string]any{"version": 99})
+	require.ErrorIs(t, err, errConfigVersionNotFound)
+
+	// Without a configuration there is no API key to keep.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:     storage,
+		Operation:   logical.DeleteOperation,
+		Path:        pathPatternConfig,
+		DisplayName: "dave",
+	})
+	require.NoError(t, err)
+
+	// The deletion is recorded, but cannot be restored.
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternConfigHistory,
+	})
+	require.NoError(t, err)
+
+	versions, _ = r.Data["versions"].([]map[string]any)
+	require.Len(t, versions, 5)
+	require.Equal(t, 5, versions[0]["version"])
+	require.Equal(t, true, versions[0]["deleted"])
+	require.Equal(t, "dave", versions[0]["display_name"])
+	require.NotContains(t, versions[0], "config")
+
+	_, err = rollback(map[string]any{"version": 5})
+	require.ErrorIs(t, err, errConfigVersionDeleted)
+
+	_, err = rollback(map[string]any{"version": 1})
+	require.ErrorIs(t, err, errMissingAPIKey)
+
+	_, err = rollback(map[string]any{"version": 1, "api_key": "key-4"})
+	require.NoError(t, err)
+}
+
+func TestConfigHistory_Prune(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	for i := 1; i <= configHistorySize+2; i++ {
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.CreateOperation,
+			Path:      pathPatternConfig,
+			Data:      map[string]any{"client_type": "mock", "max_ttl": i * 60},
+		})
+		require.NoError(t, err)
+	}
+
+	versions, err := b.listConfigVersions(ctx, storage)
+	require.NoError(t, err)
+	require.Len(t, versions, configHistorySize)
+	require.Equal(t, configHistorySize+2, versions[0].Version)
+	require.Equal(t, 3, versions[len(versions)-1].Version)
+	require.Equal(t, int64(180), versions[len(versions)-1].Config.MaxTTL)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternConfigRollback,
+		Data:      map[string]any{"version": 1},
+	})
+	require.ErrorIs(t, err, errConfigVersionNotFound)
+}
+
+func TestConfigHistory_ConcurrentWrites(t *testing.T) {
+	t.Parallel()
+
+	const writers = 8
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, writers)
+
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			_, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.CreateOperation,
+				Path:      pathPatternConfig,
+				Data:      map[string]any{"api_key": fmt.Sprintf("key-%d", i)},
+			})
+			errs <- err
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	versions, err := b.listConfigVersions(ctx, storage)
+	require.NoError(t, err)
+	require.Len(t, versions, writers)
+	require.Equal(t, writers, versions[0].Version)
+}
+
+func TestConfigHistory_ViewListsAllFields(t *testing.T) {
+	t.Parallel()
+
+	v := &configVersion{
+		APIKeySet: true,
+		Config: backendConfig{
+			APIKey:           "secret",
+			BaseURL:          "http://example.com",
+			MaxTTL:           60,
+			DefaultTeamID:    "team_a",
+			DefaultProjID:    "prj_a",
+			AllowedTeams:     []string{"team_a"},
+			ClientType:       clientTypeMock,
+			BreakerThreshold: 3,
+			BreakerCooldown:  120,
+			Mock: &mockConfig{
+				Latency:      250 * time.Millisecond,
+				CreateScript: []int{503},
+			},
+		},
+	}
+
+	view, err := v.view()
+	require.NoError(t, err)
+
+	// Every stored config field is listed, so new fields show up as well.
+	typ := reflect.TypeOf(backendConfig{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		require.Contains(t, view, name)
+	}
+
+	require.Equal(t, redactedValue, view[pathConfigAPIKey])
+	require.InDelta(t, 60, view[pathConfigMaxTTL], 0)
+	require.Equal(t, map[string]any{
+		"latency":       float64(250 * time.Millisecond),
+		"create_script": []any{float64(503)},
+	}, view["mock"])
+
+	v.APIKeySet = false
+	view, err = v.view()
+	require.NoError(t, err)
+	require.NotContains(t, view, pathConfigAPIKey)
+}
@@ -3,10 +3,11 @@ package plugin
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/stretchr/testify/require"
-	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/client"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
 )
 
 func TestConfig_Get(t *testing.T) {
@@ -23,6 +24,10 @@ func TestConfig_Get(t *testing.T) {
 			input: []byte(`{"api_key": "foo"}`),
 			cfg:   &backendConfig{APIKey: "foo"},
 		},
+		"legacy base url": {
+			input: []byte(`{"api_key": "foo", "base_url": "https://api.vercel.com/v3"}`),
+			cfg:   &backendConfig{APIKey: "foo", BaseURL: vercel.DefaultBaseURL},
+		},
 		"invalid config json": {
 			input:    []byte(`lorem ipsum`),
 			cfg:      &backendConfig{},
@@ -112,11 +117,12 @@ func TestConfig_Write(t *testing.T) {
 	t.Parallel()
 
 	cases := map[string]struct {
-		disabledOps []logical.Operation
-		data        map[string]any
-		expError    string
-		expRespErr  bool
-		expConfig   *backendConfig
+		disabledOps  []logical.Operation
+		mockDisabled bool
+		data         map[string]any
+		expError     string
+		expRespErr   bool
+		expConfig    *backendConfig
 	}{
 		"write configuration with empty data": {
 			data:     map[string]any{},
@@ -127,21 +133,23 @@ func TestConfig_Write(t *testing.T) {
 				"api_key": "foo",
 			},
 			expConfig: &backendConfig{
-				APIKey:  "foo",
-				BaseURL: client.DefaultBaseURL,
-				MaxTTL:  defaultMaxTTL,
+				APIKey:     "foo",
+				BaseURL:    vercel.DefaultBaseURL,
+				MaxTTL:     defaultMaxTTL,
+				ClientType: clientTypeVercel,
 			},
 		},
 		"write configuration with valid team data": {
 			data: map[string]any{
 				"api_key":         "foo",
-				"default_team_id": "bar",
+				"default_team_id": "team_bar",
 			},
 			expConfig: &backendConfig{
 				APIKey:        "foo",
-				BaseURL:       client.DefaultBaseURL,
+				BaseURL:       vercel.DefaultBaseURL,
 				MaxTTL:        defaultMaxTTL,
-				DefaultTeamID: "bar",
+				DefaultTeamID: "team_bar",
+				ClientType:    clientTypeVercel,
 			},
 		},
 		"write configuration with custom url and ttl": {
@@ -149,14 +157,21 @@ func TestConfig_Write(t *testing.T) {
 				"api_key":         "foo",
 				"base_url":        "http://baseurl",
 				"max_ttl":         10,
-				"default_team_id": "bar",
+				"default_team_id": "team_bar",
 			},
 			expConfig: &backendConfig{
 				APIKey:        "foo",
 				BaseURL:       "http://baseurl",
 				MaxTTL:        10,
-				DefaultTeamID: "bar",
+				DefaultTeamID: "team_bar",
+				ClientType:    clientTypeVercel,
+			},
+		},
+		"write configuration with legacy mock api key": {
+			data: map[string]any{
+				"api_key": "mock",
 			},
+			expError: errLegacyMockAPIKey.Error(),
 		},
 		"write configuration with zero max ttl": {
 			data: map[string]any{
@@ -172,6 +187,88 @@ func TestConfig_Write(t *testing.T) {
 			},
 			expRespErr: true,
 		},
+		"write configuration with mock client": {
+			data: map[string]any{
+				"client_type":            "mock",
+				"mock_latency":           "250ms",
+				"mock_create_error_rate": 0.5,
+				"mock_delete_script":     "429,200",
+			},
+			expConfig: &backendConfig{
+				BaseURL:    vercel.DefaultBaseURL,
+				MaxTTL:     defaultMaxTTL,
+				ClientType: clientTypeMock,
+				Mock: &mockConfig{
+					Latency:         250 * time.Millisecond,
+					CreateErrorRate: 0.5,
+					DeleteScript:    []int{429, 200},
+				},
+			},
+		},
+		"write configuration with mock client disabled": {
+			mockDisabled: true,
+			data: map[string]any{
+				"client_type": "mock",
+			},
+			expError: errMockClientDisabled.Error(),
+		},
+		"write configuration with mock options for vercel client": {
+			data: map[string]any{
+				"api_key":                "foo",
+				"mock_create_error_rate": 1,
+			},
+			expError: "mock options require client_type to be mock",
+		},
+		"write configuration with invalid client type": {
+			data: map[string]any{
+				"api_key":     "foo",
+				"client_type": "bar",
+			},
+			expError: "invalid client_type",
+		},
+		"write configuration with invalid mock error rate": {
+			data: map[string]any{
+				"client_type":            "mock",
+				"mock_delete_error_rate": 1.5,
+			},
+			expError: "mock error rates must be between 0 and 1",
+		},
+		"write configuration with invalid mock latency": {
+			data: map[string]any{
+				"client_type":  "mock",
+				"mock_latency": "soon",
+			},
+			expError: "invalid mock_latency",
+		},
+		"write configuration with circuit breaker": {
+			data: map[string]any{
+				"api_key":                   "foo",
+				"circuit_breaker_threshold": 3,
+				"circuit_breaker_cooldown":  "2m",
+			},
+			expConfig: &backendConfig{
+				APIKey:           "foo",
+				BaseURL:          vercel.DefaultBaseURL,
+				MaxTTL:           defaultMaxTTL,
+				ClientType:       clientTypeVercel,
+				BreakerThreshold: 3,
+				BreakerCooldown:  120,
+			},
+		},
+		"write configuration with zero circuit breaker threshold": {
+			data: map[string]any{
+				"api_key":                   "foo",
+				"circuit_breaker_threshold": 0,
+			},
+			expError: "invalid circuit_breaker_threshold",
+		},
+		"write configuration with zero circuit breaker cooldown": {
+			data: map[string]any{
+				"api_key":                  "foo",
+				"circuit_breaker_cooldown": 0,
+			},
+			expError: "invalid circuit_breaker_cooldown",
+		},
 		"write configuration with storage fail": {
 			disabledOps: []logical.Operation{
 				logical.CreateOperation,
@@ -190,6 +287,7 @@ func TestConfig_Write(t *testing.T) {
 
 			ctx := context.Background()
 			b, storage := newTestBackend(t, tc.disabledOps)
+			b.allowMockClient = !tc.mockDisabled
 
 			res, err := b.HandleRequest(ctx, &logical.Request{
 				Storage:   storage,
@@ -216,6 +314,51 @@ func TestConfig_Write(t *testing.T) {
 	}
 }
 
+func TestConfig_WriteLegacyBaseURL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"api_key":  "foo",
+			"base_url": "https://api.vercel.com/v3/",
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, r.Warnings, 1)
+	require.Contains(t, r.Warnings[0], "carries an API version")
+
+	e, err := storage.Get(ctx, pathPatternConfig)
+	require.NoError(t, err)
+
+	var cfg backendConfig
+
+	require.NoError(t, e.DecodeJSON(&cfg))
+	require.Equal(t, vercel.DefaultBaseURL, cfg.BaseURL)
+
+	// A base URL without a version is stored as is.
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"api_key":  "foo",
+			"base_url": "https://vercel.example.com/proxy",
+		},
+	})
+	require.NoError(t, err)
+	require.Empty(t, r.Warnings)
+
+	got, err := b.getConfig(ctx, storage)
+	require.NoError(t, err)
+	require.Equal(t, "https://vercel.example.com/proxy", got.BaseURL)
+}
+
 func TestConfig_Existence(t *testing.T) {
 	t.Parallel()
 
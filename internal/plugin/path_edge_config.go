@@ -0,0 +1,287 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+const (
+	// #nosec G101
+	edgeConfigTokenSecretType = "vercel_edge_config_token"
+	pathEdgeConfigID          = "edge_config_id"
+	pathEdgeConfigTeamID      = "team_id"
+	pathEdgeConfigToken       = "token"
+	pathEdgeConfigTokenID     = "token_id"
+	//nolint:gosec
+	pathEdgeConfigConnectionString = "connection_string"
+	// edgeConfigBaseURL is where Edge Config stores are read from with a read token.
+	edgeConfigBaseURL           = "https://edge-config.vercel.com/"
+	pathEdgeConfigIDDescription = `
+Edge Config store ID.`
+	pathEdgeConfigTeamIDDescription = `
+(Optional) Team ID or slug owning the Edge Config store. Defaults to default_team_id from configuration.
+May be an identity template and is subject to allowed_teams.`
+	//nolint:gosec
+	pathEdgeConfigTTLDescription = `
+(Optional) TTL for the generated read token. Less than or equal to the maximum TTL set in configuration.
+Defaults to maximum TTL.`
+	//nolint:gosec
+	pathEdgeConfigHelpSynopsis = `
+Generate a read token for a Vercel Edge Config store.`
+	//nolint:gosec
+	pathEdgeConfigHelpDescription = `
+Creates a read token for a Vercel Edge Config store using the configured API key.
+The token is deleted when the lease is revoked or expires.
+Edge Config tokens do not expire on Vercel, so they are only cleaned up through lease revocation.`
+	//nolint:gosec
+	pathEdgeConfigTokenResponseDescription = `
+Generated Edge Config read token.`
+	//nolint:gosec
+	pathEdgeConfigTokenIDResponseDescription = `
+ID of the generated read token.`
+	//nolint:gosec
+	pathEdgeConfigConnectionStringResponseDescription = `
+Connection string for the Edge Config SDK, usually set as the EDGE_CONFIG environment variable.`
+	pathEdgeConfigIDResponseDescription = `
+Edge Config store the read token belongs to.`
+	pathEdgeConfigTeamIDResponseDescription = `
+Team owning the Edge Config store. Empty for stores under a personal account.`
+	// #nosec G101
+	secretEdgeConfigTokenDescription = `
+Edge Config read token, used for revocation.`
+)
+
+var (
+	errCreateEdgeConfigToken = errors.New("failed to create edge config token")
+	errRevokeEdgeConfigToken = errors.New("failed to revoke edge config token")
+)
+
+func (b *backend) pathEdgeConfig() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         "edge-config/" + framework.GenericNameRegex(pathEdgeConfigID) + "/token",
+			HelpSynopsis:    pathEdgeConfigHelpSynopsis,
+			HelpDescription: pathEdgeConfigHelpDescription,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "edge-config-token",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				pathEdgeConfigID: {
+					Type:        framework.TypeString,
+					Description: pathEdgeConfigIDDescription,
+					Required:    true,
+				},
+				pathEdgeConfigTeamID: {
+					Type:        framework.TypeString,
+					Description: pathEdgeConfigTeamIDDescription,
+				},
+				pathTokenTTL: {
+					Type:        framework.TypeDurationSecond,
+					Description: pathEdgeConfigTTLDescription,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback:  traced("vercel.edge_config_token.create", b.pathEdgeConfigTokenWrite),
+					Summary:   "Generate a read token for a Vercel Edge Config store.",
+					Responses: edgeConfigTokenResponses(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "generate",
+					},
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback:  traced("vercel.edge_config_token.create", b.pathEdgeConfigTokenWrite),
+					Summary:   "Generate a read token for a Vercel Edge Config store.",
+					Responses: edgeConfigTokenResponses(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "generate",
+						OperationSuffix: "edge-config-token-with-parameters",
+					},
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) edgeConfigTokenSecret() *framework.Secret {
+	return &framework.Secret{
+		Type: edgeConfigTokenSecretType,
+		Fields: map[string]*framework.FieldSchema{
+			pathEdgeConfigToken: {
+				Type:        framework.TypeString,
+				Description: secretEdgeConfigTokenDescription,
+			},
+		},
+		Revoke: traced("vercel.edge_config_token.revoke", b.revokeEdgeConfigToken),
+	}
+}
+
+func edgeConfigTokenResponses() map[int][]framework.Response {
+	return map[int][]framework.Response{
+		http.StatusOK: {{
+			Description: http.StatusText(http.StatusOK),
+			Fields: map[string]*framework.FieldSchema{
+				pathEdgeConfigToken: {
+					Type:        framework.TypeString,
+					Description: pathEdgeConfigTokenResponseDescription,
+					Required:    true,
+					DisplayAttrs: &framework.DisplayAttributes{
+						Sensitive: true,
+					},
+				},
+				pathEdgeConfigTokenID: {
+					Type:        framework.TypeString,
+					Description: pathEdgeConfigTokenIDResponseDescription,
+					Required:    true,
+				},
+				pathEdgeConfigConnectionString: {
+					Type:        framework.TypeString,
+					Description: pathEdgeConfigConnectionStringResponseDescription,
+					Required:    true,
+					DisplayAttrs: &framework.DisplayAttributes{
+						Sensitive: true,
+					},
+				},
+				pathEdgeConfigID: {
+					Type:        framework.TypeString,
+					Description: pathEdgeConfigIDResponseDescription,
+					Required:    true,
+				},
+				pathEdgeConfigTeamID: {
+					Type:        framework.TypeString,
+					Description: pathEdgeConfigTeamIDResponseDescription,
+					Required:    true,
+				},
+			},
+		}},
+	}
+}
+
+func (b *backend) pathEdgeConfigTokenWrite(ctx context.Context, req *logical.Request,
+	data *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg == nil {
+		return nil, errBackendNotConfigured
+	}
+
+	if err = b.checkLockdown(ctx, req.Storage); err != nil {
+		return nil, err
+	}
+
+	ttl, err := leaseTTL(data, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	edgeConfigID, _ := data.Get(pathEdgeConfigID).(string)
+
+	rawTeamID, teamIDSet := data.GetOk(pathEdgeConfigTeamID)
+	requestedTeamID, _ := rawTeamID.(string)
+
+	teamID, err := b.resolveTeamID(ctx, req, cfg, requestedTeamID, teamIDSet)
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := b.getService(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	label := fmt.Sprintf("%s-%d", keyPrefix, time.Now().UnixNano())
+
+	b.Logger().Info("creating edge config token", "edge_config_id", edgeConfigID, "label", label, "ttl", ttl)
+
+	token, tokenID, err := svc.CreateEdgeConfigToken(ctx, edgeConfigID, teamID, label)
+	if err != nil {
+		b.Logger().Error("failed to create edge config token", "edge_config_id", edgeConfigID, "error", err)
+
+		return nil, vercelError(errCreateEdgeConfigToken, err)
+	}
+
+	resp := &logical.Response{
+		Data: map[string]any{
+			pathEdgeConfigToken:            token,
+			pathEdgeConfigTokenID:          tokenID,
+			pathEdgeConfigConnectionString: edgeConfigConnectionString(edgeConfigID, token),
+			pathEdgeConfigID:               edgeConfigID,
+			pathEdgeConfigTeamID:           teamID,
+		},
+		Secret: &logical.Secret{
+			InternalData: map[string]any{
+				"secret_type":         edgeConfigTokenSecretType,
+				pathEdgeConfigToken:   token,
+				pathEdgeConfigTokenID: tokenID,
+				pathEdgeConfigID:      edgeConfigID,
+				pathEdgeConfigTeamID:  teamID,
+			},
+			LeaseOptions: logical.LeaseOptions{
+				TTL: time.Duration(ttl) * time.Second,
+			},
+		},
+	}
+
+	if warning := b.recordLease(ctx, req.Storage, cfg, resp.Secret); warning != "" {
+		resp.AddWarning(warning)
+	}
+
+	return resp, nil
+}
+
+func edgeConfigConnectionString(edgeConfigID, token string) string {
+	return edgeConfigBaseURL + url.PathEscape(edgeConfigID) + "?" + url.Values{"token": {token}}.Encode()
+}
+
+// revokeEdgeConfigToken deletes the read token of a lease. Failures are
+// returned to Vault, which retries the revocation.
+func (b *backend) revokeEdgeConfigToken(ctx context.Context, req *logical.Request,
+	_ *framework.FieldData) (*logical.Response, error) {
+	if req.Secret == nil {
+		return nil, errInternalDataMissing
+	}
+
+	token, _ := req.Secret.InternalData[pathEdgeConfigToken].(string)
+	edgeConfigID, _ := req.Secret.InternalData[pathEdgeConfigID].(string)
+	teamID, _ := req.Secret.InternalData[pathEdgeConfigTeamID].(string)
+
+	if token == "" || edgeConfigID == "" {
+		return nil, errInternalDataMissing
+	}
+
+	svc, err := b.revokeService(ctx, req.Storage, leaseCredentialID(req.Secret))
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.DeleteEdgeConfigToken(ctx, edgeConfigID, teamID, token)
+	if errors.Is(err, vercel.ErrNotFound) {
+		b.Logger().Warn("edge config no longer exists on Vercel, treating token as revoked",
+			"edge_config_id", edgeConfigID)
+		b.deleteLeaseRecord(ctx, req.Storage, req.Secret)
+
+		return &logical.Response{}, nil
+	}
+
+	if err != nil {
+		b.Logger().Error("failed to revoke edge config token", "edge_config_id", edgeConfigID, "error", err)
+
+		return nil, vercelError(errRevokeEdgeConfigToken, err)
+	}
+
+	b.deleteLeaseRecord(ctx, req.Storage, req.Secret)
+
+	return &logical.Response{}, nil
+}
@@ -0,0 +1,179 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/service"
+)
+
+const pathTestEdgeConfigToken = "edge-config/ecfg_a/token"
+
+func TestEdgeConfigToken_Create(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		cfgData  map[string]any
+		data     map[string]any
+		expTeam  string
+		expTTL   time.Duration
+		expError string
+	}{
+		"without backend": {
+			expError: "backend not configured",
+		},
+		"default ttl": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+				"max_ttl":     600,
+			},
+			expTTL: 600 * time.Second,
+		},
+		"with ttl and team id": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			data: map[string]any{
+				"ttl":     60,
+				"team_id": "team_a",
+			},
+			expTeam: "team_a",
+			expTTL:  time.Minute,
+		},
+		"invalid ttl": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			data: map[string]any{
+				"ttl": 0,
+			},
+			expError: "invalid ttl",
+		},
+		"vercel failure": {
+			cfgData: map[string]any{
+				"client_type":        "mock",
+				"mock_create_script": "404",
+			},
+			expError: "failed to create edge config token: vercel resource not found: failure injected by mock client",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := newTestBackend(t, nil)
+
+			if tc.cfgData != nil {
+				_, err := b.HandleRequest(ctx, &logical.Request{
+					Storage:   storage,
+					Operation: logical.CreateOperation,
+					Path:      pathPatternConfig,
+					Data:      tc.cfgData,
+				})
+				require.NoError(t, err)
+			}
+
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      pathTestEdgeConfigToken,
+				Data:      tc.data,
+			})
+
+			if tc.expError != "" {
+				require.ErrorContains(t, err, tc.expError)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			token, _ := r.Data["token"].(string)
+			require.NotEmpty(t, token)
+			require.NotEmpty(t, r.Data["token_id"])
+			require.Equal(t, "https://edge-config.vercel.com/ecfg_a?token="+token, r.Data["connection_string"])
+			require.Equal(t, "ecfg_a", r.Data["edge_config_id"])
+			require.Equal(t, tc.expTeam, r.Data["team_id"])
+			require.NotNil(t, r.Secret)
+			require.Equal(t, edgeConfigTokenSecretType, r.Secret.InternalData["secret_type"])
+			require.Equal(t, token, r.Secret.InternalData["token"])
+			require.Equal(t, tc.expTTL, r.Secret.TTL)
+		})
+	}
+}
+
+func TestEdgeConfigToken_Revoke(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	s, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{APIKey: "root-key"})
+	t.Cleanup(ts.Close)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"api_key":  "root-key",
+			"base_url": ts.URL,
+		},
+	})
+	require.NoError(t, err)
+
+	create := func() *logical.Response {
+		t.Helper()
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      pathTestEdgeConfigToken,
+		})
+		require.NoError(t, err)
+
+		return r
+	}
+
+	revoke := func(internalData map[string]any) error {
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.RevokeOperation,
+			Path:      pathTestEdgeConfigToken,
+			Secret: &logical.Secret{
+				InternalData: internalData,
+			},
+		})
+
+		return err
+	}
+
+	r := create()
+	tokens := s.EdgeConfigTokens("ecfg_a")
+	require.Len(t, tokens, 1)
+	require.Equal(t, r.Data["token"], tokens[0].Token)
+	require.Contains(t, tokens[0].Label, keyPrefix)
+
+	require.NoError(t, revoke(r.Secret.InternalData))
+	require.Empty(t, s.EdgeConfigTokens("ecfg_a"))
+
+	require.ErrorIs(t, revoke(map[string]any{
+		"secret_type":    edgeConfigTokenSecretType,
+		"edge_config_id": "ecfg_a",
+	}), errInternalDataMissing)
+
+	// Failures are returned so that Vault retries the revocation.
+	r = create()
+	b.svc = service.NewWithBaseURL("bogus", ts.URL)
+
+	require.ErrorContains(t, revoke(r.Secret.InternalData), "failed to revoke edge config token")
+	require.Len(t, s.EdgeConfigTokens("ecfg_a"), 1)
+}
@@ -3,6 +3,7 @@ package plugin
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -22,9 +23,20 @@ func (b *backend) pathInfo() []*framework.Path {
 	return []*framework.Path{
 		{
 			Pattern: pathPatternInfo,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "build-info",
+			},
 			Operations: map[logical.Operation]framework.OperationHandler{
 				logical.ReadOperation: &framework.PathOperation{
 					Callback: b.pathInfoRead,
+					Summary:  "Read build information about the plugin.",
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: http.StatusText(http.StatusOK),
+							Fields:      infoResponseFields(),
+						}},
+					},
 				},
 			},
 			HelpDescription: pathPatternHelpDescription,
@@ -56,3 +68,26 @@ func (b *backend) pathInfoRead(
 		Data: m,
 	}, nil
 }
+
+func infoResponseFields() map[string]*framework.FieldSchema {
+	descriptions := map[string]string{
+		"build_date":          "Date the plugin binary was built.",
+		"build_version":       "Version of the plugin binary.",
+		"build_commit":        "Git commit the plugin binary was built from.",
+		"build_commit_date":   "Date of the git commit the plugin binary was built from.",
+		"build_commit_branch": "Git branch the plugin binary was built from.",
+		"build_tag":           "Git tag the plugin binary was built from.",
+		"build_dirty":         "Whether the working tree had uncommitted changes at build time.",
+	}
+
+	fields := make(map[string]*framework.FieldSchema, len(descriptions))
+	for k, d := range descriptions {
+		fields[k] = &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: d,
+			Required:    true,
+		}
+	}
+
+	return fields
+}
@@ -6,17 +6,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/stretchr/testify/require"
 	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/version"
 )
 
+// TestPathInfo mutates the package level build variables read by Factory,
+// so it must not run in parallel with other tests.
+//
+//nolint:paralleltest
 func TestPathInfo(t *testing.T) {
-	t.Parallel()
-
 	t.Run("ValidInfo", func(t *testing.T) {
-		t.Parallel()
-
 		version.Branch = "main"
 		version.BuildDate = time.Now().String()
 		version.Commit = "xyz"
@@ -34,6 +35,8 @@ func TestPathInfo(t *testing.T) {
 			Path:      pathPatternInfo,
 		})
 		require.NoError(t, err)
+		schema.ValidateResponse(t,
+			schema.GetResponseSchema(t, b.Route(pathPatternInfo), logical.ReadOperation), res, true)
 
 		var vi version.Info
 
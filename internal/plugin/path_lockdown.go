@@ -0,0 +1,273 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	pathPatternLockdown            = "lockdown"
+	pathLockdownEnabled            = "enabled"
+	pathLockdownReason             = "reason"
+	pathLockdownUpdatedAt          = "updated_at"
+	pathLockdownUpdatedBy          = "updated_by"
+	lockdownStorageKey             = "lockdown"
+	pathLockdownEnabledDescription = `
+Whether issuance is disabled. Defaults to true on write.`
+	pathLockdownReasonDescription = `
+(Optional) Reason for the lockdown, included in the error returned to refused requests.`
+	pathLockdownUpdatedAtDescription = `
+When the lockdown state was last changed.`
+	pathLockdownUpdatedByDescription = `
+Display name of the requester who last changed the lockdown state.`
+	pathLockdownHelpSynopsis = `
+Disable issuance of new credentials on this mount.`
+	pathLockdownHelpDescription = `
+While the lockdown is enabled, every path issuing Vercel credentials refuses requests.
+Existing leases are not affected and are revoked as usual. Write with enabled=false,
+or delete the path, to lift the lockdown. The lockdown is replicated to all clusters.`
+)
+
+var (
+	errLockdown      = errors.New("issuance is disabled by lockdown")
+	errWriteLockdown = errors.New("failed to write lockdown state to storage")
+)
+
+// lockdownState is stored while issuance is disabled on the mount.
+type lockdownState struct {
+	Enabled   bool      `json:"enabled"`
+	Reason    string    `json:"reason,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+}
+
+func (b *backend) pathLockdown() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         pathPatternLockdown,
+			HelpSynopsis:    pathLockdownHelpSynopsis,
+			HelpDescription: pathLockdownHelpDescription,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "lockdown",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				pathLockdownEnabled: {
+					Type:        framework.TypeBool,
+					Description: pathLockdownEnabledDescription,
+					Default:     true,
+				},
+				pathLockdownReason: {
+					Type:        framework.TypeString,
+					Description: pathLockdownReasonDescription,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback:  b.pathLockdownRead,
+					Summary:   "Read the lockdown state.",
+					Responses: lockdownResponses(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "read",
+					},
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback:  traced("vercel.lockdown.write", b.pathLockdownWrite),
+					Summary:   "Enable or lift the lockdown.",
+					Responses: lockdownResponses(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "configure",
+					},
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback:  traced("vercel.lockdown.delete", b.pathLockdownDelete),
+					Summary:   "Lift the lockdown.",
+					Responses: noContentResponses(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "delete",
+					},
+				},
+			},
+		},
+	}
+}
+
+func lockdownResponses() map[int][]framework.Response {
+	return map[int][]framework.Response{
+		http.StatusOK: {{
+			Description: http.StatusText(http.StatusOK),
+			Fields: map[string]*framework.FieldSchema{
+				pathLockdownEnabled: {
+					Type:        framework.TypeBool,
+					Description: pathLockdownEnabledDescription,
+					Required:    true,
+				},
+				pathLockdownReason: {
+					Type:        framework.TypeString,
+					Description: pathLockdownReasonDescription,
+				},
+				pathLockdownUpdatedAt: {
+					Type:        framework.TypeTime,
+					Description: pathLockdownUpdatedAtDescription,
+				},
+				pathLockdownUpdatedBy: {
+					Type:        framework.TypeString,
+					Description: pathLockdownUpdatedByDescription,
+				},
+			},
+		}},
+	}
+}
+
+func (b *backend) getLockdown(ctx context.Context, s logical.Storage) (*lockdownState, error) {
+	e, err := s.Get(ctx, lockdownStorageKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if e == nil {
+		return nil, nil
+	}
+
+	var l lockdownState
+	if err = e.DecodeJSON(&l); err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+func (b *backend) putLockdown(ctx context.Context, s logical.Storage, l *lockdownState) error {
+	e, err := logical.StorageEntryJSON(lockdownStorageKey, l)
+	if err != nil {
+		return err
+	}
+
+	return s.Put(ctx, e)
+}
+
+// checkLockdown returns errLockdown while issuance is disabled on the mount.
+func (b *backend) checkLockdown(ctx context.Context, s logical.Storage) error {
+	l, err := b.getLockdown(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if l == nil || !l.Enabled {
+		return nil
+	}
+
+	if l.Reason != "" {
+		return fmt.Errorf("%w: %s", errLockdown, l.Reason)
+	}
+
+	return errLockdown
+}
+
+// enableLockdown disables issuance, keeping the reason of an existing lockdown
+// when none is given.
+func (b *backend) enableLockdown(ctx context.Context, req *logical.Request, reason string) (*lockdownState, error) {
+	prev, err := b.getLockdown(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if reason == "" && prev != nil && prev.Enabled {
+		reason = prev.Reason
+	}
+
+	l := &lockdownState{
+		Enabled:   true,
+		Reason:    reason,
+		UpdatedAt: time.Now().UTC(),
+		UpdatedBy: req.DisplayName,
+	}
+
+	if err = b.putLockdown(ctx, req.Storage, l); err != nil {
+		b.Logger().Error("failed to write lockdown state", "error", err)
+
+		return nil, errWriteLockdown
+	}
+
+	b.Logger().Warn("issuance lockdown enabled", "reason", reason, "by", req.DisplayName)
+
+	return l, nil
+}
+
+func lockdownResponse(l *lockdownState) *logical.Response {
+	if l == nil {
+		return &logical.Response{
+			Data: map[string]any{
+				pathLockdownEnabled: false,
+			},
+		}
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			pathLockdownEnabled:   l.Enabled,
+			pathLockdownReason:    l.Reason,
+			pathLockdownUpdatedAt: l.UpdatedAt,
+			pathLockdownUpdatedBy: l.UpdatedBy,
+		},
+	}
+}
+
+func (b *backend) pathLockdownRead(ctx context.Context, req *logical.Request,
+	_ *framework.FieldData) (*logical.Response, error) {
+	l, err := b.getLockdown(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	return lockdownResponse(l), nil
+}
+
+func (b *backend) pathLockdownWrite(ctx context.Context, req *logical.Request,
+	data *framework.FieldData) (*logical.Response, error) {
+	enabled, _ := data.Get(pathLockdownEnabled).(bool)
+	reason, _ := data.Get(pathLockdownReason).(string)
+
+	if enabled {
+		l, err := b.enableLockdown(ctx, req, reason)
+		if err != nil {
+			return nil, err
+		}
+
+		return lockdownResponse(l), nil
+	}
+
+	l := &lockdownState{
+		UpdatedAt: time.Now().UTC(),
+		UpdatedBy: req.DisplayName,
+	}
+
+	if err := b.putLockdown(ctx, req.Storage, l); err != nil {
+		b.Logger().Error("failed to write lockdown state", "error", err)
+
+		return nil, errWriteLockdown
+	}
+
+	b.Logger().Info("issuance lockdown lifted", "by", req.DisplayName)
+
+	return lockdownResponse(l), nil
+}
+
+func (b *backend) pathLockdownDelete(ctx context.Context, req *logical.Request,
+	_ *framework.FieldData) (*logical.Response, error) {
+	if err := req.Storage.Delete(ctx, lockdownStorageKey); err != nil {
+		b.Logger().Error("failed to delete lockdown state", "error", err)
+
+		return nil, errWriteLockdown
+	}
+
+	b.Logger().Info("issuance lockdown lifted", "by", req.DisplayName)
+
+	return &logical.Response{}, nil
+}
@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockdown(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"client_type": "mock",
+		},
+	})
+	require.NoError(t, err)
+
+	lockdown := func(op logical.Operation, data map[string]any) *logical.Response {
+		t.Helper()
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:     storage,
+			Operation:   op,
+			Path:        pathPatternLockdown,
+			Data:        data,
+			DisplayName: "oncall",
+		})
+		require.NoError(t, err)
+
+		return r
+	}
+
+	issue := func(path string, data map[string]any) error {
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Data:      data,
+		})
+
+		return err
+	}
+
+	webhook := map[string]any{"url": "https://example.com/hook", "events": "deployment.created"}
+
+	r := lockdown(logical.ReadOperation, nil)
+	require.Equal(t, false, r.Data["enabled"])
+	require.NoError(t, issue(pathPatternToken, nil))
+
+	r = lockdown(logical.UpdateOperation, map[string]any{"reason": "INC-1"})
+	require.Equal(t, true, r.Data["enabled"])
+	require.Equal(t, "INC-1", r.Data["reason"])
+	require.Equal(t, "oncall", r.Data["updated_by"])
+
+	for _, p := range []string{pathPatternToken, pathTestProtectionBypass, pathTestEdgeConfigToken} {
+		err = issue(p, nil)
+		require.ErrorIs(t, err, errLockdown, p)
+		require.EqualError(t, err, "issuance is disabled by lockdown: INC-1")
+	}
+
+	require.ErrorIs(t, issue(pathPatternWebhooks, webhook), errLockdown)
+
+	// Enabling again without a reason keeps the existing one.
+	r = lockdown(logical.UpdateOperation, nil)
+	require.Equal(t, "INC-1", r.Data["reason"])
+
+	r = lockdown(logical.UpdateOperation, map[string]any{"enabled": false})
+	require.Equal(t, false, r.Data["enabled"])
+	require.NoError(t, issue(pathPatternToken, nil))
+
+	lockdown(logical.UpdateOperation, nil)
+	require.EqualError(t, issue(pathPatternToken, nil), errLockdown.Error())
+
+	lockdown(logical.DeleteOperation, nil)
+	require.NoError(t, issue(pathPatternWebhooks, webhook))
+	require.Equal(t, false, lockdown(logical.ReadOperation, nil).Data["enabled"])
+}
@@ -0,0 +1,151 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	pathProjectEnvProjectID            = "project_id"
+	pathProjectEnvTarget               = "target"
+	pathProjectEnvGitBranch            = "git_branch"
+	pathProjectEnvTeamID               = "team_id"
+	envTargetProduction                = "production"
+	envTargetPreview                   = "preview"
+	envTargetDevelopment               = "development"
+	pathProjectEnvProjectIDDescription = `
+Vercel project ID or name.`
+	pathProjectEnvTargetDescription = `
+Deployment target to read variables for. One of "production", "preview" or "development".`
+	pathProjectEnvGitBranchDescription = `
+(Optional) Git branch. Branch-specific preview variables override generic ones.`
+	pathProjectEnvTeamIDDescription = `
+(Optional) Team ID or slug owning the project. Defaults to default_team_id from configuration.
+May be an identity template and is subject to allowed_teams.`
+	pathProjectEnvHelpSynopsis = `
+Read the decrypted environment variables of a Vercel project.`
+	pathProjectEnvHelpDescription = `
+Fetches and decrypts the environment variables of a Vercel project for a single deployment
+target using the configured API key. Variables are returned as response data keyed by name.
+Sensitive variables cannot be decrypted through the Vercel API and are reported as warnings.
+Nothing is stored in the plugin backend. Supports only read operations.`
+)
+
+var (
+	envTargets = []any{envTargetProduction, envTargetPreview, envTargetDevelopment}
+
+	errInvalidEnvTarget = errors.New("target must be one of production, preview or development")
+	errReadProjectEnv   = errors.New("failed to read project environment")
+)
+
+func (b *backend) pathProjectEnv() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "projects/" + framework.GenericNameRegex(pathProjectEnvProjectID) +
+				"/environment/" + framework.GenericNameRegex(pathProjectEnvTarget),
+			HelpSynopsis:    pathProjectEnvHelpSynopsis,
+			HelpDescription: pathProjectEnvHelpDescription,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "project-environment",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				pathProjectEnvProjectID: {
+					Type:        framework.TypeString,
+					Description: pathProjectEnvProjectIDDescription,
+					Required:    true,
+				},
+				pathProjectEnvTarget: {
+					Type:          framework.TypeString,
+					Description:   pathProjectEnvTargetDescription,
+					AllowedValues: envTargets,
+					Required:      true,
+				},
+				pathProjectEnvGitBranch: {
+					Type:        framework.TypeString,
+					Description: pathProjectEnvGitBranchDescription,
+				},
+				pathProjectEnvTeamID: {
+					Type:        framework.TypeString,
+					Description: pathProjectEnvTeamIDDescription,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathProjectEnvRead,
+					Summary:  "Read the decrypted environment variables of a Vercel project.",
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "read",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "Environment variables keyed by name.",
+						}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) pathProjectEnvRead(ctx context.Context, req *logical.Request,
+	data *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg == nil {
+		return nil, errBackendNotConfigured
+	}
+
+	projectID, _ := data.Get(pathProjectEnvProjectID).(string)
+	target, _ := data.Get(pathProjectEnvTarget).(string)
+	gitBranch, _ := data.Get(pathProjectEnvGitBranch).(string)
+
+	switch target {
+	case envTargetProduction, envTargetPreview, envTargetDevelopment:
+	default:
+		return nil, errInvalidEnvTarget
+	}
+
+	requestedTeamID, _ := data.Get(pathProjectEnvTeamID).(string)
+
+	teamID, err := b.resolveTeamID(ctx, req, cfg, requestedTeamID, requestedTeamID != "")
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := b.getService(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	values, skipped, err := svc.ProjectEnv(ctx, projectID, target, gitBranch, teamID)
+	if err != nil {
+		b.Logger().Error("failed to read project environment", "project_id", projectID, "error", err)
+
+		return nil, vercelError(errReadProjectEnv, err)
+	}
+
+	respData := make(map[string]any, len(values))
+	for k, v := range values {
+		respData[k] = v
+	}
+
+	resp := &logical.Response{Data: respData}
+
+	if len(skipped) > 0 {
+		sort.Strings(skipped)
+		resp.AddWarning("sensitive variables cannot be decrypted and were omitted: " +
+			strings.Join(skipped, ", "))
+	}
+
+	return resp, nil
+}
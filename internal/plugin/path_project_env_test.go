@@ -0,0 +1,145 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectEnv_Read(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		disabledOps []logical.Operation
+		cfgData     map[string]any
+		path        string
+		data        map[string]any
+		expData     map[string]any
+		expWarnings []string
+		expError    string
+	}{
+		"without backend": {
+			path:     "projects/prj_a/environment/production",
+			expError: "backend not configured",
+		},
+		"with storage fail": {
+			disabledOps: []logical.Operation{
+				logical.ReadOperation,
+			},
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			path:     "projects/prj_a/environment/production",
+			expError: "failed to get config from storage",
+		},
+		"production": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			path: "projects/prj_a/environment/production",
+			expData: map[string]any{
+				"MOCK_SHARED": "shared-value",
+			},
+			expWarnings: []string{
+				"sensitive variables cannot be decrypted and were omitted: MOCK_SENSITIVE",
+			},
+		},
+		"preview": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			path: "projects/prj_a/environment/preview",
+			expData: map[string]any{
+				"MOCK_SHARED": "shared-value",
+				"MOCK_BRANCH": "preview-value",
+			},
+		},
+		"preview with git branch": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			path: "projects/prj_a/environment/preview",
+			data: map[string]any{
+				"git_branch": "mock-branch",
+			},
+			expData: map[string]any{
+				"MOCK_SHARED": "shared-value",
+				"MOCK_BRANCH": "branch-value",
+			},
+		},
+		"development with default team id": {
+			cfgData: map[string]any{
+				"client_type":     "mock",
+				"default_team_id": "team_a",
+			},
+			path: "projects/prj_a/environment/development",
+			data: map[string]any{
+				"team_id": "team_a",
+			},
+			expData: map[string]any{
+				"MOCK_SHARED": "shared-value",
+			},
+		},
+		"invalid target": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			path:     "projects/prj_a/environment/staging",
+			expError: "target must be one of production, preview or development",
+		},
+		"conflicting team ids": {
+			cfgData: map[string]any{
+				"client_type":     "mock",
+				"default_team_id": "team_a",
+			},
+			path: "projects/prj_a/environment/production",
+			data: map[string]any{
+				"team_id": "team_b",
+			},
+			expError: "cannot override default_team_id",
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := newTestBackend(t, nil)
+
+			if tc.cfgData != nil {
+				_, err := b.HandleRequest(ctx, &logical.Request{
+					Storage:   storage,
+					Operation: logical.CreateOperation,
+					Path:      pathPatternConfig,
+					Data:      tc.cfgData,
+				})
+				require.NoError(t, err)
+			}
+
+			// Storage fails once the backend is configured.
+			failStorageOps(storage.(*logical.InmemStorage), tc.disabledOps)
+
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.ReadOperation,
+				Path:      tc.path,
+				Data:      tc.data,
+			})
+
+			if tc.expError != "" {
+				require.EqualError(t, err, tc.expError)
+				require.Nil(t, r)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expData, r.Data)
+			require.Equal(t, tc.expWarnings, r.Warnings)
+		})
+	}
+}
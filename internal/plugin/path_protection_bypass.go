@@ -0,0 +1,256 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+const (
+	// #nosec G101
+	protectionBypassSecretType               = "vercel_protection_bypass"
+	pathProtectionBypassProjectID            = "project_id"
+	pathProtectionBypassTeamID               = "team_id"
+	pathProtectionBypassSecret               = "secret"
+	pathProtectionBypassProjectIDDescription = `
+Vercel project ID or name.`
+	pathProtectionBypassTeamIDDescription = `
+(Optional) Team ID or slug owning the project. Defaults to default_team_id from configuration.
+May be an identity template and is subject to allowed_teams.`
+	//nolint:gosec
+	pathProtectionBypassTTLDescription = `
+(Optional) TTL for the generated bypass secret. Less than or equal to the maximum TTL set in configuration.
+Defaults to maximum TTL.`
+	//nolint:gosec
+	pathProtectionBypassHelpSynopsis = `
+Generate a Vercel Protection Bypass for Automation secret for a project.`
+	//nolint:gosec
+	pathProtectionBypassHelpDescription = `
+Adds a Protection Bypass for Automation secret to a Vercel project using the configured API key.
+Send it in the x-vercel-protection-bypass header to reach protected deployments of the project.
+The secret is removed from the project when the lease is revoked or expires.
+Bypass secrets do not expire on Vercel, so they are only cleaned up through lease revocation.`
+	//nolint:gosec
+	pathProtectionBypassSecretResponseDescription = `
+Generated protection bypass secret.`
+	pathProtectionBypassProjectIDResponseDescription = `
+Project the bypass secret was added to.`
+	pathProtectionBypassTeamIDResponseDescription = `
+Team owning the project. Empty for projects under a personal account.`
+	// #nosec G101
+	secretProtectionBypassDescription = `
+Protection bypass secret of the project, used for revocation.`
+)
+
+var (
+	errCreateProtectionBypass = errors.New("failed to create protection bypass secret")
+	errRevokeProtectionBypass = errors.New("failed to revoke protection bypass secret")
+)
+
+func (b *backend) pathProtectionBypass() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         "projects/" + framework.GenericNameRegex(pathProtectionBypassProjectID) + "/protection-bypass",
+			HelpSynopsis:    pathProtectionBypassHelpSynopsis,
+			HelpDescription: pathProtectionBypassHelpDescription,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "protection-bypass",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				pathProtectionBypassProjectID: {
+					Type:        framework.TypeString,
+					Description: pathProtectionBypassProjectIDDescription,
+					Required:    true,
+				},
+				pathProtectionBypassTeamID: {
+					Type:        framework.TypeString,
+					Description: pathProtectionBypassTeamIDDescription,
+				},
+				pathTokenTTL: {
+					Type:        framework.TypeDurationSecond,
+					Description: pathProtectionBypassTTLDescription,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback:  traced("vercel.protection_bypass.create", b.pathProtectionBypassWrite),
+					Summary:   "Generate a protection bypass secret for a Vercel project.",
+					Responses: protectionBypassResponses(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "generate",
+					},
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback:  traced("vercel.protection_bypass.create", b.pathProtectionBypassWrite),
+					Summary:   "Generate a protection bypass secret for a Vercel project.",
+					Responses: protectionBypassResponses(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "generate",
+						OperationSuffix: "protection-bypass-with-parameters",
+					},
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) protectionBypassSecret() *framework.Secret {
+	return &framework.Secret{
+		Type: protectionBypassSecretType,
+		Fields: map[string]*framework.FieldSchema{
+			pathProtectionBypassSecret: {
+				Type:        framework.TypeString,
+				Description: secretProtectionBypassDescription,
+			},
+		},
+		Revoke: traced("vercel.protection_bypass.revoke", b.revokeProtectionBypass),
+	}
+}
+
+func protectionBypassResponses() map[int][]framework.Response {
+	return map[int][]framework.Response{
+		http.StatusOK: {{
+			Description: http.StatusText(http.StatusOK),
+			Fields: map[string]*framework.FieldSchema{
+				pathProtectionBypassSecret: {
+					Type:        framework.TypeString,
+					Description: pathProtectionBypassSecretResponseDescription,
+					Required:    true,
+					DisplayAttrs: &framework.DisplayAttributes{
+						Sensitive: true,
+					},
+				},
+				pathProtectionBypassProjectID: {
+					Type:        framework.TypeString,
+					Description: pathProtectionBypassProjectIDResponseDescription,
+					Required:    true,
+				},
+				pathProtectionBypassTeamID: {
+					Type:        framework.TypeString,
+					Description: pathProtectionBypassTeamIDResponseDescription,
+					Required:    true,
+				},
+			},
+		}},
+	}
+}
+
+func (b *backend) pathProtectionBypassWrite(ctx context.Context, req *logical.Request,
+	data *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg == nil {
+		return nil, errBackendNotConfigured
+	}
+
+	if err = b.checkLockdown(ctx, req.Storage); err != nil {
+		return nil, err
+	}
+
+	ttl, err := leaseTTL(data, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, _ := data.Get(pathProtectionBypassProjectID).(string)
+
+	rawTeamID, teamIDSet := data.GetOk(pathProtectionBypassTeamID)
+	requestedTeamID, _ := rawTeamID.(string)
+
+	teamID, err := b.resolveTeamID(ctx, req, cfg, requestedTeamID, teamIDSet)
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := b.getService(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	note := fmt.Sprintf("%s-%d", keyPrefix, time.Now().UnixNano())
+
+	b.Logger().Info("creating protection bypass secret", "project_id", projectID, "note", note, "ttl", ttl)
+
+	secret, err := svc.CreateProtectionBypass(ctx, projectID, teamID, note)
+	if err != nil {
+		b.Logger().Error("failed to create protection bypass secret", "project_id", projectID, "error", err)
+
+		return nil, vercelError(errCreateProtectionBypass, err)
+	}
+
+	resp := &logical.Response{
+		Data: map[string]any{
+			pathProtectionBypassSecret:    secret,
+			pathProtectionBypassProjectID: projectID,
+			pathProtectionBypassTeamID:    teamID,
+		},
+		Secret: &logical.Secret{
+			InternalData: map[string]any{
+				"secret_type":                 protectionBypassSecretType,
+				pathProtectionBypassSecret:    secret,
+				pathProtectionBypassProjectID: projectID,
+				pathProtectionBypassTeamID:    teamID,
+			},
+			LeaseOptions: logical.LeaseOptions{
+				TTL: time.Duration(ttl) * time.Second,
+			},
+		},
+	}
+
+	if warning := b.recordLease(ctx, req.Storage, cfg, resp.Secret); warning != "" {
+		resp.AddWarning(warning)
+	}
+
+	return resp, nil
+}
+
+// revokeProtectionBypass removes the bypass secret of a lease from its
+// project. Failures are returned to Vault, which retries the revocation.
+func (b *backend) revokeProtectionBypass(ctx context.Context, req *logical.Request,
+	_ *framework.FieldData) (*logical.Response, error) {
+	if req.Secret == nil {
+		return nil, errInternalDataMissing
+	}
+
+	secret, _ := req.Secret.InternalData[pathProtectionBypassSecret].(string)
+	projectID, _ := req.Secret.InternalData[pathProtectionBypassProjectID].(string)
+	teamID, _ := req.Secret.InternalData[pathProtectionBypassTeamID].(string)
+
+	if secret == "" || projectID == "" {
+		return nil, errInternalDataMissing
+	}
+
+	svc, err := b.revokeService(ctx, req.Storage, leaseCredentialID(req.Secret))
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.RevokeProtectionBypass(ctx, projectID, teamID, secret)
+	if errors.Is(err, vercel.ErrNotFound) {
+		b.Logger().Warn("protection bypass secret already removed from Vercel, treating as revoked",
+			"project_id", projectID)
+		b.deleteLeaseRecord(ctx, req.Storage, req.Secret)
+
+		return &logical.Response{}, nil
+	}
+
+	if err != nil {
+		b.Logger().Error("failed to revoke protection bypass secret", "project_id", projectID, "error", err)
+
+		return nil, vercelError(errRevokeProtectionBypass, err)
+	}
+
+	b.deleteLeaseRecord(ctx, req.Storage, req.Secret)
+
+	return &logical.Response{}, nil
+}
@@ -0,0 +1,189 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/service"
+)
+
+const pathTestProtectionBypass = "projects/prj_a/protection-bypass"
+
+func TestProtectionBypass_Create(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		cfgData  map[string]any
+		data     map[string]any
+		expTeam  string
+		expTTL   time.Duration
+		expError string
+	}{
+		"without backend": {
+			expError: "backend not configured",
+		},
+		"default ttl": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+				"max_ttl":     600,
+			},
+			expTTL: 600 * time.Second,
+		},
+		"with ttl and default team id": {
+			cfgData: map[string]any{
+				"client_type":     "mock",
+				"default_team_id": "team_a",
+			},
+			data: map[string]any{
+				"ttl": 60,
+			},
+			expTeam: "team_a",
+			expTTL:  time.Minute,
+		},
+		"ttl exceeds max ttl": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+				"max_ttl":     60,
+			},
+			data: map[string]any{
+				"ttl": 120,
+			},
+			expError: "TTL exceeds the maximum value",
+		},
+		"team not allowed": {
+			cfgData: map[string]any{
+				"client_type":   "mock",
+				"allowed_teams": "team_a",
+			},
+			data: map[string]any{
+				"team_id": "team_b",
+			},
+			expError: errTeamNotAllowed.Error(),
+		},
+		"vercel failure": {
+			cfgData: map[string]any{
+				"client_type":        "mock",
+				"mock_create_script": "403",
+			},
+			expError: "failed to create protection bypass secret: vercel denied access: failure injected by mock client",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := newTestBackend(t, nil)
+
+			if tc.cfgData != nil {
+				_, err := b.HandleRequest(ctx, &logical.Request{
+					Storage:   storage,
+					Operation: logical.CreateOperation,
+					Path:      pathPatternConfig,
+					Data:      tc.cfgData,
+				})
+				require.NoError(t, err)
+			}
+
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      pathTestProtectionBypass,
+				Data:      tc.data,
+			})
+
+			if tc.expError != "" {
+				require.ErrorContains(t, err, tc.expError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Regexp(t, `^[A-Za-z0-9]{32}$`, r.Data["secret"])
+			require.Equal(t, "prj_a", r.Data["project_id"])
+			require.Equal(t, tc.expTeam, r.Data["team_id"])
+			require.NotNil(t, r.Secret)
+			require.Equal(t, protectionBypassSecretType, r.Secret.InternalData["secret_type"])
+			require.Equal(t, r.Data["secret"], r.Secret.InternalData["secret"])
+			require.Equal(t, tc.expTTL, r.Secret.TTL)
+		})
+	}
+}
+
+func TestProtectionBypass_Revoke(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	s, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{APIKey: "root-key", Teams: []string{"team_a"}})
+	t.Cleanup(ts.Close)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"api_key":         "root-key",
+			"base_url":        ts.URL,
+			"default_team_id": "team_a",
+		},
+	})
+	require.NoError(t, err)
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathTestProtectionBypass,
+	})
+	require.NoError(t, err)
+
+	secret, _ := r.Data["secret"].(string)
+	require.Contains(t, s.ProtectionBypasses("prj_a"), secret)
+
+	revoke := func(internalData map[string]any) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.RevokeOperation,
+			Path:      pathTestProtectionBypass,
+			Secret: &logical.Secret{
+				InternalData: internalData,
+			},
+		})
+	}
+
+	_, err = revoke(r.Secret.InternalData)
+	require.NoError(t, err)
+	require.Empty(t, s.ProtectionBypasses("prj_a"))
+
+	// Secrets removed outside Vault count as revoked.
+	_, err = revoke(r.Secret.InternalData)
+	require.NoError(t, err)
+
+	_, err = revoke(map[string]any{
+		"secret_type": protectionBypassSecretType,
+		"project_id":  "prj_a",
+	})
+	require.ErrorIs(t, err, errInternalDataMissing)
+
+	// Other failures are returned so that Vault retries the revocation.
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathTestProtectionBypass,
+	})
+	require.NoError(t, err)
+
+	b.svc = service.NewWithBaseURL("bogus", ts.URL)
+
+	_, err = revoke(r.Secret.InternalData)
+	require.ErrorContains(t, err, "failed to revoke protection bypass secret")
+	require.Len(t, s.ProtectionBypasses("prj_a"), 1)
+}
@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	pathPatternRateLimits      = "rate-limits"
+	pathRateLimitsEndpoints    = "endpoints"
+	pathRateLimitsHelpSynopsis = `
+Show the remaining Vercel API request budget per endpoint.`
+	pathRateLimitsHelpDescription = `
+Vercel limits the requests to each API endpoint within a time window and reports the remaining
+budget in the X-RateLimit-* headers of its responses. The plugin tracks the budget per endpoint,
+spreads requests over the rest of the window once less than 10% of the budget is left, and holds
+requests back while the budget is exhausted. Requests that would wait more than 30 seconds fail
+with a rate limit error without being sent.
+
+The budget is tracked in memory per Vault node and only for endpoints the plugin called within
+the current window. Supports only read operations.`
+	pathRateLimitsEndpointsDescription = `
+Limit, remaining requests and reset time per endpoint, keyed by method and path.`
+)
+
+func (b *backend) pathRateLimits() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         pathPatternRateLimits,
+			HelpSynopsis:    pathRateLimitsHelpSynopsis,
+			HelpDescription: pathRateLimitsHelpDescription,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "rate-limits",
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathRateLimitsRead,
+					Summary:  "Show the remaining Vercel API request budget per endpoint.",
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: http.StatusText(http.StatusOK),
+							Fields: map[string]*framework.FieldSchema{
+								pathRateLimitsEndpoints: {
+									Type:        framework.TypeMap,
+									Description: pathRateLimitsEndpointsDescription,
+									Required:    true,
+								},
+							},
+						}},
+					},
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "read",
+					},
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) pathRateLimitsRead(ctx context.Context, req *logical.Request,
+	_ *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg == nil {
+		return nil, errBackendNotConfigured
+	}
+
+	svc, err := b.getService(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make(map[string]any)
+
+	for _, l := range svc.RateLimits() {
+		endpoints[l.Endpoint] = map[string]any{
+			"limit":     l.Limit,
+			"remaining": l.Remaining,
+			"reset":     l.Reset.UTC().Format(time.RFC3339),
+		}
+	}
+
+	return &logical.Response{
+		Data: map[string]any{
+			pathRateLimitsEndpoints: endpoints,
+		},
+	}, nil
+}
@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
+)
+
+func TestRateLimits_Read(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternRateLimits,
+	})
+	require.ErrorIs(t, err, errBackendNotConfigured)
+
+	_, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{
+		APIKey:          "root-key",
+		Teams:           []string{"team_a"},
+		RateLimit:       5,
+		RateLimitWindow: time.Hour,
+	})
+	t.Cleanup(ts.Close)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"api_key":  "root-key",
+			"base_url": ts.URL,
+		},
+	})
+	require.NoError(t, err)
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternRateLimits,
+	})
+	require.NoError(t, err)
+	require.Empty(t, r.Data["endpoints"])
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternToken,
+		Data:      map[string]any{"team_id": "team_a"},
+	})
+	require.NoError(t, err)
+
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternRateLimits,
+	})
+	require.NoError(t, err)
+
+	endpoints, _ := r.Data["endpoints"].(map[string]any)
+	require.Len(t, endpoints, 2)
+
+	create, _ := endpoints["POST /user/tokens"].(map[string]any)
+	require.Equal(t, 5, create["limit"])
+	require.Equal(t, 4, create["remaining"])
+	require.NotEmpty(t, create["reset"])
+	require.Contains(t, endpoints, "GET /teams")
+}
@@ -0,0 +1,85 @@
+package plugin
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	pathPatternRevocationsPending = "revocations/pending/?$"
+	pathRevocationsHelpSynopsis   = `
+List token revocations waiting to be retried.`
+	pathRevocationsHelpDescription = `
+When deleting a token from Vercel fails during lease revocation, the token is queued
+in the plugin storage and retried with exponential backoff. Entries are removed once
+the token is deleted or has expired on Vercel. The queue survives plugin restarts.
+Supports only list operations.`
+)
+
+func (b *backend) pathRevocations() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         pathPatternRevocationsPending,
+			HelpSynopsis:    pathRevocationsHelpSynopsis,
+			HelpDescription: pathRevocationsHelpDescription,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "pending-revocations",
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.pathRevocationsList,
+					Summary:  "List token revocations waiting to be retried.",
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: http.StatusText(http.StatusOK),
+							Fields: map[string]*framework.FieldSchema{
+								"keys": {
+									Type:        framework.TypeStringSlice,
+									Description: "Token IDs waiting to be revoked.",
+								},
+								"key_info": {
+									Type:        framework.TypeMap,
+									Description: "Retry state per token ID.",
+								},
+							},
+						}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) pathRevocationsList(ctx context.Context, req *logical.Request,
+	_ *framework.FieldData) (*logical.Response, error) {
+	pending, err := b.listPendingRevocations(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(pending))
+	info := make(map[string]any, len(pending))
+
+	for _, p := range pending {
+		keys = append(keys, p.TokenID)
+
+		i := map[string]any{
+			"attempts":     p.Attempts,
+			"last_error":   p.LastError,
+			"queued_at":    p.QueuedAt.Format(time.RFC3339),
+			"next_attempt": p.NextAttempt.Format(time.RFC3339),
+		}
+		if !p.ExpiresAt.IsZero() {
+			i["expires_at"] = p.ExpiresAt.Format(time.RFC3339)
+		}
+
+		info[p.TokenID] = i
+	}
+
+	return logical.ListResponseWithInfo(keys, info), nil
+}
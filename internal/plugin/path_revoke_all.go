@@ -0,0 +1,329 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/client"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/maintenance"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+const (
+	pathPatternRevokeAll                = "revoke-all"
+	pathRevokeAllParallelism            = "parallelism"
+	pathRevokeAllTotal                  = "total"
+	pathRevokeAllDeleted                = "deleted"
+	pathRevokeAllFailed                 = "failed"
+	pathRevokeAllTokens                 = "tokens"
+	defaultRevokeAllParallelism         = 8
+	maxRevokeAllParallelism             = 32
+	revokeStatusDeleted                 = "deleted"
+	revokeStatusFailed                  = "failed"
+	pathRevokeAllParallelismDescription = `
+(Optional) Number of tokens deleted concurrently. Defaults to 8, at most 32.`
+	pathRevokeAllReasonDescription = `
+(Optional) Reason for the lockdown enabled by this operation.`
+	pathRevokeAllTotalDescription = `
+Number of live tokens created by this mount.`
+	pathRevokeAllDeletedDescription = `
+Number of tokens deleted, including tokens already gone from Vercel.`
+	pathRevokeAllFailedDescription = `
+Number of tokens that could not be deleted.`
+	pathRevokeAllTokensDescription = `
+Outcome per token with its ID, name, team ID, status and error, if any.`
+	pathRevokeAllHelpSynopsis = `
+Enable the lockdown and delete every Vercel token created by this mount.`
+	pathRevokeAllHelpDescription = `
+Break-glass operation for incidents. Enables the lockdown so that no new credentials are issued,
+then deletes every live token this mount created: tokens it recorded as issued and not yet revoked,
+tokens whose revocation is being retried, and tokens on Vercel named with the prefix of this mount,
+whether or not Vault still tracks a lease for them. Tokens of other mounts or Vault clusters sharing
+the API key are left alone. Each token is deleted with the credential it was issued with, and
+tokens of a credential the plugin no longer keeps are reported as failed. Tokens are deleted
+concurrently and the outcome is reported per token. Leases of deleted tokens stay in Vault and
+are revoked as usual, which treats the already deleted tokens as revoked. Supports only update
+operations.`
+)
+
+var (
+	errInvalidRevokeAllParallelism = fmt.Errorf("parallelism must be between 1 and %d", maxRevokeAllParallelism)
+	errRevokeAllList               = errors.New("failed to list tokens")
+	errRevokeAllRecords            = errors.New("failed to read issued tokens from storage")
+)
+
+func (b *backend) pathRevokeAll() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         pathPatternRevokeAll,
+			HelpSynopsis:    pathRevokeAllHelpSynopsis,
+			HelpDescription: pathRevokeAllHelpDescription,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "tokens",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				pathRevokeAllParallelism: {
+					Type:        framework.TypeInt,
+					Description: pathRevokeAllParallelismDescription,
+					Default:     defaultRevokeAllParallelism,
+				},
+				pathLockdownReason: {
+					Type:        framework.TypeString,
+					Description: pathRevokeAllReasonDescription,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: traced("vercel.token.revoke_all", b.pathRevokeAllWrite),
+					Summary:  "Enable the lockdown and delete every Vercel token created by this mount.",
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "revoke-all",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: http.StatusText(http.StatusOK),
+							Fields: map[string]*framework.FieldSchema{
+								pathRevokeAllTotal: {
+									Type:        framework.TypeInt,
+									Description: pathRevokeAllTotalDescription,
+									Required:    true,
+								},
+								pathRevokeAllDeleted: {
+									Type:        framework.TypeInt,
+									Description: pathRevokeAllDeletedDescription,
+									Required:    true,
+								},
+								pathRevokeAllFailed: {
+									Type:        framework.TypeInt,
+									Description: pathRevokeAllFailedDescription,
+									Required:    true,
+								},
+								pathRevokeAllTokens: {
+									Type:        framework.TypeSlice,
+									Description: pathRevokeAllTokensDescription,
+									Required:    true,
+								},
+							},
+						}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) pathRevokeAllWrite(ctx context.Context, req *logical.Request,
+	data *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg == nil {
+		return nil, errBackendNotConfigured
+	}
+
+	parallelism, _ := data.Get(pathRevokeAllParallelism).(int)
+	if parallelism < 1 || parallelism > maxRevokeAllParallelism {
+		return nil, errInvalidRevokeAllParallelism
+	}
+
+	reason, _ := data.Get(pathLockdownReason).(string)
+
+	if _, err = b.enableLockdown(ctx, req, reason); err != nil {
+		return nil, err
+	}
+
+	svc, err := b.getService(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, credentials, err := b.revokeAllTokens(ctx, req, svc.Client(), cfg.credentialID())
+	if err != nil {
+		return nil, err
+	}
+
+	b.Logger().Warn("revoking all plugin tokens", "count", len(tokens), "by", req.DisplayName)
+
+	results := b.deleteWithCredentials(ctx, req.Storage, tokens, credentials, parallelism)
+	report := make([]map[string]any, 0, len(results))
+	deleted, failed := 0, 0
+
+	for _, r := range results {
+		entry := map[string]any{
+			pathTokenID:     r.Token.ID,
+			"name":          r.Token.Name,
+			pathTokenTeamID: r.Token.TeamID(),
+			"status":        revokeStatusDeleted,
+		}
+
+		if r.Err != nil {
+			failed++
+
+			entry["status"] = revokeStatusFailed
+			entry["error"] = vercelError(errRemoteTokenRevokeFailed, r.Err).Error()
+
+			b.Logger().Error("failed to delete token during revoke-all", "token_id", r.Token.ID, "error", r.Err)
+		} else {
+			deleted++
+
+			b.markRevoked(ctx, req.Storage, r.Token.ID)
+
+			if err = req.Storage.Delete(ctx, revocationQueueKey(r.Token.ID)); err != nil {
+				b.Logger().Warn("failed to remove pending revocation", "token_id", r.Token.ID, "error", err)
+			}
+		}
+
+		report = append(report, entry)
+	}
+
+	resp := &logical.Response{
+		Data: map[string]any{
+			pathRevokeAllTotal:   len(results),
+			pathRevokeAllDeleted: deleted,
+			pathRevokeAllFailed:  failed,
+			pathRevokeAllTokens:  report,
+		},
+	}
+
+	if failed > 0 {
+		resp.AddWarning(fmt.Sprintf("%d of %d tokens could not be deleted, run revoke-all again to retry",
+			failed, len(results)))
+	}
+
+	return resp, nil
+}
+
+// deleteWithCredentials deletes each token with the credential it was issued
+// with, so that a token of another credential is never reported as deleted
+// because the current one cannot see it. Tokens whose credential is no longer
+// kept are reported as failed. Results are returned in the order of tokens.
+func (b *backend) deleteWithCredentials(ctx context.Context, s logical.Storage, tokens []vercel.Token,
+	credentials map[string]string, parallelism int) []maintenance.Result {
+	// groups holds the positions in tokens of the tokens of each credential.
+	groups := make(map[string][]int)
+	order := make([]string, 0)
+
+	for i, t := range tokens {
+		id := credentials[t.ID]
+		if _, ok := groups[id]; !ok {
+			order = append(order, id)
+		}
+
+		groups[id] = append(groups[id], i)
+	}
+
+	results := make([]maintenance.Result, len(tokens))
+
+	for _, id := range order {
+		group := make([]vercel.Token, 0, len(groups[id]))
+		for _, i := range groups[id] {
+			group = append(group, tokens[i])
+		}
+
+		var groupResults []maintenance.Result
+
+		svc, err := b.revokeService(ctx, s, id)
+		if err != nil {
+			b.Logger().Error("cannot delete tokens of credential during revoke-all", "credential_id", id,
+				"count", len(group), "error", err)
+
+			groupResults = make([]maintenance.Result, len(group))
+			for j, t := range group {
+				groupResults[j] = maintenance.Result{Token: t, Err: err}
+			}
+		} else {
+			groupResults = maintenance.DeleteParallel(ctx, svc.Client(), group, false, parallelism)
+		}
+
+		for j, i := range groups[id] {
+			results[i] = groupResults[j]
+		}
+	}
+
+	return results
+}
+
+// revokeAllTokens returns the live tokens created by this mount: issued tokens
+// not revoked yet, tokens whose revocation is pending, and tokens on Vercel
+// named with the prefix of the mount. It also returns the ID of the credential
+// each token was issued with. Tokens listed with c use currentCredentialID.
+func (b *backend) revokeAllTokens(ctx context.Context, req *logical.Request, c client.Client,
+	currentCredentialID string) ([]vercel.Token, map[string]string, error) {
+	records, err := b.listIssuances(ctx, req.Storage)
+	if err != nil {
+		return nil, nil, errRevokeAllRecords
+	}
+
+	pending, err := b.listPendingRevocations(ctx, req.Storage)
+	if err != nil {
+		return nil, nil, errRevokeAllRecords
+	}
+
+	var tokens []vercel.Token
+
+	// credentials maps token IDs to the credential they were issued with.
+	credentials := make(map[string]string)
+
+	// index maps token IDs to their position in tokens.
+	index := make(map[string]int)
+	add := func(t vercel.Token, credentialID string) {
+		if i, ok := index[t.ID]; ok {
+			// Prefer the details listed by Vercel over the recorded ones.
+			if t.Name != "" {
+				tokens[i] = t
+			}
+
+			return
+		}
+
+		index[t.ID] = len(tokens)
+		tokens = append(tokens, t)
+		credentials[t.ID] = credentialID
+	}
+
+	now := time.Now()
+
+	for _, r := range records {
+		if !r.RevokedAt.IsZero() || (!r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)) {
+			continue
+		}
+
+		t := vercel.Token{ID: r.TokenID}
+		if r.TeamID != "" {
+			t.Scopes = []vercel.TokenScope{{Type: "team", TeamID: r.TeamID}}
+		}
+
+		add(t, r.CredentialID)
+	}
+
+	for _, p := range pending {
+		add(vercel.Token{ID: p.TokenID}, p.CredentialID)
+	}
+
+	// Without a mount accessor the name prefix would match the tokens of
+	// every mount, so only the records are used.
+	if req.MountAccessor == "" {
+		return tokens, credentials, nil
+	}
+
+	named, err := maintenance.List(ctx, c, maintenance.Filter{Prefix: tokenNamePrefix(req.MountAccessor)})
+	if err != nil {
+		b.Logger().Error("failed to list tokens for revoke-all", "error", err)
+
+		return nil, nil, vercelError(errRevokeAllList, err)
+	}
+
+	for _, t := range named {
+		add(t, currentCredentialID)
+	}
+
+	return tokens, credentials, nil
+}
@@ -0,0 +1,326 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+func TestRevokeAll(t *testing.T) {
+	t.Parallel()
+
+	const accessor = "vercel_1a2b3c"
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	s, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{
+		APIKey:    "root-key",
+		Teams:     []string{"team_a"},
+		RateLimit: 1000,
+	})
+	t.Cleanup(ts.Close)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"api_key":  "root-key",
+			"base_url": ts.URL,
+		},
+	})
+	require.NoError(t, err)
+
+	leased := make(map[string]bool)
+
+	for _, teamID := range []string{"", "team_a", "team_a"} {
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:       storage,
+			Operation:     logical.UpdateOperation,
+			Path:          pathPatternToken,
+			Data:          map[string]any{"team_id": teamID},
+			MountAccessor: accessor,
+		})
+		require.NoError(t, err)
+
+		id, _ := r.Data["token_id"].(string)
+		leased[id] = true
+	}
+
+	c := vercel.NewClient("root-key", vercel.WithBaseURL(ts.URL))
+	create := func(name string) string {
+		res, err := c.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{Name: name})
+		require.NoError(t, err)
+
+		return res.Token.ID
+	}
+
+	// A token of this mount Vault no longer tracks, and one whose revocation
+	// is being retried.
+	leased[create(tokenNamePrefix(accessor)+"orphan")] = true
+
+	queued := create("renamed-token")
+	leased[queued] = true
+
+	require.NoError(t, b.queueRevocation(ctx, storage, queued, "", time.Now().Add(time.Hour), errors.New("boom")))
+
+	// Tokens of another mount sharing the API key, and created by hand.
+	create(tokenNamePrefix("vercel_other") + "1")
+	create(keyPrefix + "-1760868431000000000")
+	create("manual-token")
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:       storage,
+		Operation:     logical.UpdateOperation,
+		Path:          pathPatternRevokeAll,
+		Data:          map[string]any{"reason": "INC-2", "parallelism": 2},
+		DisplayName:   "oncall",
+		MountAccessor: accessor,
+	})
+	require.NoError(t, err)
+	require.Empty(t, r.Warnings)
+	require.Equal(t, 5, r.Data["total"])
+	require.Equal(t, 5, r.Data["deleted"])
+	require.Equal(t, 0, r.Data["failed"])
+
+	report, _ := r.Data["tokens"].([]map[string]any)
+	require.Len(t, report, 5)
+
+	for _, e := range report {
+		require.True(t, leased[e["token_id"].(string)])
+		require.Equal(t, "deleted", e["status"])
+	}
+
+	remaining := make([]string, 0)
+	for _, tok := range s.Tokens() {
+		remaining = append(remaining, tok.Name)
+	}
+
+	require.ElementsMatch(t, []string{
+		tokenNamePrefix("vercel_other") + "1",
+		keyPrefix + "-1760868431000000000",
+		"manual-token",
+	}, remaining)
+
+	// Deleted tokens are no longer reported as live.
+	pending, err := b.listPendingRevocations(ctx, storage)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	n, err := b.outstandingLeases(ctx, storage, "")
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	l, err := b.getLockdown(ctx, storage)
+	require.NoError(t, err)
+	require.True(t, l.Enabled)
+	require.Equal(t, "INC-2", l.Reason)
+	require.Equal(t, "oncall", l.UpdatedBy)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternToken,
+	})
+	require.ErrorIs(t, err, errLockdown)
+}
+
+func TestRevokeAll_IssuingCredential(t *testing.T) {
+	t.Parallel()
+
+	const accessor = "vercel_1a2b3c"
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	oldServer, oldTS := fakevercel.NewHTTPTestServer(fakevercel.Config{APIKey: "old-key", RateLimit: 1000})
+	t.Cleanup(oldTS.Close)
+
+	newServer, newTS := fakevercel.NewHTTPTestServer(fakevercel.Config{APIKey: "new-key", RateLimit: 1000})
+	t.Cleanup(newTS.Close)
+
+	writeConfig := func(apiKey, baseURL string) {
+		t.Helper()
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.CreateOperation,
+			Path:      pathPatternConfig,
+			Data:      map[string]any{"api_key": apiKey, "base_url": baseURL},
+		})
+		require.NoError(t, err)
+	}
+
+	issue := func() string {
+		t.Helper()
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:       storage,
+			Operation:     logical.UpdateOperation,
+			Path:          pathPatternToken,
+			MountAccessor: accessor,
+		})
+		require.NoError(t, err)
+
+		id, _ := r.Data["token_id"].(string)
+
+		return id
+	}
+
+	writeConfig("old-key", oldTS.URL)
+	oldToken := issue()
+
+	writeConfig("new-key", newTS.URL)
+	newToken := issue()
+
+	// A token of a credential no longer kept is not deleted with the current
+	// one, even though the current one can see it.
+	c := vercel.NewClient("new-key", vercel.WithBaseURL(newTS.URL))
+	res, err := c.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{Name: tokenNamePrefix(accessor) + "gone"})
+	require.NoError(t, err)
+
+	goneToken := res.Token.ID
+	require.NoError(t, b.putIssuance(ctx, storage, &issuanceRecord{
+		TokenID:      goneToken,
+		IssuedAt:     time.Now(),
+		CredentialID: "0123456789abcdef",
+	}))
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:       storage,
+		Operation:     logical.UpdateOperation,
+		Path:          pathPatternRevokeAll,
+		MountAccessor: accessor,
+	})
+	require.NoError(t, err)
+	require.Len(t, r.Warnings, 1)
+	require.Equal(t, 3, r.Data["total"])
+	require.Equal(t, 2, r.Data["deleted"])
+	require.Equal(t, 1, r.Data["failed"])
+
+	report, _ := r.Data["tokens"].([]map[string]any)
+	status := make(map[string]any, len(report))
+
+	for _, e := range report {
+		status[e["token_id"].(string)] = e["status"]
+	}
+
+	require.Equal(t, map[string]any{
+		oldToken:  "deleted",
+		newToken:  "deleted",
+		goneToken: "failed",
+	}, status)
+	require.Empty(t, oldServer.Tokens())
+
+	remaining := newServer.Tokens()
+	require.Len(t, remaining, 1)
+	require.Equal(t, goneToken, remaining[0].ID)
+
+	rec, err := b.getIssuance(ctx, storage, goneToken)
+	require.NoError(t, err)
+	require.True(t, rec.RevokedAt.IsZero())
+}
+
+func TestRevokeAll_Failures(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		cfgData    map[string]any
+		data       map[string]any
+		expError   string
+		expDeleted int
+		expFailed  int
+	}{
+		"without backend": {
+			expError: "backend not configured",
+		},
+		"invalid parallelism": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			data: map[string]any{
+				"parallelism": 100,
+			},
+			expError: errInvalidRevokeAllParallelism.Error(),
+		},
+		"partial failure": {
+			cfgData: map[string]any{
+				"client_type":        "mock",
+				"mock_delete_script": "403",
+			},
+			data: map[string]any{
+				"parallelism": 1,
+			},
+			expDeleted: 1,
+			expFailed:  1,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := newTestBackend(t, nil)
+
+			if tc.cfgData != nil {
+				_, err := b.HandleRequest(ctx, &logical.Request{
+					Storage:   storage,
+					Operation: logical.CreateOperation,
+					Path:      pathPatternConfig,
+					Data:      tc.cfgData,
+				})
+				require.NoError(t, err)
+
+				for i := 0; i < 2; i++ {
+					_, err = b.HandleRequest(ctx, &logical.Request{
+						Storage:   storage,
+						Operation: logical.ReadOperation,
+						Path:      pathPatternToken,
+					})
+					require.NoError(t, err)
+				}
+			}
+
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      pathPatternRevokeAll,
+				Data:      tc.data,
+			})
+
+			if tc.expError != "" {
+				require.EqualError(t, err, tc.expError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expDeleted, r.Data["deleted"])
+			require.Equal(t, tc.expFailed, r.Data["failed"])
+			require.Len(t, r.Warnings, 1)
+
+			failed := 0
+
+			for _, e := range r.Data["tokens"].([]map[string]any) {
+				if e["status"] == "failed" {
+					failed++
+
+					require.Equal(t, "failed to revoke token: vercel denied access: failure injected by mock client",
+						e["error"])
+				}
+			}
+
+			require.Equal(t, tc.expFailed, failed)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package plugin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	pathPatternTeams      = "teams/?$"
+	pathTeamsHelpSynopsis = `
+List the Vercel teams the configured API key can access.`
+	pathTeamsHelpDescription = `
+Lists the teams the configured API key is a member of, keyed by team ID, with their
+slug and name. Wherever a team ID is accepted, the team slug can be used instead.
+Listing refreshes the cached teams used to resolve slugs.
+Supports only list operations.`
+)
+
+func (b *backend) pathTeams() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         pathPatternTeams,
+			HelpSynopsis:    pathTeamsHelpSynopsis,
+			HelpDescription: pathTeamsHelpDescription,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "teams",
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: traced("vercel.teams.list", b.pathTeamsList),
+					Summary:  "List the Vercel teams the configured API key can access.",
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: http.StatusText(http.StatusOK),
+							Fields: map[string]*framework.FieldSchema{
+								"keys": {
+									Type:        framework.TypeStringSlice,
+									Description: "Team IDs.",
+								},
+								"key_info": {
+									Type:        framework.TypeMap,
+									Description: "Slug and name per team ID.",
+								},
+							},
+						}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) pathTeamsList(ctx context.Context, req *logical.Request,
+	_ *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg == nil {
+		return nil, errBackendNotConfigured
+	}
+
+	c, err := b.listTeams(ctx, cfg, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(c.teams))
+	info := make(map[string]any, len(c.teams))
+
+	for _, t := range c.teams {
+		keys = append(keys, t.ID)
+		info[t.ID] = map[string]any{
+			"slug": t.Slug,
+			"name": t.Name,
+		}
+	}
+
+	return logical.ListResponseWithInfo(keys, info), nil
+}
@@ -0,0 +1,244 @@
+package plugin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/service"
+)
+
+func TestTeams_List(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ListOperation,
+		Path:      "teams/",
+	})
+	require.ErrorIs(t, err, errBackendNotConfigured)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data:      map[string]any{"client_type": "mock"},
+	})
+	require.NoError(t, err)
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ListOperation,
+		Path:      "teams/",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"team_a", "team_b"}, r.Data["keys"])
+	require.Equal(t, map[string]any{
+		"team_a": map[string]any{"slug": "team-a", "name": "Team A"},
+		"team_b": map[string]any{"slug": "team-b", "name": "Team B"},
+	}, r.Data["key_info"])
+}
+
+func TestTeams_Resolve(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	s, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{
+		APIKey:    "root-key",
+		Teams:     []string{"team_a"},
+		TeamSlugs: map[string]string{"team_a": "acme"},
+	})
+	t.Cleanup(ts.Close)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"api_key":       "root-key",
+			"base_url":      ts.URL,
+			"allowed_teams": "acme",
+		},
+	})
+	require.NoError(t, err)
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken,
+		Data:      map[string]any{"team_id": "acme"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "team_a", r.Data[pathTokenTeamID])
+	require.Len(t, s.Tokens(), 1)
+	require.Equal(t, "team_a", s.Tokens()[0].Scopes[0].TeamID)
+
+	// Unknown teams are rejected without trying to create a token.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken,
+		Data:      map[string]any{"team_id": "globex"},
+	})
+	require.ErrorIs(t, err, errUnknownTeam)
+	require.Len(t, s.Tokens(), 1)
+
+	// Teams are resolved from the cache, so only token creation fails here.
+	b.svc = service.NewWithBaseURL("bogus", ts.URL)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken,
+		Data:      map[string]any{"team_id": "acme"},
+	})
+	require.ErrorContains(t, err, errCreateToken.Error())
+
+	b.teams = nil
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternToken,
+		Data:      map[string]any{"team_id": "acme"},
+	})
+	require.ErrorContains(t, err, errListTeams.Error())
+}
+
+func TestTeams_ResolveDefaultTeam(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		data       map[string]any
+		expTeamID  string
+		expWarning string
+		expError   error
+	}{
+		"slug": {
+			data:      map[string]any{"client_type": "mock", "default_team_id": "team-b"},
+			expTeamID: "team_b",
+		},
+		"id": {
+			data:      map[string]any{"client_type": "mock", "default_team_id": "team_b"},
+			expTeamID: "team_b",
+		},
+		"unknown slug": {
+			data:     map[string]any{"client_type": "mock", "default_team_id": "team-x"},
+			expError: errUnknownTeam,
+		},
+		"teams unavailable": {
+			data: map[string]any{
+				"api_key":         "root-key",
+				"base_url":        "http://localhost:69696",
+				"default_team_id": "acme",
+			},
+			expTeamID:  "acme",
+			expWarning: `default_team_id "acme" could not be resolved to a team ID and is stored as given`,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := newTestBackend(t, nil)
+
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.CreateOperation,
+				Path:      pathPatternConfig,
+				Data:      tc.data,
+			})
+			if tc.expError != nil {
+				require.ErrorIs(t, err, tc.expError)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tc.expWarning != "" {
+				require.Len(t, r.Warnings, 1)
+				require.Contains(t, r.Warnings[0], tc.expWarning)
+			} else {
+				require.Empty(t, r.Warnings)
+			}
+
+			cfg, err := b.getConfig(ctx, storage)
+			require.NoError(t, err)
+			require.Equal(t, tc.expTeamID, cfg.DefaultTeamID)
+		})
+	}
+}
+
+func TestTeams_ListingUnavailable(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	s, fake := fakevercel.NewHTTPTestServer(fakevercel.Config{
+		APIKey:    "root-key",
+		Teams:     []string{"team_a"},
+		TeamSlugs: map[string]string{"team_a": "acme"},
+	})
+	t.Cleanup(fake.Close)
+
+	// The teams endpoint fails, while tokens can still be created.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/teams") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		s.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"api_key":         "root-key",
+			"base_url":        ts.URL,
+			"default_team_id": "team_a",
+		},
+	})
+	require.NoError(t, err)
+
+	issue := func(data map[string]any) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternToken,
+			Data:      data,
+		})
+	}
+
+	// Team IDs are used as given.
+	r, err := issue(nil)
+	require.NoError(t, err)
+	require.Equal(t, "team_a", r.Data[pathTokenTeamID])
+
+	r, err = issue(map[string]any{"team_id": "team_a"})
+	require.NoError(t, err)
+	require.Equal(t, "team_a", r.Data[pathTokenTeamID])
+
+	// Slugs cannot be resolved without the listing.
+	_, err = issue(map[string]any{"team_id": "acme"})
+	require.ErrorContains(t, err, errListTeams.Error())
+}
@@ -4,11 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
-	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/service"
 )
 
 const (
@@ -18,14 +18,25 @@ const (
 	pathTokenBearerToken = "bearer_token"
 	pathTokenTTL         = "ttl"
 	pathTokenTeamID      = "team_id"
+	pathTokenProjectID   = "project_id"
+	pathTokenFormat      = "format"
+	secretExpiresAt      = "expires_at"
 	//nolint:gosec
 	pathTokenTTLDescription = `
 (Optional) TTL for the generated API key ("bearer token"). Less than or equal to the maximum TTL set in configuration.
 Defaults to maximum TTL.`
 	//nolint:gosec
 	pathTokenTeamIDDescription = `
-(Optional) Team ID used for generating the API key.
-This acts as a scope for the key. It only has access to the given team.`
+(Optional) Team ID or slug used for generating the API key.
+This acts as a scope for the key. It only has access to the given team.
+May be an identity template such as {{identity.entity.metadata.vercel_team}}.`
+	pathTokenProjectIDDescription = `
+(Optional) Vercel project ID included in the project_json and dotenv formats.
+Defaults to default_project_id from configuration.`
+	pathTokenFormatDescription = `
+(Optional) Additional output format for the generated token, returned in a response field of the same name.
+"auth_json" renders a Vercel CLI auth.json file, "project_json" renders a .vercel/project.json
+project link and "dotenv" renders VERCEL_TOKEN, VERCEL_ORG_ID and VERCEL_PROJECT_ID variables.`
 	pathTokenDescription = `
 Supports only read operations. Token ID for the generated key is stored in the plugin backend for revocation purposes.
 Generated bearer token is NOT stored in the plugin backend.
@@ -34,6 +45,23 @@ Tokens cannot be renewed. Generate a new token if you need one.`
 	//nolint:gosec
 	pathTokenSynopsis = `
 Generate a Vercel API token with the given TTL.`
+	pathTokenIDResponseDescription = `
+ID of the generated token. Used for revocation, cannot be used for API operations.`
+	//nolint:gosec
+	pathTokenBearerTokenResponseDescription = `
+Generated Vercel API token.`
+	pathTokenTeamIDResponseDescription = `
+Team ID the generated token is scoped to. Empty for tokens with personal account scope.`
+	pathTokenProjectIDResponseDescription = `
+Project ID included in the rendered output format, if any.`
+	//nolint:gosec
+	pathTokenAuthJSONResponseDescription = `
+Vercel CLI auth.json file containing the generated token. Returned with format=auth_json.`
+	pathTokenProjectJSONResponseDescription = `
+Vercel CLI .vercel/project.json project link. Returned with format=project_json.`
+	//nolint:gosec
+	pathTokenDotenvResponseDescription = `
+Dotenv block with VERCEL_TOKEN, VERCEL_ORG_ID and VERCEL_PROJECT_ID. Returned with format=dotenv.`
 )
 
 var (
@@ -49,6 +77,10 @@ func (b *backend) pathToken() []*framework.Path {
 			Pattern:         pathPatternToken,
 			HelpDescription: pathTokenDescription,
 			HelpSynopsis:    pathTokenSynopsis,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "token",
+			},
 			Fields: map[string]*framework.FieldSchema{
 				pathTokenTTL: {
 					Type:        framework.TypeDurationSecond,
@@ -58,19 +90,89 @@ func (b *backend) pathToken() []*framework.Path {
 					Type:        framework.TypeString,
 					Description: pathTokenTeamIDDescription,
 				},
+				pathTokenProjectID: {
+					Type:        framework.TypeString,
+					Description: pathTokenProjectIDDescription,
+				},
+				pathTokenFormat: {
+					Type:          framework.TypeString,
+					Description:   pathTokenFormatDescription,
+					AllowedValues: tokenFormats,
+				},
 			},
 			Operations: map[logical.Operation]framework.OperationHandler{
 				logical.ReadOperation: &framework.PathOperation{
-					Callback: b.pathTokenWrite,
+					Callback:  traced("vercel.token.create", b.pathTokenWrite),
+					Summary:   "Generate a Vercel API token.",
+					Responses: tokenResponses(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "generate",
+					},
 				},
 				logical.UpdateOperation: &framework.PathOperation{
-					Callback: b.pathTokenWrite,
+					Callback:  traced("vercel.token.create", b.pathTokenWrite),
+					Summary:   "Generate a Vercel API token.",
+					Responses: tokenResponses(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "generate",
+						OperationSuffix: "token-with-parameters",
+					},
 				},
 			},
 		},
 	}
 }
 
+func tokenResponses() map[int][]framework.Response {
+	return map[int][]framework.Response{
+		http.StatusOK: {{
+			Description: http.StatusText(http.StatusOK),
+			Fields: map[string]*framework.FieldSchema{
+				pathTokenID: {
+					Type:        framework.TypeString,
+					Description: pathTokenIDResponseDescription,
+					Required:    true,
+				},
+				pathTokenBearerToken: {
+					Type:        framework.TypeString,
+					Description: pathTokenBearerTokenResponseDescription,
+					Required:    true,
+					DisplayAttrs: &framework.DisplayAttributes{
+						Sensitive: true,
+					},
+				},
+				pathTokenTeamID: {
+					Type:        framework.TypeString,
+					Description: pathTokenTeamIDResponseDescription,
+					Required:    true,
+				},
+				pathTokenProjectID: {
+					Type:        framework.TypeString,
+					Description: pathTokenProjectIDResponseDescription,
+				},
+				tokenFormatAuthJSON: {
+					Type:        framework.TypeString,
+					Description: pathTokenAuthJSONResponseDescription,
+					DisplayAttrs: &framework.DisplayAttributes{
+						Sensitive: true,
+					},
+				},
+				tokenFormatProjectJSON: {
+					Type:        framework.TypeString,
+					Description: pathTokenProjectJSONResponseDescription,
+				},
+				tokenFormatDotenv: {
+					Type:        framework.TypeString,
+					Description: pathTokenDotenvResponseDescription,
+					DisplayAttrs: &framework.DisplayAttributes{
+						Sensitive: true,
+					},
+				},
+			},
+		}},
+	}
+}
+
 func (b *backend) pathTokenWrite(ctx context.Context, req *logical.Request,
 	data *framework.FieldData) (*logical.Response, error) {
 	cfg, err := b.getConfig(ctx, req.Storage)
@@ -82,43 +184,40 @@ func (b *backend) pathTokenWrite(ctx context.Context, req *logical.Request,
 		return nil, errBackendNotConfigured
 	}
 
-	ttl := int64(0)
+	if err = b.checkLockdown(ctx, req.Storage); err != nil {
+		return nil, err
+	}
 
-	ttlSeconds, ttlSet, ttlErr := durationSeconds(data, pathTokenTTL)
-	if ttlErr != nil {
-		return nil, errInvalidTokenTTL
+	ttl, err := leaseTTL(data, cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	if ttlSet {
-		if ttlSeconds <= 0 {
-			return nil, errInvalidTokenTTL
-		}
+	rawTeamID, teamIDSet := data.GetOk(pathTokenTeamID)
+	requestedTeamID, _ := rawTeamID.(string)
 
-		ttl = int64(ttlSeconds)
+	teamID, err := b.resolveTeamID(ctx, req, cfg, requestedTeamID, teamIDSet)
+	if err != nil {
+		return nil, err
 	}
 
-	if ttl == 0 {
-		ttl = cfg.MaxTTL
+	projectID := cfg.DefaultProjID
+	if v, _ := data.Get(pathTokenProjectID).(string); v != "" {
+		projectID = v
 	}
 
-	if ttl > cfg.MaxTTL {
-		return nil, errTokenMaxTTLExceeded
+	format, _ := data.Get(pathTokenFormat).(string)
+	if err = validateTokenFormat(format, tokenCredentials{TeamID: teamID, ProjectID: projectID}); err != nil {
+		return nil, err
 	}
 
-	teamID := cfg.DefaultTeamID
-
-	if vr, ok := data.GetOk(pathTokenTeamID); ok {
-		v, _ := vr.(string)
-		if teamID != "" && v != "" && teamID != v {
-			return nil, errCannotOverrideDefaultTeamID
-		}
-
-		teamID = v
+	svc, err := b.getService(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	svc := service.NewWithBaseURL(cfg.APIKey, cfg.BaseURL)
 	ts := time.Now().UnixNano()
-	name := fmt.Sprintf("%s-%d", keyPrefix, ts)
+	name := fmt.Sprintf("%s%d", tokenNamePrefix(req.MountAccessor), ts)
 
 	b.Logger().Info("creating token", "name", name, "ttl", ttl)
 
@@ -126,19 +225,57 @@ func (b *backend) pathTokenWrite(ctx context.Context, req *logical.Request,
 	if err != nil {
 		b.Logger().Error("failed to create token", "error", err)
 
-		return nil, errCreateToken
+		return nil, vercelError(errCreateToken, err)
+	}
+
+	expiresAt := time.Now().Add(time.Duration(ttl) * time.Second).UTC()
+	var warnings []string
+
+	if err = b.putIssuance(ctx, req.Storage, &issuanceRecord{
+		TokenID:      tokenID,
+		TeamID:       teamID,
+		EntityID:     req.EntityID,
+		DisplayName:  req.DisplayName,
+		IssuedAt:     time.Now().UTC(),
+		ExpiresAt:    expiresAt,
+		CredentialID: cfg.credentialID(),
+	}); err != nil {
+		b.Logger().Warn("failed to write issuance record", "token_id", tokenID, "error", err)
+		warnings = append(warnings, "failed to record token issuance, usage will not be reported for this token")
+	}
+
+	respData := map[string]any{
+		pathTokenID:          tokenID,
+		pathTokenBearerToken: bearerToken,
+		pathTokenTeamID:      teamID,
+	}
+
+	if format != "" {
+		rendered, renderErr := renderTokenFormat(format, tokenCredentials{
+			BearerToken: bearerToken,
+			TeamID:      teamID,
+			ProjectID:   projectID,
+		})
+		if renderErr != nil {
+			return nil, renderErr
+		}
+
+		respData[format] = rendered
+
+		if projectID != "" {
+			respData[pathTokenProjectID] = projectID
+		}
 	}
 
 	return &logical.Response{
-		Data: map[string]any{
-			pathTokenID:          tokenID,
-			pathTokenBearerToken: bearerToken,
-			pathTokenTeamID:      teamID,
-		},
+		Data:     respData,
+		Warnings: warnings,
 		Secret: &logical.Secret{
 			InternalData: map[string]any{
-				"secret_type": backendSecretType,
-				pathTokenID:   tokenID,
+				"secret_type":      backendSecretType,
+				pathTokenID:        tokenID,
+				secretExpiresAt:    expiresAt.Format(time.RFC3339),
+				secretCredentialID: cfg.credentialID(),
 			},
 			LeaseOptions: logical.LeaseOptions{
 				TTL: time.Duration(ttl) * time.Second,
@@ -146,3 +283,37 @@ func (b *backend) pathTokenWrite(ctx context.Context, req *logical.Request,
 		},
 	}, nil
 }
+
+// leaseTTL returns the requested lease TTL in seconds, defaulting to and
+// bounded by the maximum TTL from configuration.
+func leaseTTL(data *framework.FieldData, cfg *backendConfig) (int64, error) {
+	ttlSeconds, ttlSet, err := durationSeconds(data, pathTokenTTL)
+	if err != nil {
+		return 0, errInvalidTokenTTL
+	}
+
+	if !ttlSet {
+		return cfg.MaxTTL, nil
+	}
+
+	if ttlSeconds <= 0 {
+		return 0, errInvalidTokenTTL
+	}
+
+	if int64(ttlSeconds) > cfg.MaxTTL {
+		return 0, errTokenMaxTTLExceeded
+	}
+
+	return int64(ttlSeconds), nil
+}
+
+// tokenNamePrefix returns the name prefix of the tokens issued by the mount,
+// so that revoke-all can tell them apart from tokens of other mounts sharing
+// the API key.
+func tokenNamePrefix(mountAccessor string) string {
+	if mountAccessor == "" {
+		return keyPrefix + "-"
+	}
+
+	return keyPrefix + "-" + mountAccessor + "-"
+}
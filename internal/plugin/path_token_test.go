@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/stretchr/testify/require"
 )
@@ -25,7 +26,7 @@ func TestToken_Create(t *testing.T) {
 		},
 		"token success": {
 			cfgData: map[string]any{
-				"api_key": "mock",
+				"client_type": "mock",
 			},
 			tokenData: map[string]any{
 				"name": "foo",
@@ -39,7 +40,7 @@ func TestToken_Create(t *testing.T) {
 				logical.ReadOperation,
 			},
 			cfgData: map[string]any{
-				"api_key": "mock",
+				"client_type": "mock",
 			},
 			tokenData: map[string]any{
 				"name": "foo",
@@ -48,17 +49,28 @@ func TestToken_Create(t *testing.T) {
 		},
 		"token with backend fail": {
 			cfgData: map[string]any{
-				"api_key": "mock",
+				"client_type":        "mock",
+				"mock_create_script": "503",
 			},
 			tokenData: map[string]any{
-				"team_id": "force-fail",
+				"name": "foo",
+			},
+			expError: "failed to create token: vercel api unavailable: failure injected by mock client",
+		},
+		"token with backend rate limit": {
+			cfgData: map[string]any{
+				"client_type":        "mock",
+				"mock_create_script": "429",
+			},
+			tokenData: map[string]any{
+				"name": "foo",
 			},
-			expError: "failed to create token",
+			expError: "failed to create token: vercel rate limit exceeded: failure injected by mock client",
 		},
 		"token with conflicting ttl": {
 			cfgData: map[string]any{
-				"api_key": "mock",
-				"max_ttl": 10,
+				"client_type": "mock",
+				"max_ttl":     10,
 			},
 			tokenData: map[string]any{
 				"name": "foo",
@@ -68,7 +80,7 @@ func TestToken_Create(t *testing.T) {
 		},
 		"token with zero ttl": {
 			cfgData: map[string]any{
-				"api_key": "mock",
+				"client_type": "mock",
 			},
 			tokenData: map[string]any{
 				"name": "foo",
@@ -78,7 +90,7 @@ func TestToken_Create(t *testing.T) {
 		},
 		"token with negative ttl": {
 			cfgData: map[string]any{
-				"api_key": "mock",
+				"client_type": "mock",
 			},
 			tokenData: map[string]any{
 				"name": "foo",
@@ -88,38 +100,110 @@ func TestToken_Create(t *testing.T) {
 		},
 		"token with default team id": {
 			cfgData: map[string]any{
-				"api_key":         "mock",
-				"default_team_id": "default-team-id",
+				"client_type":     "mock",
+				"default_team_id": "team_a",
 			},
 			tokenData: map[string]any{
 				"name": "foo",
 			},
 			expDataFields: map[string]any{
 				"bearer_token": "some-bearer-token",
-				"team_id":      "default-team-id",
+				"team_id":      "team_a",
 			},
 		},
 		"token with custom team id": {
 			cfgData: map[string]any{
-				"api_key": "mock",
+				"client_type": "mock",
+			},
+			tokenData: map[string]any{
+				"name":    "foo",
+				"team_id": "team_b",
+			},
+			expDataFields: map[string]any{
+				"bearer_token": "some-bearer-token",
+				"team_id":      "team_b",
+			},
+		},
+		"token with team slug": {
+			cfgData: map[string]any{
+				"client_type": "mock",
 			},
 			tokenData: map[string]any{
 				"name":    "foo",
-				"team_id": "custom-team-id",
+				"team_id": "team-b",
 			},
 			expDataFields: map[string]any{
 				"bearer_token": "some-bearer-token",
-				"team_id":      "custom-team-id",
+				"team_id":      "team_b",
+			},
+		},
+		"token with unknown team": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			tokenData: map[string]any{
+				"name":    "foo",
+				"team_id": "team_unknown",
 			},
+			expError: `team not found or not accessible with the configured API key: "team_unknown"`,
+		},
+		"token with dotenv format": {
+			cfgData: map[string]any{
+				"client_type":        "mock",
+				"default_team_id":    "team_a",
+				"default_project_id": "prj_a",
+			},
+			tokenData: map[string]any{
+				"format": "dotenv",
+			},
+			expDataFields: map[string]any{
+				"project_id": "prj_a",
+				"dotenv": "VERCEL_TOKEN=\"some-bearer-token\"\n" +
+					"VERCEL_ORG_ID=\"team_a\"\nVERCEL_PROJECT_ID=\"prj_a\"\n",
+			},
+		},
+		"token with project json format and project override": {
+			cfgData: map[string]any{
+				"client_type":        "mock",
+				"default_project_id": "prj_a",
+			},
+			tokenData: map[string]any{
+				"team_id":    "team_a",
+				"project_id": "prj_b",
+				"format":     "project_json",
+			},
+			expDataFields: map[string]any{
+				"project_id":   "prj_b",
+				"project_json": "{\n  \"projectId\": \"prj_b\",\n  \"orgId\": \"team_a\"\n}\n",
+			},
+		},
+		"token with project json format without project": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			tokenData: map[string]any{
+				"team_id": "team_a",
+				"format":  "project_json",
+			},
+			expError: "project_json format requires project_id or default_project_id",
+		},
+		"token with invalid format": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			tokenData: map[string]any{
+				"format": "yaml",
+			},
+			expError: "invalid format",
 		},
 		"token with conflicting team ids": {
 			cfgData: map[string]any{
-				"api_key":         "mock",
-				"default_team_id": "default-team-id",
+				"client_type":     "mock",
+				"default_team_id": "team_a",
 			},
 			tokenData: map[string]any{
 				"name":    "foo",
-				"team_id": "custom-team-id",
+				"team_id": "team_b",
 			},
 			expError: "cannot override default_team_id",
 		},
@@ -131,7 +215,7 @@ func TestToken_Create(t *testing.T) {
 			t.Parallel()
 
 			ctx := context.Background()
-			b, storage := newTestBackend(t, tc.disabledOps)
+			b, storage := newTestBackend(t, nil)
 
 			if tc.cfgData != nil {
 				_, err := b.HandleRequest(ctx, &logical.Request{
@@ -143,6 +227,9 @@ func TestToken_Create(t *testing.T) {
 				require.NoError(t, err)
 			}
 
+			// Storage fails once the backend is configured.
+			failStorageOps(storage.(*logical.InmemStorage), tc.disabledOps)
+
 			r, err := b.HandleRequest(ctx, &logical.Request{
 				Storage:   storage,
 				Operation: logical.ReadOperation,
@@ -159,6 +246,9 @@ func TestToken_Create(t *testing.T) {
 				require.Nil(t, r)
 			} else {
 				require.Equal(t, r.Secret.LeaseOptions.TTL, time.Duration(defaultMaxTTL)*time.Second)
+				schema.ValidateResponse(t,
+					schema.GetResponseSchema(t, b.Route(pathPatternToken), logical.ReadOperation), r, true)
+				require.False(t, secretExpiry(r.Secret).IsZero())
 
 				for k, v := range tc.expDataFields {
 					require.Equal(t, r.Data[k], v)
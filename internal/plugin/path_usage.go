@@ -0,0 +1,204 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	pathPatternUsage      = "usage"
+	pathUsageSince        = "since"
+	pathUsageUntil        = "until"
+	pathUsageTeamID       = "team_id"
+	usagePersonalScope    = "personal"
+	pathUsageHelpSynopsis = `
+Report tokens that were never used during their lease.`
+	pathUsageHelpDescription = `
+Token activity is fetched from Vercel when a lease is revoked and kept in the plugin storage
+for 90 days after issuance. The report covers tokens issued within the given time range and
+lists unused tokens per team and requester. Tokens with personal account scope are reported
+under "personal". Supports only read operations.`
+	pathUsageSinceDescription = `
+(Optional) Start of the reporting range, as RFC3339 or Unix seconds. Defaults to the oldest record.`
+	pathUsageUntilDescription = `
+(Optional) End of the reporting range, as RFC3339 or Unix seconds. Defaults to now.`
+	pathUsageTeamIDDescription = `
+(Optional) Only report tokens scoped to this team.`
+)
+
+var (
+	errInvalidUsageRange = errors.New("since must be before until")
+)
+
+func (b *backend) pathUsage() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         pathPatternUsage,
+			HelpSynopsis:    pathUsageHelpSynopsis,
+			HelpDescription: pathUsageHelpDescription,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "token-usage",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				pathUsageSince: {
+					Type:        framework.TypeTime,
+					Description: pathUsageSinceDescription,
+				},
+				pathUsageUntil: {
+					Type:        framework.TypeTime,
+					Description: pathUsageUntilDescription,
+				},
+				pathUsageTeamID: {
+					Type:        framework.TypeString,
+					Description: pathUsageTeamIDDescription,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathUsageRead,
+					Summary:  "Report tokens that were never used during their lease.",
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: http.StatusText(http.StatusOK),
+							Fields:      usageResponseFields(),
+						}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func usageResponseFields() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		pathUsageSince: {
+			Type:        framework.TypeString,
+			Description: "Start of the reporting range.",
+		},
+		pathUsageUntil: {
+			Type:        framework.TypeString,
+			Description: "End of the reporting range.",
+			Required:    true,
+		},
+		"issued": {
+			Type:        framework.TypeInt,
+			Description: "Number of tokens issued within the range.",
+			Required:    true,
+		},
+		"used": {
+			Type:        framework.TypeInt,
+			Description: "Number of revoked tokens that were used.",
+			Required:    true,
+		},
+		"unused": {
+			Type:        framework.TypeInt,
+			Description: "Number of revoked tokens that were never used.",
+			Required:    true,
+		},
+		"pending": {
+			Type:        framework.TypeInt,
+			Description: "Number of tokens with unknown usage, either still leased or with unavailable activity.",
+			Required:    true,
+		},
+		"unused_tokens": {
+			Type:        framework.TypeMap,
+			Description: "IDs of unused tokens keyed by team and requester.",
+			Required:    true,
+		},
+	}
+}
+
+func (b *backend) pathUsageRead(ctx context.Context, req *logical.Request,
+	data *framework.FieldData) (*logical.Response, error) {
+	var since time.Time
+	if v, ok := data.GetOk(pathUsageSince); ok {
+		since, _ = v.(time.Time)
+	}
+
+	until := time.Now().UTC()
+	if v, ok := data.GetOk(pathUsageUntil); ok {
+		until, _ = v.(time.Time)
+	}
+
+	if !since.IsZero() && !since.Before(until) {
+		return nil, errInvalidUsageRange
+	}
+
+	teamID, _ := data.Get(pathUsageTeamID).(string)
+
+	records, err := b.listIssuances(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	var issued, used, unused, pending int
+
+	unusedTokens := make(map[string]map[string][]string)
+
+	for _, r := range records {
+		if r.IssuedAt.Before(since) || !r.IssuedAt.Before(until) {
+			continue
+		}
+
+		if teamID != "" && r.TeamID != teamID {
+			continue
+		}
+
+		issued++
+
+		switch {
+		case !r.UsageKnown:
+			pending++
+		case r.Used:
+			used++
+		default:
+			unused++
+
+			team := r.TeamID
+			if team == "" {
+				team = usagePersonalScope
+			}
+
+			if unusedTokens[team] == nil {
+				unusedTokens[team] = make(map[string][]string)
+			}
+
+			unusedTokens[team][r.requester()] = append(unusedTokens[team][r.requester()], r.TokenID)
+		}
+	}
+
+	out := make(map[string]any, len(unusedTokens))
+
+	for team, byRequester := range unusedTokens {
+		m := make(map[string]any, len(byRequester))
+
+		for requester, ids := range byRequester {
+			sort.Strings(ids)
+			m[requester] = ids
+		}
+
+		out[team] = m
+	}
+
+	respData := map[string]any{
+		pathUsageUntil:  until.Format(time.RFC3339),
+		"issued":        issued,
+		"used":          used,
+		"unused":        unused,
+		"pending":       pending,
+		"unused_tokens": out,
+	}
+
+	if !since.IsZero() {
+		respData[pathUsageSince] = since.Format(time.RFC3339)
+	}
+
+	return &logical.Response{Data: respData}, nil
+}
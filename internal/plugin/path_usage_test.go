@@ -0,0 +1,209 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/client"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/service"
+)
+
+func TestUsage_Read(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"client_type": "mock",
+		},
+	})
+	require.NoError(t, err)
+
+	m := client.NewMockClient()
+	b.svc = service.NewWithClient(m)
+
+	issue := func(displayName, teamID string) string {
+		t.Helper()
+
+		data := map[string]any{}
+		if teamID != "" {
+			data["team_id"] = teamID
+		}
+
+		r, issueErr := b.HandleRequest(ctx, &logical.Request{
+			Storage:     storage,
+			Operation:   logical.ReadOperation,
+			Path:        pathPatternToken,
+			DisplayName: displayName,
+			Data:        data,
+		})
+		require.NoError(t, issueErr)
+
+		id, _ := r.Data[pathTokenID].(string)
+
+		return id
+	}
+
+	revoke := func(id string) {
+		t.Helper()
+
+		_, revokeErr := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.RevokeOperation,
+			Path:      pathPatternToken,
+			Secret: &logical.Secret{
+				InternalData: map[string]any{
+					"secret_type": backendSecretType,
+					pathTokenID:   id,
+				},
+			},
+		})
+		require.NoError(t, revokeErr)
+	}
+
+	used := issue("jwt-repo-a", "team_a")
+	unusedA := issue("jwt-repo-a", "team_a")
+	unusedB := issue("jwt-repo-b", "team_a")
+	personal := issue("jwt-repo-a", "")
+	active := issue("jwt-repo-b", "team_b")
+
+	m.MarkAuthTokenUsed(used, time.Now().Add(time.Minute))
+
+	for _, id := range []string{used, unusedA, unusedB, personal} {
+		revoke(id)
+	}
+
+	cases := map[string]struct {
+		data      map[string]any
+		expCounts map[string]int
+		expUnused map[string]any
+		expError  error
+	}{
+		"all": {
+			expCounts: map[string]int{"issued": 5, "used": 1, "unused": 3, "pending": 1},
+			expUnused: map[string]any{
+				"team_a": map[string]any{
+					"jwt-repo-a": []string{unusedA},
+					"jwt-repo-b": []string{unusedB},
+				},
+				usagePersonalScope: map[string]any{
+					"jwt-repo-a": []string{personal},
+				},
+			},
+		},
+		"team filter": {
+			data: map[string]any{
+				"team_id": "team_b",
+			},
+			expCounts: map[string]int{"issued": 1, "used": 0, "unused": 0, "pending": 1},
+			expUnused: map[string]any{},
+		},
+		"range before issuance": {
+			data: map[string]any{
+				"since": time.Now().Add(-2 * time.Hour).Format(time.RFC3339),
+				"until": time.Now().Add(-time.Hour).Format(time.RFC3339),
+			},
+			expCounts: map[string]int{"issued": 0, "used": 0, "unused": 0, "pending": 0},
+			expUnused: map[string]any{},
+		},
+		"invalid range": {
+			data: map[string]any{
+				"since": time.Now().Format(time.RFC3339),
+				"until": time.Now().Add(-time.Hour).Format(time.RFC3339),
+			},
+			expError: errInvalidUsageRange,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := &logical.Request{
+				Storage:   storage,
+				Operation: logical.ReadOperation,
+				Path:      pathPatternUsage,
+				Data:      tc.data,
+			}
+
+			r, err := b.HandleRequest(ctx, req)
+			if tc.expError != nil {
+				require.ErrorIs(t, err, tc.expError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route(pathPatternUsage), req.Operation), r, true)
+
+			for k, v := range tc.expCounts {
+				require.Equal(t, v, r.Data[k], k)
+			}
+
+			require.Equal(t, tc.expUnused, r.Data["unused_tokens"])
+		})
+	}
+
+	rec, err := b.getIssuance(ctx, storage, active)
+	require.NoError(t, err)
+	require.True(t, rec.RevokedAt.IsZero())
+
+	rec, err = b.getIssuance(ctx, storage, used)
+	require.NoError(t, err)
+	require.True(t, rec.UsageKnown)
+	require.True(t, rec.Used)
+	require.False(t, rec.RevokedAt.IsZero())
+}
+
+func TestUsage_PruneIssuances(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	now := time.Now().UTC()
+
+	require.NoError(t, b.putIssuance(ctx, storage, &issuanceRecord{
+		TokenID:  "old",
+		IssuedAt: now.Add(-issuanceRetention - time.Hour),
+	}))
+	require.NoError(t, b.putIssuance(ctx, storage, &issuanceRecord{
+		TokenID:  "recent",
+		IssuedAt: now.Add(-time.Hour),
+	}))
+
+	require.NoError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+
+	records, err := b.listIssuances(ctx, storage)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, "recent", records[0].TokenID)
+
+	// Records are pruned at most once per interval.
+	require.NoError(t, b.putIssuance(ctx, storage, &issuanceRecord{
+		TokenID:  "old",
+		IssuedAt: now.Add(-issuanceRetention - time.Hour),
+	}))
+	require.NoError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+
+	records, err = b.listIssuances(ctx, storage)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	b.issuancesPrunedAt = now.Add(-issuancePruneInterval)
+	require.NoError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+
+	records, err = b.listIssuances(ctx, storage)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+}
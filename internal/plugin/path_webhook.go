@@ -0,0 +1,334 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+const (
+	// #nosec G101
+	webhookSecretType         = "vercel_webhook"
+	pathPatternWebhooks       = "webhooks"
+	pathWebhookID             = "webhook_id"
+	pathWebhookURL            = "url"
+	pathWebhookEvents         = "events"
+	pathWebhookProjectIDs     = "project_ids"
+	pathWebhookTeamID         = "team_id"
+	pathWebhookSecret         = "secret"
+	pathWebhookURLDescription = `
+HTTPS URL Vercel delivers the webhook events to.`
+	pathWebhookEventsDescription = `
+Events to subscribe to, for example "deployment.created,deployment.succeeded".`
+	pathWebhookProjectIDsDescription = `
+(Optional) Project IDs to receive events for. Defaults to all projects of the team.`
+	pathWebhookTeamIDDescription = `
+(Optional) Team ID or slug the webhook is registered for. Defaults to default_team_id from configuration.
+May be an identity template and is subject to allowed_teams.`
+	pathWebhookTTLDescription = `
+(Optional) TTL for the webhook. Less than or equal to the maximum TTL set in configuration.
+Defaults to maximum TTL. The lease can be renewed by up to the maximum TTL at a time.`
+	pathWebhookHelpSynopsis = `
+Register a Vercel webhook for the lifetime of a lease.`
+	pathWebhookHelpDescription = `
+Registers a Vercel webhook using the configured API key and returns its ID and signing secret
+as a leased secret. The webhook is deleted when the lease is revoked or expires. The lease
+is renewable, by up to max_ttl from configuration at a time and within the maximum lease TTL
+of the mount, so that a webhook can stay registered for longer than max_ttl.
+Webhooks do not expire on Vercel, so they are only cleaned up through lease revocation.
+Supports only update operations.`
+	pathWebhookIDResponseDescription = `
+ID of the registered webhook.`
+	//nolint:gosec
+	pathWebhookSecretResponseDescription = `
+Signing secret of the webhook, used to verify the x-vercel-signature header of deliveries.`
+	pathWebhookTeamIDResponseDescription = `
+Team the webhook is registered for. Empty for webhooks of a personal account.`
+	secretWebhookIDDescription = `
+ID of the registered webhook, used for revocation.`
+)
+
+var (
+	errInvalidWebhookURL    = errors.New("url must be an absolute https URL")
+	errMissingWebhookEvents = errors.New("at least one event is required")
+	errCreateWebhook        = errors.New("failed to create webhook")
+	errRevokeWebhook        = errors.New("failed to revoke webhook")
+)
+
+func (b *backend) pathWebhook() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         pathPatternWebhooks,
+			HelpSynopsis:    pathWebhookHelpSynopsis,
+			HelpDescription: pathWebhookHelpDescription,
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixVercel,
+				OperationSuffix: "webhook",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				pathWebhookURL: {
+					Type:        framework.TypeString,
+					Description: pathWebhookURLDescription,
+					Required:    true,
+				},
+				pathWebhookEvents: {
+					Type:        framework.TypeCommaStringSlice,
+					Description: pathWebhookEventsDescription,
+					Required:    true,
+				},
+				pathWebhookProjectIDs: {
+					Type:        framework.TypeCommaStringSlice,
+					Description: pathWebhookProjectIDsDescription,
+				},
+				pathWebhookTeamID: {
+					Type:        framework.TypeString,
+					Description: pathWebhookTeamIDDescription,
+				},
+				pathTokenTTL: {
+					Type:        framework.TypeDurationSecond,
+					Description: pathWebhookTTLDescription,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback:  traced("vercel.webhook.create", b.pathWebhookWrite),
+					Summary:   "Register a Vercel webhook.",
+					Responses: webhookResponses(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "create",
+					},
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) webhookSecret() *framework.Secret {
+	return &framework.Secret{
+		Type: webhookSecretType,
+		Fields: map[string]*framework.FieldSchema{
+			pathWebhookID: {
+				Type:        framework.TypeString,
+				Description: secretWebhookIDDescription,
+			},
+		},
+		Renew:  traced("vercel.webhook.renew", b.renewWebhook),
+		Revoke: traced("vercel.webhook.revoke", b.revokeWebhook),
+	}
+}
+
+func webhookResponses() map[int][]framework.Response {
+	return map[int][]framework.Response{
+		http.StatusOK: {{
+			Description: http.StatusText(http.StatusOK),
+			Fields: map[string]*framework.FieldSchema{
+				pathWebhookID: {
+					Type:        framework.TypeString,
+					Description: pathWebhookIDResponseDescription,
+					Required:    true,
+				},
+				pathWebhookSecret: {
+					Type:        framework.TypeString,
+					Description: pathWebhookSecretResponseDescription,
+					Required:    true,
+					DisplayAttrs: &framework.DisplayAttributes{
+						Sensitive: true,
+					},
+				},
+				pathWebhookURL: {
+					Type:        framework.TypeString,
+					Description: pathWebhookURLDescription,
+					Required:    true,
+				},
+				pathWebhookEvents: {
+					Type:        framework.TypeCommaStringSlice,
+					Description: pathWebhookEventsDescription,
+					Required:    true,
+				},
+				pathWebhookProjectIDs: {
+					Type:        framework.TypeCommaStringSlice,
+					Description: pathWebhookProjectIDsDescription,
+				},
+				pathWebhookTeamID: {
+					Type:        framework.TypeString,
+					Description: pathWebhookTeamIDResponseDescription,
+					Required:    true,
+				},
+			},
+		}},
+	}
+}
+
+func (b *backend) pathWebhookWrite(ctx context.Context, req *logical.Request,
+	data *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg == nil {
+		return nil, errBackendNotConfigured
+	}
+
+	if err = b.checkLockdown(ctx, req.Storage); err != nil {
+		return nil, err
+	}
+
+	ttl, err := leaseTTL(data, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	hookURL, _ := data.Get(pathWebhookURL).(string)
+	if u, parseErr := url.Parse(hookURL); parseErr != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, errInvalidWebhookURL
+	}
+
+	events, _ := data.Get(pathWebhookEvents).([]string)
+	if len(events) == 0 {
+		return nil, errMissingWebhookEvents
+	}
+
+	projectIDs, _ := data.Get(pathWebhookProjectIDs).([]string)
+
+	rawTeamID, teamIDSet := data.GetOk(pathWebhookTeamID)
+	requestedTeamID, _ := rawTeamID.(string)
+
+	teamID, err := b.resolveTeamID(ctx, req, cfg, requestedTeamID, teamIDSet)
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := b.getService(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	b.Logger().Info("creating webhook", "url", hookURL, "events", events, "ttl", ttl)
+
+	webhookID, secret, err := svc.CreateWebhook(ctx, teamID, hookURL, events, projectIDs)
+	if err != nil {
+		b.Logger().Error("failed to create webhook", "url", hookURL, "error", err)
+
+		return nil, vercelError(errCreateWebhook, err)
+	}
+
+	respData := map[string]any{
+		pathWebhookID:     webhookID,
+		pathWebhookSecret: secret,
+		pathWebhookURL:    hookURL,
+		pathWebhookEvents: events,
+		pathWebhookTeamID: teamID,
+	}
+
+	if len(projectIDs) > 0 {
+		respData[pathWebhookProjectIDs] = projectIDs
+	}
+
+	resp := &logical.Response{
+		Data: respData,
+		Secret: &logical.Secret{
+			InternalData: map[string]any{
+				"secret_type":     webhookSecretType,
+				pathWebhookID:     webhookID,
+				pathWebhookTeamID: teamID,
+			},
+			LeaseOptions: logical.LeaseOptions{
+				TTL:       time.Duration(ttl) * time.Second,
+				Renewable: true,
+			},
+		},
+	}
+
+	if warning := b.recordLease(ctx, req.Storage, cfg, resp.Secret); warning != "" {
+		resp.AddWarning(warning)
+	}
+
+	return resp, nil
+}
+
+// renewWebhook extends the lease of a webhook by up to max_ttl from the
+// configuration at a time, so that a webhook can outlive max_ttl within the
+// maximum lease TTL of the mount. Webhooks do not expire on Vercel, so nothing
+// changes there.
+func (b *backend) renewWebhook(ctx context.Context, req *logical.Request,
+	_ *framework.FieldData) (*logical.Response, error) {
+	if req.Secret == nil {
+		return nil, errInternalDataMissing
+	}
+
+	cfg, err := b.getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg == nil {
+		return nil, errBackendNotConfigured
+	}
+
+	// Renewing a lease that could no longer be revoked would keep the webhook
+	// registered without a way to delete it.
+	if _, err = b.revokeService(ctx, req.Storage, leaseCredentialID(req.Secret)); err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(cfg.MaxTTL) * time.Second
+	if req.Secret.Increment > 0 && req.Secret.Increment < ttl {
+		ttl = req.Secret.Increment
+	}
+
+	resp := &logical.Response{Secret: req.Secret}
+	resp.Secret.TTL = ttl
+	resp.Secret.MaxTTL = 0
+
+	if warning := b.extendLeaseRecord(ctx, req.Storage, req.Secret, ttl); warning != "" {
+		resp.AddWarning(warning)
+	}
+
+	return resp, nil
+}
+
+// revokeWebhook deletes the webhook of a lease. Failures are returned to
+// Vault, which retries the revocation.
+func (b *backend) revokeWebhook(ctx context.Context, req *logical.Request,
+	_ *framework.FieldData) (*logical.Response, error) {
+	if req.Secret == nil {
+		return nil, errInternalDataMissing
+	}
+
+	webhookID, _ := req.Secret.InternalData[pathWebhookID].(string)
+	teamID, _ := req.Secret.InternalData[pathWebhookTeamID].(string)
+
+	if webhookID == "" {
+		return nil, errInternalDataMissing
+	}
+
+	svc, err := b.revokeService(ctx, req.Storage, leaseCredentialID(req.Secret))
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.DeleteWebhook(ctx, webhookID, teamID)
+	if errors.Is(err, vercel.ErrNotFound) {
+		b.Logger().Warn("webhook already deleted from Vercel, treating as revoked", "webhook_id", webhookID)
+		b.deleteLeaseRecord(ctx, req.Storage, req.Secret)
+
+		return &logical.Response{}, nil
+	}
+
+	if err != nil {
+		b.Logger().Error("failed to revoke webhook", "webhook_id", webhookID, "error", err)
+
+		return nil, vercelError(errRevokeWebhook, err)
+	}
+
+	b.deleteLeaseRecord(ctx, req.Storage, req.Secret)
+
+	return &logical.Response{}, nil
+}
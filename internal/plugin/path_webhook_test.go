@@ -0,0 +1,341 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/service"
+)
+
+func TestWebhook_Create(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		cfgData  map[string]any
+		data     map[string]any
+		expData  map[string]any
+		expTTL   time.Duration
+		expError string
+	}{
+		"without backend": {
+			data: map[string]any{
+				"url":    "https://example.com/hook",
+				"events": "deployment.created",
+			},
+			expError: "backend not configured",
+		},
+		"with project filter": {
+			cfgData: map[string]any{
+				"client_type":     "mock",
+				"default_team_id": "team_a",
+				"max_ttl":         600,
+			},
+			data: map[string]any{
+				"url":         "https://example.com/hook",
+				"events":      "deployment.created,deployment.succeeded",
+				"project_ids": "prj_a,prj_b",
+			},
+			expData: map[string]any{
+				"secret":      "mock-webhook-secret",
+				"url":         "https://example.com/hook",
+				"events":      []string{"deployment.created", "deployment.succeeded"},
+				"project_ids": []string{"prj_a", "prj_b"},
+				"team_id":     "team_a",
+			},
+			expTTL: 600 * time.Second,
+		},
+		"without project filter": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			data: map[string]any{
+				"url":    "https://example.com/hook",
+				"events": "deployment.created",
+				"ttl":    60,
+			},
+			expData: map[string]any{
+				"secret":  "mock-webhook-secret",
+				"url":     "https://example.com/hook",
+				"events":  []string{"deployment.created"},
+				"team_id": "",
+			},
+			expTTL: time.Minute,
+		},
+		"http url": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			data: map[string]any{
+				"url":    "http://example.com/hook",
+				"events": "deployment.created",
+			},
+			expError: errInvalidWebhookURL.Error(),
+		},
+		"missing events": {
+			cfgData: map[string]any{
+				"client_type": "mock",
+			},
+			data: map[string]any{
+				"url": "https://example.com/hook",
+			},
+			expError: errMissingWebhookEvents.Error(),
+		},
+		"vercel failure": {
+			cfgData: map[string]any{
+				"client_type":        "mock",
+				"mock_create_script": "400",
+			},
+			data: map[string]any{
+				"url":    "https://example.com/hook",
+				"events": "deployment.created",
+			},
+			expError: "failed to create webhook: vercel rejected the request: failure injected by mock client",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := newTestBackend(t, nil)
+
+			if tc.cfgData != nil {
+				_, err := b.HandleRequest(ctx, &logical.Request{
+					Storage:   storage,
+					Operation: logical.CreateOperation,
+					Path:      pathPatternConfig,
+					Data:      tc.cfgData,
+				})
+				require.NoError(t, err)
+			}
+
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      pathPatternWebhooks,
+				Data:      tc.data,
+			})
+
+			if tc.expError != "" {
+				require.ErrorContains(t, err, tc.expError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotEmpty(t, r.Data["webhook_id"])
+
+			for k, v := range tc.expData {
+				require.Equal(t, v, r.Data[k], k)
+			}
+
+			require.Equal(t, webhookSecretType, r.Secret.InternalData["secret_type"])
+			require.Equal(t, r.Data["webhook_id"], r.Secret.InternalData["webhook_id"])
+			require.NotContains(t, r.Secret.InternalData, "secret")
+			require.Equal(t, tc.expTTL, r.Secret.TTL)
+		})
+	}
+}
+
+func TestWebhook_Revoke(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	s, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{APIKey: "root-key", Teams: []string{"team_a"}})
+	t.Cleanup(ts.Close)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"api_key":         "root-key",
+			"base_url":        ts.URL,
+			"default_team_id": "team_a",
+		},
+	})
+	require.NoError(t, err)
+
+	create := func() *logical.Response {
+		t.Helper()
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      pathPatternWebhooks,
+			Data: map[string]any{
+				"url":    "https://example.com/hook",
+				"events": "deployment.created",
+			},
+		})
+		require.NoError(t, err)
+
+		return r
+	}
+
+	revoke := func(internalData map[string]any) error {
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.RevokeOperation,
+			Path:      pathPatternWebhooks,
+			Secret: &logical.Secret{
+				InternalData: internalData,
+			},
+		})
+
+		return err
+	}
+
+	r := create()
+	hooks := s.Webhooks()
+	require.Len(t, hooks, 1)
+	require.Equal(t, r.Data["webhook_id"], hooks[0].ID)
+	require.Equal(t, "team_a", hooks[0].OwnerID)
+
+	require.NoError(t, revoke(r.Secret.InternalData))
+	require.Empty(t, s.Webhooks())
+
+	// Webhooks deleted outside Vault count as revoked.
+	require.NoError(t, revoke(r.Secret.InternalData))
+
+	require.ErrorIs(t, revoke(map[string]any{"secret_type": webhookSecretType}), errInternalDataMissing)
+
+	// Other failures are returned so that Vault retries the revocation.
+	r = create()
+	b.svc = service.NewWithBaseURL("bogus", ts.URL)
+
+	require.ErrorContains(t, revoke(r.Secret.InternalData), "failed to revoke webhook")
+	require.Len(t, s.Webhooks(), 1)
+}
+
+func TestWebhook_Renew(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data:      map[string]any{"client_type": "mock", "max_ttl": 600},
+	})
+	require.NoError(t, err)
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      pathPatternWebhooks,
+		Data: map[string]any{
+			"url":    "https://example.com/hook",
+			"events": "deployment.created",
+			"ttl":    60,
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, r.Secret.Renewable)
+
+	secret := r.Secret
+
+	renew := func(increment time.Duration) (*logical.Response, error) {
+		s := *secret
+		s.Increment = increment
+
+		return b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.RenewOperation,
+			Path:      pathPatternWebhooks,
+			Secret:    &s,
+		})
+	}
+
+	cases := map[string]struct {
+		increment time.Duration
+		expTTL    time.Duration
+	}{
+		"defaults to max_ttl": {
+			expTTL: 600 * time.Second,
+		},
+		"requested increment": {
+			increment: 120 * time.Second,
+			expTTL:    120 * time.Second,
+		},
+		"increment capped at max_ttl": {
+			increment: time.Hour,
+			expTTL:    600 * time.Second,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := renew(tc.increment)
+			require.NoError(t, err)
+			require.Empty(t, r.Warnings)
+			require.Equal(t, tc.expTTL, r.Secret.TTL)
+		})
+	}
+
+	t.Run("lease record", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := renew(0)
+		require.NoError(t, err)
+		require.Empty(t, r.Warnings)
+
+		records, err := b.listLeaseRecords(ctx, storage)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		require.WithinDuration(t, time.Now().Add(600*time.Second), records[0].ExpiresAt, time.Minute)
+	})
+
+	t.Run("credential gone", func(t *testing.T) {
+		t.Parallel()
+
+		s := *secret
+		s.InternalData = map[string]any{
+			"secret_type":      webhookSecretType,
+			pathWebhookID:      "hook_gone",
+			secretCredentialID: "0123456789abcdef",
+		}
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.RenewOperation,
+			Path:      pathPatternWebhooks,
+			Secret:    &s,
+		})
+		require.ErrorIs(t, err, errRevokeCredentialGone)
+	})
+}
+
+func TestWebhook_RenewWithoutConfig(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.RenewOperation,
+		Path:      pathPatternWebhooks,
+		Secret: &logical.Secret{
+			InternalData: map[string]any{
+				"secret_type": webhookSecretType,
+				pathWebhookID: "hook_a",
+			},
+		},
+	})
+	require.ErrorIs(t, err, errBackendNotConfigured)
+}
@@ -0,0 +1,232 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/client"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/service"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+const (
+	revocationQueuePrefix    = "revocations/pending/"
+	revocationRetryBaseDelay = 30 * time.Second
+	revocationRetryMaxDelay  = time.Hour
+	// revocationMaxPending caps how long entries without a known token expiry,
+	// i.e. from leases issued before the expiry was recorded, are retried.
+	revocationMaxPending = 7 * 24 * time.Hour
+)
+
+var (
+	errQueueRevocation = errors.New("failed to queue token revocation")
+)
+
+// pendingRevocation is a token whose deletion from Vercel failed during lease
+// revocation. It is retried from the periodic function until it succeeds or
+// the token expires on Vercel.
+type pendingRevocation struct {
+	TokenID     string    `json:"token_id"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error"`
+	QueuedAt    time.Time `json:"queued_at"`
+	NextAttempt time.Time `json:"next_attempt"`
+	ExpiresAt   time.Time `json:"expires_at,omitempty"`
+	// CredentialID identifies the configuration the token was issued with.
+	CredentialID string `json:"credential_id,omitempty"`
+}
+
+func revocationQueueKey(tokenID string) string {
+	return revocationQueuePrefix + url.PathEscape(tokenID)
+}
+
+// revocationBackoff returns the delay before the given retry attempt,
+// honouring a longer wait requested by Vercel.
+func revocationBackoff(attempts int, err error) time.Duration {
+	d := revocationRetryBaseDelay
+	for i := 1; i < attempts && d < revocationRetryMaxDelay; i++ {
+		d *= 2
+	}
+
+	if d > revocationRetryMaxDelay {
+		d = revocationRetryMaxDelay
+	}
+
+	var httpErr *vercel.HTTPError
+	if errors.As(err, &httpErr) && httpErr.RetryAfter > d {
+		d = httpErr.RetryAfter
+	}
+
+	return d
+}
+
+// retryableRevocation reports whether a failed token deletion is worth
+// retrying later. Outages, rate limits and timeouts are. Rejected keys and
+// requests are not, and are returned to Vault instead.
+func retryableRevocation(err error) bool {
+	for _, kind := range []error{
+		vercel.ErrUpstream,
+		vercel.ErrRateLimited,
+		client.ErrCircuitOpen,
+		context.DeadlineExceeded,
+	} {
+		if errors.Is(err, kind) {
+			return true
+		}
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
+
+// expiry returns when the entry is dropped from the queue: when the token
+// expires on Vercel, or after revocationMaxPending if the expiry is unknown.
+func (p *pendingRevocation) expiry() time.Time {
+	if !p.ExpiresAt.IsZero() {
+		return p.ExpiresAt
+	}
+
+	return p.QueuedAt.Add(revocationMaxPending)
+}
+
+func (b *backend) queueRevocation(ctx context.Context, s logical.Storage, tokenID, credentialID string,
+	expiresAt time.Time, cause error) error {
+	now := time.Now().UTC()
+	p := &pendingRevocation{
+		TokenID:      tokenID,
+		Attempts:     1,
+		LastError:    cause.Error(),
+		QueuedAt:     now,
+		NextAttempt:  now.Add(revocationBackoff(1, cause)),
+		ExpiresAt:    expiresAt,
+		CredentialID: credentialID,
+	}
+
+	return b.putPendingRevocation(ctx, s, p)
+}
+
+func (b *backend) putPendingRevocation(ctx context.Context, s logical.Storage, p *pendingRevocation) error {
+	e, err := logical.StorageEntryJSON(revocationQueueKey(p.TokenID), p)
+	if err != nil {
+		return err
+	}
+
+	return s.Put(ctx, e)
+}
+
+func (b *backend) listPendingRevocations(ctx context.Context, s logical.Storage) ([]*pendingRevocation, error) {
+	keys, err := s.List(ctx, revocationQueuePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*pendingRevocation, 0, len(keys))
+
+	for _, k := range keys {
+		e, err := s.Get(ctx, revocationQueuePrefix+k)
+		if err != nil {
+			return nil, err
+		}
+
+		if e == nil {
+			continue
+		}
+
+		var p pendingRevocation
+		if err = e.DecodeJSON(&p); err != nil {
+			return nil, err
+		}
+
+		out = append(out, &p)
+	}
+
+	return out, nil
+}
+
+// processRevocationQueue retries due revocations with the credential each
+// token was issued with. Entries are removed once the token is deleted, is
+// already gone from Vercel, or has expired on its own. Entries without a known
+// expiry are given up after revocationMaxPending.
+func (b *backend) processRevocationQueue(ctx context.Context, s logical.Storage) error {
+	pending, err := b.listPendingRevocations(ctx, s)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	now := time.Now().UTC()
+	// services holds the service of each credential, created on first use.
+	services := make(map[string]*service.Service)
+
+	for _, p := range pending {
+		if !now.Before(p.expiry()) {
+			if p.ExpiresAt.IsZero() {
+				b.Logger().Error("giving up on pending revocation of token without known expiry, "+
+					"delete it from Vercel manually", "token_id", p.TokenID, "attempts", p.Attempts,
+					"last_error", p.LastError)
+			} else {
+				b.Logger().Info("dropping pending revocation of expired token", "token_id", p.TokenID)
+			}
+
+			if err = s.Delete(ctx, revocationQueueKey(p.TokenID)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if now.Before(p.NextAttempt) {
+			continue
+		}
+
+		svc, ok := services[p.CredentialID]
+		if !ok {
+			if svc, err = b.revokeService(ctx, s, p.CredentialID); err != nil {
+				b.Logger().Warn("cannot retry pending revocation", "token_id", p.TokenID, "error", err)
+			}
+
+			services[p.CredentialID] = svc
+		}
+
+		if svc == nil {
+			continue
+		}
+
+		_, err = svc.DeleteAuthToken(ctx, p.TokenID)
+		if err == nil || errors.Is(err, vercel.ErrNotFound) {
+			b.Logger().Info("pending revocation completed", "token_id", p.TokenID, "attempts", p.Attempts+1)
+			b.markRevoked(ctx, s, p.TokenID)
+
+			if err = s.Delete(ctx, revocationQueueKey(p.TokenID)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		p.Attempts++
+		p.LastError = err.Error()
+		p.NextAttempt = now.Add(revocationBackoff(p.Attempts, err))
+
+		b.Logger().Warn("pending revocation failed", "token_id", p.TokenID, "attempts", p.Attempts,
+			"next_attempt", p.NextAttempt, "error", err)
+
+		if err = b.putPendingRevocation(ctx, s, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *backend) periodicFunc(ctx context.Context, req *logical.Request) error {
+	return errors.Join(
+		b.processRevocationQueue(ctx, req.Storage),
+		b.pruneIssuances(ctx, req.Storage),
+		b.pruneRevokeOnlyConfig(ctx, req.Storage),
+	)
+}
@@ -0,0 +1,270 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/client"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+func TestRevocationBackoff(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		attempts int
+		err      error
+		exp      time.Duration
+	}{
+		"first attempt": {
+			attempts: 1,
+			err:      errors.New("boom"),
+			exp:      revocationRetryBaseDelay,
+		},
+		"third attempt": {
+			attempts: 3,
+			err:      errors.New("boom"),
+			exp:      4 * revocationRetryBaseDelay,
+		},
+		"capped": {
+			attempts: 100,
+			err:      errors.New("boom"),
+			exp:      revocationRetryMaxDelay,
+		},
+		"retry after": {
+			attempts: 1,
+			err: &vercel.HTTPError{
+				StatusCode: http.StatusTooManyRequests,
+				RetryAfter: 5 * time.Minute,
+			},
+			exp: 5 * time.Minute,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.exp, revocationBackoff(tc.attempts, tc.err))
+		})
+	}
+}
+
+func TestRetryableRevocation(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		err error
+		exp bool
+	}{
+		"upstream":     {&vercel.HTTPError{StatusCode: http.StatusBadGateway}, true},
+		"rate limited": {&vercel.HTTPError{StatusCode: http.StatusTooManyRequests}, true},
+		"circuit open": {&client.CircuitOpenError{RetryAfter: time.Second}, true},
+		"timeout":      {fmt.Errorf("delete: %w", context.DeadlineExceeded), true},
+		"network":      {&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		"invalid key":  {&vercel.HTTPError{StatusCode: http.StatusUnauthorized}, false},
+		"forbidden":    {&vercel.HTTPError{StatusCode: http.StatusForbidden}, false},
+		"bad request":  {&vercel.HTTPError{StatusCode: http.StatusBadRequest}, false},
+		"canceled":     {context.Canceled, false},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.exp, retryableRevocation(tc.err))
+		})
+	}
+}
+
+func TestRevocationQueue_Process(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+
+	cases := map[string]struct {
+		cfgData     map[string]any
+		entry       *pendingRevocation
+		expRemoved  bool
+		expAttempts int
+	}{
+		"retry succeeds": {
+			cfgData: map[string]any{"client_type": "mock"},
+			entry: &pendingRevocation{
+				TokenID:     "foo",
+				Attempts:    1,
+				QueuedAt:    now.Add(-time.Hour),
+				NextAttempt: now.Add(-time.Minute),
+			},
+			expRemoved: true,
+		},
+		"token already gone": {
+			cfgData: map[string]any{"client_type": "mock", "mock_delete_script": "404"},
+			entry: &pendingRevocation{
+				TokenID:     "foo",
+				Attempts:    1,
+				QueuedAt:    now.Add(-time.Hour),
+				NextAttempt: now.Add(-time.Minute),
+			},
+			expRemoved: true,
+		},
+		"retry fails": {
+			cfgData: map[string]any{"client_type": "mock", "mock_delete_script": "503"},
+			entry: &pendingRevocation{
+				TokenID:     "foo",
+				Attempts:    1,
+				QueuedAt:    now.Add(-time.Hour),
+				NextAttempt: now.Add(-time.Minute),
+			},
+			expAttempts: 2,
+		},
+		"not due yet": {
+			cfgData: map[string]any{"client_type": "mock"},
+			entry: &pendingRevocation{
+				TokenID:     "foo",
+				Attempts:    1,
+				QueuedAt:    now.Add(-time.Hour),
+				NextAttempt: now.Add(time.Hour),
+			},
+			expAttempts: 1,
+		},
+		"token expired": {
+			cfgData: map[string]any{"client_type": "mock", "mock_delete_script": "503"},
+			entry: &pendingRevocation{
+				TokenID:     "foo",
+				Attempts:    1,
+				QueuedAt:    now.Add(-time.Hour),
+				NextAttempt: now.Add(-time.Minute),
+				ExpiresAt:   now.Add(-time.Second),
+			},
+			expRemoved: true,
+		},
+		"legacy entry within cap": {
+			cfgData: map[string]any{"client_type": "mock", "mock_delete_script": "503"},
+			entry: &pendingRevocation{
+				TokenID:     "foo",
+				Attempts:    1,
+				QueuedAt:    now.Add(-time.Hour),
+				NextAttempt: now.Add(-time.Minute),
+			},
+			expAttempts: 2,
+		},
+		"legacy entry past cap": {
+			cfgData: map[string]any{"client_type": "mock", "mock_delete_script": "503"},
+			entry: &pendingRevocation{
+				TokenID:     "foo",
+				Attempts:    50,
+				QueuedAt:    now.Add(-revocationMaxPending),
+				NextAttempt: now.Add(-time.Minute),
+			},
+			expRemoved: true,
+		},
+		"credential no longer kept": {
+			cfgData: map[string]any{"client_type": "mock"},
+			entry: &pendingRevocation{
+				TokenID:      "foo",
+				Attempts:     1,
+				QueuedAt:     now.Add(-time.Hour),
+				NextAttempt:  now.Add(-time.Minute),
+				CredentialID: "0123456789abcdef",
+			},
+			expAttempts: 1,
+		},
+		"backend not configured": {
+			entry: &pendingRevocation{
+				TokenID:     "foo",
+				Attempts:    1,
+				QueuedAt:    now.Add(-time.Hour),
+				NextAttempt: now.Add(-time.Minute),
+			},
+			expAttempts: 1,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := newTestBackend(t, nil)
+
+			if tc.cfgData != nil {
+				_, err := b.HandleRequest(ctx, &logical.Request{
+					Storage:   storage,
+					Operation: logical.CreateOperation,
+					Path:      pathPatternConfig,
+					Data:      tc.cfgData,
+				})
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, b.putPendingRevocation(ctx, storage, tc.entry))
+
+			// The periodic function is invoked by Vault through rollback requests.
+			_, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.RollbackOperation,
+			})
+			require.NoError(t, err)
+
+			pending, err := b.listPendingRevocations(ctx, storage)
+			require.NoError(t, err)
+
+			if tc.expRemoved {
+				require.Empty(t, pending)
+
+				return
+			}
+
+			require.Len(t, pending, 1)
+			require.Equal(t, tc.expAttempts, pending[0].Attempts)
+
+			if tc.expAttempts > tc.entry.Attempts {
+				require.NotEmpty(t, pending[0].LastError)
+				require.True(t, pending[0].NextAttempt.After(now))
+			}
+		})
+	}
+}
+
+func TestRevocationQueue_List(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	queuedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, b.putPendingRevocation(ctx, storage, &pendingRevocation{
+		TokenID:     "foo/bar",
+		Attempts:    2,
+		LastError:   "boom",
+		QueuedAt:    queuedAt,
+		NextAttempt: queuedAt.Add(time.Minute),
+	}))
+
+	res, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ListOperation,
+		Path:      "revocations/pending",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo/bar"}, res.Data["keys"])
+
+	info, ok := res.Data["key_info"].(map[string]any)["foo/bar"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, 2, info["attempts"])
+	require.Equal(t, "boom", info["last_error"])
+	require.Equal(t, "2026-01-02T03:04:05Z", info["queued_at"])
+	require.Equal(t, "2026-01-02T03:05:05Z", info["next_attempt"])
+	require.NotContains(t, info, "expires_at")
+}
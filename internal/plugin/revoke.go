@@ -3,10 +3,11 @@ package plugin
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
-	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/service"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
 )
 
 var (
@@ -15,19 +16,15 @@ var (
 )
 
 func (b *backend) Revoke(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
-	cfg, err := b.getConfig(ctx, req.Storage)
-	if err != nil {
-		return nil, err
-	}
-
-	if cfg == nil {
-		return nil, errBackendNotConfigured
+	if req.Secret == nil {
+		return nil, errInternalDataMissing
 	}
 
-	svc := service.NewWithBaseURL(cfg.APIKey, cfg.BaseURL)
+	credentialID := leaseCredentialID(req.Secret)
 
-	if req.Secret == nil {
-		return nil, errInternalDataMissing
+	svc, err := b.revokeService(ctx, req.Storage, credentialID)
+	if err != nil {
+		return nil, err
 	}
 
 	k, ok := req.Secret.InternalData[pathTokenID]
@@ -40,12 +37,52 @@ func (b *backend) Revoke(ctx context.Context, req *logical.Request, _ *framework
 		return nil, errInternalDataMissing
 	}
 
+	b.recordUsage(ctx, req.Storage, svc, ks)
+
 	_, err = svc.DeleteAuthToken(ctx, ks)
+	if errors.Is(err, vercel.ErrNotFound) {
+		b.Logger().Warn("token already deleted from Vercel, treating as revoked", "token_id", ks)
+		b.markRevoked(ctx, req.Storage, ks)
+
+		return &logical.Response{}, nil
+	}
+
+	if err != nil && !retryableRevocation(err) {
+		b.Logger().Error("failed to revoke/delete token from Vercel", "token_id", ks, "error", err)
+
+		return nil, vercelError(errRemoteTokenRevokeFailed, err)
+	}
+
 	if err != nil {
-		b.Logger().Error("failed to revoke/delete token from Vercel", "error", err)
+		b.Logger().Error("failed to revoke/delete token from Vercel, queueing for retry", "error", err)
 
-		return nil, errRemoteTokenRevokeFailed
+		if qerr := b.queueRevocation(ctx, req.Storage, ks, credentialID, secretExpiry(req.Secret), err); qerr != nil {
+			b.Logger().Error("failed to queue token revocation", "error", qerr)
+
+			return nil, vercelError(errRemoteTokenRevokeFailed, err)
+		}
+
+		return &logical.Response{
+			Warnings: []string{
+				vercelError(errRemoteTokenRevokeFailed, err).Error() + ", queued for retry",
+			},
+		}, nil
 	}
 
+	b.markRevoked(ctx, req.Storage, ks)
+
 	return &logical.Response{}, nil
 }
+
+// secretExpiry returns the Vercel-side expiry of a token lease, or the zero
+// time for leases issued before the expiry was recorded.
+func secretExpiry(s *logical.Secret) time.Time {
+	v, _ := s.InternalData[secretExpiresAt].(string)
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
@@ -0,0 +1,270 @@
+package plugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/service"
+)
+
+const (
+	// revokeOnlyConfigPrefix holds the configurations replaced or deleted
+	// while leases issued with them may be outstanding, keyed by credential
+	// ID. They are used only to revoke those leases.
+	revokeOnlyConfigPrefix = "config-revoke-only/"
+	// revokeOnlyGrace is how long a revoke-only configuration is kept past
+	// the longest lease TTL, so that Vault can retry failed revocations.
+	revokeOnlyGrace = time.Hour
+	// secretCredentialID is the lease internal data key of the credential ID
+	// of the configuration that issued the lease.
+	secretCredentialID = "credential_id"
+)
+
+var (
+	errRevokeCredentialGone = errors.New("the configuration that issued the lease is no longer kept, " +
+		"delete the credential from Vercel manually")
+)
+
+// revokeOnlyConfig is a configuration kept after it was replaced or deleted,
+// until every lease issued with it has expired.
+type revokeOnlyConfig struct {
+	Config    backendConfig `json:"config"`
+	RetiredAt time.Time     `json:"retired_at"`
+	ExpiresAt time.Time     `json:"expires_at"`
+}
+
+// credentialID identifies the Vercel credential of the configuration. Leases
+// record it so that they are revoked with the credential that issued them.
+func (c *backendConfig) credentialID() string {
+	h := sha256.Sum256([]byte(c.ClientType + "\x00" + c.BaseURL + "\x00" + c.APIKey))
+
+	return hex.EncodeToString(h[:8])
+}
+
+// leaseCredentialID returns the credential ID recorded on a lease, or an empty
+// string for leases issued before it was recorded.
+func leaseCredentialID(s *logical.Secret) string {
+	id, _ := s.InternalData[secretCredentialID].(string)
+
+	return id
+}
+
+func (b *backend) getRevokeOnlyConfig(ctx context.Context, s logical.Storage,
+	credentialID string) (*revokeOnlyConfig, error) {
+	e, err := s.Get(ctx, revokeOnlyConfigPrefix+credentialID)
+	if err != nil {
+		return nil, errGetConfig
+	}
+
+	if e == nil {
+		return nil, nil
+	}
+
+	var c revokeOnlyConfig
+	if err = e.DecodeJSON(&c); err != nil {
+		return nil, errDecode
+	}
+
+	c.Config.migrateBaseURL()
+
+	return &c, nil
+}
+
+// listRevokeOnlyConfigs returns the revoke-only configurations, most recently
+// retired first.
+func (b *backend) listRevokeOnlyConfigs(ctx context.Context, s logical.Storage) ([]*revokeOnlyConfig, error) {
+	keys, err := s.List(ctx, revokeOnlyConfigPrefix)
+	if err != nil {
+		return nil, errGetConfig
+	}
+
+	out := make([]*revokeOnlyConfig, 0, len(keys))
+
+	for _, k := range keys {
+		c, err := b.getRevokeOnlyConfig(ctx, s, k)
+		if err != nil {
+			return nil, err
+		}
+
+		if c != nil {
+			out = append(out, c)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].RetiredAt.After(out[j].RetiredAt)
+	})
+
+	return out, nil
+}
+
+// putRevokeOnlyConfig keeps cfg for revocations until its leases have expired.
+// A configuration already kept for the same credential is kept at least as
+// long as before.
+func (b *backend) putRevokeOnlyConfig(ctx context.Context, s logical.Storage,
+	cfg *backendConfig) (*revokeOnlyConfig, error) {
+	now := time.Now().UTC()
+	c := &revokeOnlyConfig{
+		Config:    *cfg,
+		RetiredAt: now,
+		ExpiresAt: now.Add(time.Duration(cfg.MaxTTL)*time.Second + revokeOnlyGrace),
+	}
+
+	prev, err := b.getRevokeOnlyConfig(ctx, s, cfg.credentialID())
+	if err != nil {
+		return nil, err
+	}
+
+	if prev != nil && prev.ExpiresAt.After(c.ExpiresAt) {
+		c.ExpiresAt = prev.ExpiresAt
+	}
+
+	e, err := logical.StorageEntryJSON(revokeOnlyConfigPrefix+cfg.credentialID(), c)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.Put(ctx, e); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// revokeService returns the service revoking the leases issued with the given
+// credential: the one of the current configuration if it holds the credential,
+// or one created for the revoke-only configuration kept for it. Leases without
+// a recorded credential use the current configuration or, once it has been
+// deleted, the most recently retired one.
+func (b *backend) revokeService(ctx context.Context, s logical.Storage,
+	credentialID string) (*service.Service, error) {
+	cfg, err := b.getConfig(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg != nil && (credentialID == "" || credentialID == cfg.credentialID()) {
+		return b.getService(cfg)
+	}
+
+	var retired *revokeOnlyConfig
+
+	if credentialID != "" {
+		if retired, err = b.getRevokeOnlyConfig(ctx, s, credentialID); err != nil {
+			return nil, err
+		}
+
+		if retired == nil {
+			return nil, errRevokeCredentialGone
+		}
+	} else {
+		all, err := b.listRevokeOnlyConfigs(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(all) == 0 {
+			return nil, errBackendNotConfigured
+		}
+
+		retired = all[0]
+	}
+
+	return b.newService(&retired.Config)
+}
+
+// outstandingLeases returns the number of leases issued by the plugin that
+// have not been revoked yet, including token revocations still being retried.
+// Only leases issued with the given credential are counted, or every lease if
+// it is empty.
+func (b *backend) outstandingLeases(ctx context.Context, s logical.Storage, credentialID string) (int, error) {
+	records, err := b.listIssuances(ctx, s)
+	if err != nil {
+		return 0, err
+	}
+
+	leases, err := b.listLeaseRecords(ctx, s)
+	if err != nil {
+		return 0, err
+	}
+
+	pending, err := b.listPendingRevocations(ctx, s)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	// Leases recorded without a credential ID may belong to any credential.
+	issuedWith := func(id string) bool {
+		return credentialID == "" || id == "" || id == credentialID
+	}
+	ids := make(map[string]bool, len(pending))
+
+	for _, p := range pending {
+		if issuedWith(p.CredentialID) {
+			ids[p.TokenID] = true
+		}
+	}
+
+	for _, r := range records {
+		if !issuedWith(r.CredentialID) || !r.RevokedAt.IsZero() {
+			continue
+		}
+
+		if r.ExpiresAt.IsZero() || now.Before(r.ExpiresAt) {
+			ids[r.TokenID] = true
+		}
+	}
+
+	n := len(ids)
+
+	for _, l := range leases {
+		if issuedWith(l.CredentialID) && now.Before(l.ExpiresAt) {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// pruneRevokeOnlyConfig deletes the revoke-only configurations once the
+// leases issued with them have expired and no revocation is being retried
+// with them.
+func (b *backend) pruneRevokeOnlyConfig(ctx context.Context, s logical.Storage) error {
+	retired, err := b.listRevokeOnlyConfigs(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	for _, c := range retired {
+		if now.Before(c.ExpiresAt) {
+			continue
+		}
+
+		id := c.Config.credentialID()
+
+		outstanding, err := b.outstandingLeases(ctx, s, id)
+		if err != nil {
+			return err
+		}
+
+		if outstanding > 0 {
+			continue
+		}
+
+		b.Logger().Info("deleting revoke-only configuration", "credential_id", id, "retired_at", c.RetiredAt)
+
+		if err = s.Delete(ctx, revokeOnlyConfigPrefix+id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,348 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
+)
+
+func TestConfig_DeleteWithOutstandingTokens(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data:      map[string]any{"client_type": "mock"},
+	})
+	require.NoError(t, err)
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternToken,
+		Data:      map[string]any{"name": "foo"},
+	})
+	require.NoError(t, err)
+
+	secret := r.Secret
+
+	deleteConfig := func(data map[string]any) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.DeleteOperation,
+			Path:      pathPatternConfig,
+			Data:      data,
+		})
+	}
+
+	_, err = deleteConfig(nil)
+	require.ErrorIs(t, err, errLeasesOutstanding)
+	require.ErrorContains(t, err, "1 outstanding")
+
+	cfg, err := b.getConfig(ctx, storage)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	credentialID := cfg.credentialID()
+
+	r, err = deleteConfig(map[string]any{"force": true})
+	require.NoError(t, err)
+	require.Len(t, r.Warnings, 1)
+	require.Contains(t, r.Warnings[0], "1 leases are outstanding")
+
+	cfg, err = b.getConfig(ctx, storage)
+	require.NoError(t, err)
+	require.Nil(t, cfg)
+
+	// The lease is still revoked with the deleted configuration.
+	r, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.RevokeOperation,
+		Path:      pathPatternToken,
+		Secret:    secret,
+	})
+	require.NoError(t, err)
+	require.Empty(t, r.Warnings)
+
+	n, err := b.outstandingLeases(ctx, storage, "")
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// Issuance stays disabled until the backend is configured again.
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternToken,
+		Data:      map[string]any{"name": "foo"},
+	})
+	require.ErrorIs(t, err, errBackendNotConfigured)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data:      map[string]any{"client_type": "mock"},
+	})
+	require.NoError(t, err)
+
+	// Writing a configuration does not drop the revoke-only one.
+	revokeOnly, err := b.getRevokeOnlyConfig(ctx, storage, credentialID)
+	require.NoError(t, err)
+	require.NotNil(t, revokeOnly)
+}
+
+func TestConfig_DeleteWithOutstandingLeases(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		path      string
+		operation logical.Operation
+		data      map[string]any
+	}{
+		"token": {
+			path:      pathPatternToken,
+			operation: logical.ReadOperation,
+		},
+		"protection bypass": {
+			path:      pathTestProtectionBypass,
+			operation: logical.ReadOperation,
+		},
+		"edge config token": {
+			path:      pathTestEdgeConfigToken,
+			operation: logical.ReadOperation,
+		},
+		"webhook": {
+			path:      pathPatternWebhooks,
+			operation: logical.UpdateOperation,
+			data: map[string]any{
+				"url":    "https://example.com/hook",
+				"events": "deployment.created",
+			},
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			b, storage := newTestBackend(t, nil)
+
+			_, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{APIKey: "root-key", Teams: []string{"team_a"}})
+			t.Cleanup(ts.Close)
+
+			_, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.CreateOperation,
+				Path:      pathPatternConfig,
+				Data: map[string]any{
+					"api_key":         "root-key",
+					"base_url":        ts.URL,
+					"default_team_id": "team_a",
+				},
+			})
+			require.NoError(t, err)
+
+			r, err := b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: tc.operation,
+				Path:      tc.path,
+				Data:      tc.data,
+			})
+			require.NoError(t, err)
+			require.Empty(t, r.Warnings)
+
+			deleteConfig := func() error {
+				_, err := b.HandleRequest(ctx, &logical.Request{
+					Storage:   storage,
+					Operation: logical.DeleteOperation,
+					Path:      pathPatternConfig,
+				})
+
+				return err
+			}
+
+			err = deleteConfig()
+			require.ErrorIs(t, err, errLeasesOutstanding)
+			require.ErrorContains(t, err, "1 outstanding")
+
+			_, err = b.HandleRequest(ctx, &logical.Request{
+				Storage:   storage,
+				Operation: logical.RevokeOperation,
+				Path:      tc.path,
+				Secret:    r.Secret,
+			})
+			require.NoError(t, err)
+			require.NoError(t, deleteConfig())
+		})
+	}
+}
+
+func TestConfig_ReplaceCredential(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	oldServer, oldTS := fakevercel.NewHTTPTestServer(fakevercel.Config{APIKey: "old-key", Teams: []string{"team_a"}})
+	t.Cleanup(oldTS.Close)
+
+	newServer, newTS := fakevercel.NewHTTPTestServer(fakevercel.Config{APIKey: "new-key", Teams: []string{"team_a"}})
+	t.Cleanup(newTS.Close)
+
+	writeConfig := func(apiKey, baseURL string) {
+		t.Helper()
+
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.CreateOperation,
+			Path:      pathPatternConfig,
+			Data: map[string]any{
+				"api_key":         apiKey,
+				"base_url":        baseURL,
+				"default_team_id": "team_a",
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	issue := func(path string) *logical.Secret {
+		t.Helper()
+
+		r, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      path,
+		})
+		require.NoError(t, err)
+
+		return r.Secret
+	}
+
+	revoke := func(path string, secret *logical.Secret) error {
+		_, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.RevokeOperation,
+			Path:      path,
+			Secret:    secret,
+		})
+
+		return err
+	}
+
+	writeConfig("old-key", oldTS.URL)
+
+	oldCfg, err := b.getConfig(ctx, storage)
+	require.NoError(t, err)
+
+	token := issue(pathPatternToken)
+	bypass := issue(pathTestProtectionBypass)
+	require.Equal(t, oldCfg.credentialID(), leaseCredentialID(token))
+	require.Len(t, oldServer.Tokens(), 1)
+	require.Len(t, oldServer.ProtectionBypasses("prj_a"), 1)
+
+	// Rotating the key keeps the old configuration for the leases issued with it.
+	writeConfig("new-key", newTS.URL)
+	writeConfig("new-key", newTS.URL)
+
+	n, err := b.outstandingLeases(ctx, storage, oldCfg.credentialID())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	require.NoError(t, revoke(pathPatternToken, token))
+	require.NoError(t, revoke(pathTestProtectionBypass, bypass))
+	require.Empty(t, oldServer.Tokens())
+	require.Empty(t, oldServer.ProtectionBypasses("prj_a"))
+
+	n, err = b.outstandingLeases(ctx, storage, oldCfg.credentialID())
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// Leases issued before the credential was recorded use the current configuration.
+	token = issue(pathPatternToken)
+	require.Len(t, newServer.Tokens(), 1)
+
+	delete(token.InternalData, secretCredentialID)
+	require.NoError(t, revoke(pathPatternToken, token))
+	require.Empty(t, newServer.Tokens())
+
+	// Leases of credentials no longer kept are not revoked with another one.
+	token = issue(pathPatternToken)
+	token.InternalData[secretCredentialID] = "0123456789abcdef"
+	require.ErrorIs(t, revoke(pathPatternToken, token), errRevokeCredentialGone)
+	require.Len(t, newServer.Tokens(), 1)
+}
+
+func TestRevokeOnlyConfig_Prune(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	cfg := &backendConfig{ClientType: clientTypeMock, MaxTTL: 60}
+	id := cfg.credentialID()
+
+	c, err := b.putRevokeOnlyConfig(ctx, storage, cfg)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Minute+revokeOnlyGrace), c.ExpiresAt, time.Minute)
+
+	got, err := b.getRevokeOnlyConfig(ctx, storage, id)
+	require.NoError(t, err)
+	require.Equal(t, cfg, &got.Config)
+
+	// Kept until the leases issued with it have expired.
+	require.NoError(t, b.pruneRevokeOnlyConfig(ctx, storage))
+
+	got, err = b.getRevokeOnlyConfig(ctx, storage, id)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	c.ExpiresAt = time.Now().Add(-time.Minute)
+
+	e, err := logical.StorageEntryJSON(revokeOnlyConfigPrefix+id, c)
+	require.NoError(t, err)
+	require.NoError(t, storage.Put(ctx, e))
+
+	// Kept while revocations are being retried with it.
+	require.NoError(t, b.putPendingRevocation(ctx, storage, &pendingRevocation{
+		TokenID:      "tok_a",
+		NextAttempt:  time.Now().Add(time.Hour),
+		CredentialID: id,
+	}))
+	require.NoError(t, b.pruneRevokeOnlyConfig(ctx, storage))
+
+	got, err = b.getRevokeOnlyConfig(ctx, storage, id)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	require.NoError(t, storage.Delete(ctx, revocationQueueKey("tok_a")))
+
+	// Kept while leases issued with it are outstanding.
+	require.NoError(t, b.putLeaseRecord(ctx, storage, &leaseRecord{
+		ID:           "lease_a",
+		SecretType:   webhookSecretType,
+		CredentialID: id,
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}))
+	require.NoError(t, b.pruneRevokeOnlyConfig(ctx, storage))
+
+	got, err = b.getRevokeOnlyConfig(ctx, storage, id)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	require.NoError(t, storage.Delete(ctx, leaseRecordPrefix+"lease_a"))
+	require.NoError(t, b.pruneRevokeOnlyConfig(ctx, storage))
+
+	got, err = b.getRevokeOnlyConfig(ctx, storage, id)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
@@ -3,6 +3,7 @@ package plugin
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/stretchr/testify/require"
@@ -17,6 +18,7 @@ func TestToken_Revoke(t *testing.T) {
 		tokenData    map[string]any
 		internalData map[string]any
 		expError     string
+		expQueued    string
 	}{
 		"token revocation without backend": {
 			expError: "backend not configured",
@@ -29,7 +31,7 @@ func TestToken_Revoke(t *testing.T) {
 		},
 		"token revocation success": {
 			cfgData: map[string]any{
-				"api_key": "mock",
+				"client_type": "mock",
 			},
 			tokenData: map[string]any{
 				"name": "foo",
@@ -44,11 +46,50 @@ func TestToken_Revoke(t *testing.T) {
 				"secret_type": backendSecretType,
 				"token_id":    "foo",
 			},
-			expError: "failed to revoke token",
+			expQueued: "failed to revoke token: unexpected error from vercel api, queued for retry",
+		},
+		"token revocation rate limited": {
+			cfgData: map[string]any{
+				"client_type":        "mock",
+				"mock_delete_script": "429",
+			},
+			tokenData: map[string]any{
+				"name": "foo",
+			},
+			expQueued: "failed to revoke token: vercel rate limit exceeded: failure injected by mock client, queued for retry",
+		},
+		"token revocation invalid api key": {
+			cfgData: map[string]any{
+				"client_type":        "mock",
+				"mock_delete_script": "401",
+			},
+			tokenData: map[string]any{
+				"name": "foo",
+			},
+			expError: "failed to revoke token: vercel rejected the api key: failure injected by mock client",
+		},
+		"token revocation already deleted": {
+			cfgData: map[string]any{
+				"client_type":        "mock",
+				"mock_delete_script": "404",
+			},
+			tokenData: map[string]any{
+				"name": "foo",
+			},
+		},
+		"token revocation forbidden": {
+			cfgData: map[string]any{
+				"client_type":        "mock",
+				"mock_delete_script": "403",
+			},
+			tokenData: map[string]any{
+				"name": "foo",
+			},
+			expError: "failed to revoke token: vercel denied access: failure injected by mock client",
 		},
 		"token revocation empty token id": {
 			cfgData: map[string]any{
-				"api_key": "mock",
+				"client_type": "mock",
 			},
 			tokenData: map[string]any{
 				"name": "foo",
@@ -61,7 +102,7 @@ func TestToken_Revoke(t *testing.T) {
 		},
 		"token revocation internal data fail": {
 			cfgData: map[string]any{
-				"api_key": "mock",
+				"client_type": "mock",
 			},
 			tokenData: map[string]any{
 				"name": "foo",
@@ -73,7 +114,7 @@ func TestToken_Revoke(t *testing.T) {
 		},
 		"token revocation non-string token id": {
 			cfgData: map[string]any{
-				"api_key": "mock",
+				"client_type": "mock",
 			},
 			tokenData: map[string]any{
 				"name": "foo",
@@ -140,7 +181,72 @@ func TestToken_Revoke(t *testing.T) {
 			if tc.expError != "" {
 				require.EqualError(t, err, tc.expError)
 				require.Nil(t, r)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			pending, err := b.listPendingRevocations(ctx, storage)
+			require.NoError(t, err)
+
+			if tc.expQueued != "" {
+				require.Equal(t, []string{tc.expQueued}, r.Warnings)
+				require.Len(t, pending, 1)
+				require.Equal(t, id["token_id"], pending[0].TokenID)
+			} else {
+				require.Empty(t, pending)
 			}
 		})
 	}
 }
+
+func TestToken_RevokeMarksIssuance(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data:      map[string]any{"client_type": "mock", "mock_delete_script": "503"},
+	})
+	require.NoError(t, err)
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternToken,
+		Data:      map[string]any{"name": "foo"},
+	})
+	require.NoError(t, err)
+
+	tokenID, _ := r.Data["token_id"].(string)
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Storage:   storage,
+		Operation: logical.RevokeOperation,
+		Path:      pathPatternToken,
+		Secret:    r.Secret,
+	})
+	require.NoError(t, err)
+
+	// The token is queued, not deleted, so it is not revoked yet.
+	rec, err := b.getIssuance(ctx, storage, tokenID)
+	require.NoError(t, err)
+	require.True(t, rec.RevokedAt.IsZero())
+
+	pending, err := b.listPendingRevocations(ctx, storage)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	pending[0].NextAttempt = time.Now().Add(-time.Minute)
+	require.NoError(t, b.putPendingRevocation(ctx, storage, pending[0]))
+	require.NoError(t, b.processRevocationQueue(ctx, storage))
+
+	rec, err = b.getIssuance(ctx, storage, tokenID)
+	require.NoError(t, err)
+	require.False(t, rec.RevokedAt.IsZero())
+}
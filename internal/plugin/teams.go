@@ -0,0 +1,150 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+const (
+	// teamCacheTTL is how long the teams of the root API key are cached.
+	teamCacheTTL = 5 * time.Minute
+	// teamCacheMinRefresh is the minimum age of the cache before a lookup of
+	// an unknown team lists the teams again, so that teams the API key
+	// joined recently are found without flooding Vercel with list requests.
+	teamCacheMinRefresh = 30 * time.Second
+	// teamIDPrefix starts every Vercel team ID, telling IDs apart from slugs.
+	teamIDPrefix = "team_"
+)
+
+var (
+	errUnknownTeam = errors.New("team not found or not accessible with the configured API key")
+	errListTeams   = errors.New("failed to list teams")
+)
+
+// teamCache holds the teams the root API key has access to.
+type teamCache struct {
+	teams     []vercel.Team
+	fetchedAt time.Time
+}
+
+// find returns the team with the given ID or slug.
+func (c *teamCache) find(ref string) (vercel.Team, bool) {
+	for _, t := range c.teams {
+		if t.ID == ref || t.Slug == ref {
+			return t, true
+		}
+	}
+
+	return vercel.Team{}, false
+}
+
+// listTeams returns the teams of the root API key. Cached teams are returned
+// unless they are older than maxAge.
+func (b *backend) listTeams(ctx context.Context, cfg *backendConfig, maxAge time.Duration) (*teamCache, error) {
+	b.lock.RLock()
+	cached := b.teams
+	b.lock.RUnlock()
+
+	if cached != nil && time.Since(cached.fetchedAt) < maxAge {
+		return cached, nil
+	}
+
+	svc, err := b.getService(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := svc.ListTeams(ctx)
+	if err != nil {
+		b.Logger().Error("failed to list teams", "error", err)
+
+		return nil, vercelError(errListTeams, err)
+	}
+
+	c := &teamCache{teams: teams, fetchedAt: time.Now()}
+
+	b.lock.Lock()
+	b.teams = c
+	b.lock.Unlock()
+
+	return c, nil
+}
+
+// lookupTeam returns the team with the given ID or slug. Unknown teams are
+// looked up again once the cache is older than teamCacheMinRefresh. Team IDs
+// are used as given when the teams cannot be listed, so that issuance does not
+// depend on the teams endpoint.
+func (b *backend) lookupTeam(ctx context.Context, cfg *backendConfig, ref string) (vercel.Team, error) {
+	c, err := b.listTeams(ctx, cfg, teamCacheTTL)
+	if err != nil {
+		return unlistedTeam(ref, err)
+	}
+
+	if t, ok := c.find(ref); ok {
+		return t, nil
+	}
+
+	if time.Since(c.fetchedAt) >= teamCacheMinRefresh {
+		c, err = b.listTeams(ctx, cfg, 0)
+		if err != nil {
+			return unlistedTeam(ref, err)
+		}
+
+		if t, ok := c.find(ref); ok {
+			return t, nil
+		}
+	}
+
+	return vercel.Team{}, fmt.Errorf("%w: %q", errUnknownTeam, ref)
+}
+
+// unlistedTeam returns the team for ref when the teams could not be listed:
+// team IDs are used as given, while slugs cannot be resolved.
+func unlistedTeam(ref string, err error) (vercel.Team, error) {
+	if strings.HasPrefix(ref, teamIDPrefix) {
+		return vercel.Team{ID: ref}, nil
+	}
+
+	return vercel.Team{}, err
+}
+
+// resolveDefaultTeam replaces a default_team_id given as slug with the team
+// ID, so that issuance does not need to resolve it. Unknown teams are
+// rejected. If the teams cannot be listed the slug is kept, and a warning is
+// returned.
+func (b *backend) resolveDefaultTeam(ctx context.Context, cfg *backendConfig) (string, error) {
+	ref := cfg.DefaultTeamID
+	if ref == "" || strings.HasPrefix(ref, teamIDPrefix) {
+		return "", nil
+	}
+
+	svc, err := b.newService(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	teams, err := svc.ListTeams(ctx)
+	if err != nil {
+		b.Logger().Warn("failed to list teams, storing default_team_id as given", "default_team_id", ref,
+			"error", err)
+
+		return fmt.Sprintf("default_team_id %q could not be resolved to a team ID and is stored as given: %s",
+			ref, vercelError(errListTeams, err)), nil
+	}
+
+	c := &teamCache{teams: teams}
+
+	t, ok := c.find(ref)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", errUnknownTeam, ref)
+	}
+
+	cfg.DefaultTeamID = t.ID
+
+	return "", nil
+}
@@ -0,0 +1,101 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	tokenFormatAuthJSON    = "auth_json"
+	tokenFormatProjectJSON = "project_json"
+	tokenFormatDotenv      = "dotenv"
+
+	vercelCLIAuthNote = "This is your Vercel credentials file. DO NOT SHARE!"
+	vercelCLIAuthDocs = "https://vercel.com/docs/projects/project-configuration/global-configuration#auth.json"
+)
+
+var (
+	errInvalidTokenFormat     = errors.New("invalid format")
+	errProjectFormatNoProject = errors.New("project_json format requires project_id or default_project_id")
+	errProjectFormatNoTeam    = errors.New("project_json format requires team_id or default_team_id")
+)
+
+var tokenFormats = []any{tokenFormatAuthJSON, tokenFormatProjectJSON, tokenFormatDotenv}
+
+type tokenCredentials struct {
+	BearerToken string
+	TeamID      string
+	ProjectID   string
+}
+
+// validateTokenFormat checks a requested format before a token is created,
+// so that a token is never issued for a request that cannot be rendered.
+func validateTokenFormat(format string, c tokenCredentials) error {
+	switch format {
+	case "", tokenFormatAuthJSON, tokenFormatDotenv:
+		return nil
+	case tokenFormatProjectJSON:
+		if c.ProjectID == "" {
+			return errProjectFormatNoProject
+		}
+
+		if c.TeamID == "" {
+			return errProjectFormatNoTeam
+		}
+
+		return nil
+	default:
+		return errInvalidTokenFormat
+	}
+}
+
+// renderTokenFormat renders the issued token in the given format.
+func renderTokenFormat(format string, c tokenCredentials) (string, error) {
+	switch format {
+	case tokenFormatAuthJSON:
+		return marshalIndent(struct {
+			Note  string `json:"// Note"`
+			Docs  string `json:"// Docs"`
+			Token string `json:"token"`
+		}{
+			Note:  vercelCLIAuthNote,
+			Docs:  vercelCLIAuthDocs,
+			Token: c.BearerToken,
+		})
+	case tokenFormatProjectJSON:
+		return marshalIndent(struct {
+			ProjectID string `json:"projectId"`
+			OrgID     string `json:"orgId"`
+		}{
+			ProjectID: c.ProjectID,
+			OrgID:     c.TeamID,
+		})
+	case tokenFormatDotenv:
+		var sb strings.Builder
+
+		fmt.Fprintf(&sb, "VERCEL_TOKEN=%q\n", c.BearerToken)
+
+		if c.TeamID != "" {
+			fmt.Fprintf(&sb, "VERCEL_ORG_ID=%q\n", c.TeamID)
+		}
+
+		if c.ProjectID != "" {
+			fmt.Fprintf(&sb, "VERCEL_PROJECT_ID=%q\n", c.ProjectID)
+		}
+
+		return sb.String(), nil
+	default:
+		return "", errInvalidTokenFormat
+	}
+}
+
+func marshalIndent(v any) (string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(b) + "\n", nil
+}
@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenFormat_Validate(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		format   string
+		creds    tokenCredentials
+		expError error
+	}{
+		"no format":         {},
+		"auth json":         {format: tokenFormatAuthJSON},
+		"dotenv":            {format: tokenFormatDotenv},
+		"unknown":           {format: "yaml", expError: errInvalidTokenFormat},
+		"project json":      {format: tokenFormatProjectJSON, creds: tokenCredentials{TeamID: "t", ProjectID: "p"}},
+		"project json team": {format: tokenFormatProjectJSON, creds: tokenCredentials{ProjectID: "p"}, expError: errProjectFormatNoTeam},
+		"project json proj": {format: tokenFormatProjectJSON, creds: tokenCredentials{TeamID: "t"}, expError: errProjectFormatNoProject},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateTokenFormat(tc.format, tc.creds)
+			if tc.expError != nil {
+				require.ErrorIs(t, err, tc.expError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTokenFormat_Render(t *testing.T) {
+	t.Parallel()
+
+	creds := tokenCredentials{
+		BearerToken: "bearer",
+		TeamID:      "team_a",
+		ProjectID:   "prj_a",
+	}
+
+	t.Run("auth json", func(t *testing.T) {
+		t.Parallel()
+
+		out, err := renderTokenFormat(tokenFormatAuthJSON, creds)
+		require.NoError(t, err)
+
+		var m map[string]string
+
+		require.NoError(t, json.Unmarshal([]byte(out), &m))
+		require.Equal(t, "bearer", m["token"])
+		require.Equal(t, vercelCLIAuthNote, m["// Note"])
+	})
+
+	t.Run("project json", func(t *testing.T) {
+		t.Parallel()
+
+		out, err := renderTokenFormat(tokenFormatProjectJSON, creds)
+		require.NoError(t, err)
+
+		var m map[string]string
+
+		require.NoError(t, json.Unmarshal([]byte(out), &m))
+		require.Equal(t, map[string]string{"projectId": "prj_a", "orgId": "team_a"}, m)
+	})
+
+	t.Run("dotenv without team and project", func(t *testing.T) {
+		t.Parallel()
+
+		out, err := renderTokenFormat(tokenFormatDotenv, tokenCredentials{BearerToken: "a\"b"})
+		require.NoError(t, err)
+		require.Equal(t, "VERCEL_TOKEN=\"a\\\"b\"\n", out)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := renderTokenFormat("yaml", creds)
+		require.ErrorIs(t, err, errInvalidTokenFormat)
+	})
+}
@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"context"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traced wraps an operation callback in a span named after the operation.
+// Storage calls made by the callback get child spans of their own, so time
+// spent in Vault storage can be told apart from time spent in the Vercel API.
+func traced(name string, fn framework.OperationFunc) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+		ctx, span := tracing.Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				tracing.AttrVaultRequestID.String(req.ID),
+				tracing.AttrVaultPath.String(req.Path),
+				tracing.AttrVaultOperation.String(string(req.Operation)),
+				tracing.AttrVaultMountPoint.String(req.MountPoint),
+			),
+		)
+
+		if req.Storage != nil {
+			if _, ok := req.Storage.(*tracedStorage); !ok {
+				req.Storage = &tracedStorage{Storage: req.Storage}
+			}
+		}
+
+		resp, err := fn(ctx, req, data)
+
+		spanErr := err
+		if spanErr == nil && resp != nil && resp.IsError() {
+			spanErr = resp.Error()
+		}
+
+		tracing.End(span, spanErr)
+
+		return resp, err
+	}
+}
+
+// tracedStorage creates a span for every storage call.
+type tracedStorage struct {
+	logical.Storage
+}
+
+func startStorageSpan(ctx context.Context, op, key string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "vault.storage."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("vault.storage.key", key)),
+	)
+}
+
+func (s *tracedStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	ctx, span := startStorageSpan(ctx, "list", prefix)
+	keys, err := s.Storage.List(ctx, prefix)
+	tracing.End(span, err)
+
+	return keys, err
+}
+
+func (s *tracedStorage) Get(ctx context.Context, key string) (*logical.StorageEntry, error) {
+	ctx, span := startStorageSpan(ctx, "get", key)
+	e, err := s.Storage.Get(ctx, key)
+	tracing.End(span, err)
+
+	return e, err
+}
+
+func (s *tracedStorage) Put(ctx context.Context, e *logical.StorageEntry) error {
+	ctx, span := startStorageSpan(ctx, "put", e.Key)
+	err := s.Storage.Put(ctx, e)
+	tracing.End(span, err)
+
+	return err
+}
+
+func (s *tracedStorage) Delete(ctx context.Context, key string) error {
+	ctx, span := startStorageSpan(ctx, "delete", key)
+	err := s.Storage.Delete(ctx, key)
+	tracing.End(span, err)
+
+	return err
+}
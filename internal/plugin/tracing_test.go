@@ -0,0 +1,143 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	testSpanRecorder     *tracetest.SpanRecorder
+	testSpanRecorderOnce sync.Once
+)
+
+// spanRecorder installs a global tracer provider recording all spans. Tests
+// run in parallel, so spans must be told apart by their Vault request ID.
+func spanRecorder() *tracetest.SpanRecorder {
+	testSpanRecorderOnce.Do(func() {
+		testSpanRecorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(testSpanRecorder)))
+	})
+
+	return testSpanRecorder
+}
+
+// requestSpans returns the root span of the given Vault request and its descendants.
+func requestSpans(sr *tracetest.SpanRecorder, requestID string) (sdktrace.ReadOnlySpan, []sdktrace.ReadOnlySpan) {
+	var root sdktrace.ReadOnlySpan
+
+	spans := sr.Ended()
+
+	for _, s := range spans {
+		for _, a := range s.Attributes() {
+			if a.Key == tracing.AttrVaultRequestID && a.Value.AsString() == requestID {
+				root = s
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, nil
+	}
+
+	children := make([]sdktrace.ReadOnlySpan, 0)
+
+	for _, s := range spans {
+		sc, rc := s.SpanContext(), root.SpanContext()
+		if sc.TraceID() == rc.TraceID() && sc.SpanID() != rc.SpanID() {
+			children = append(children, s)
+		}
+	}
+
+	return root, children
+}
+
+func spanNames(spans []sdktrace.ReadOnlySpan) []string {
+	out := make([]string, 0, len(spans))
+	for _, s := range spans {
+		out = append(out, s.Name())
+	}
+
+	return out
+}
+
+func TestTracing_Spans(t *testing.T) {
+	t.Parallel()
+
+	sr := spanRecorder()
+	ctx := context.Background()
+	b, storage := newTestBackend(t, nil)
+
+	_, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{APIKey: "root-key"})
+	t.Cleanup(ts.Close)
+
+	_, err := b.HandleRequest(ctx, &logical.Request{
+		ID:        "trace-config",
+		Storage:   storage,
+		Operation: logical.CreateOperation,
+		Path:      pathPatternConfig,
+		Data: map[string]any{
+			"api_key":  "root-key",
+			"base_url": ts.URL,
+		},
+	})
+	require.NoError(t, err)
+
+	root, children := requestSpans(sr, "trace-config")
+	require.NotNil(t, root)
+	require.Equal(t, "vercel.config.write", root.Name())
+	require.Contains(t, spanNames(children), "vault.storage.put")
+
+	r, err := b.HandleRequest(ctx, &logical.Request{
+		ID:        "trace-token",
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternToken,
+	})
+	require.NoError(t, err)
+
+	root, children = requestSpans(sr, "trace-token")
+	require.NotNil(t, root)
+	require.Equal(t, "vercel.token.create", root.Name())
+	require.Subset(t, spanNames(children), []string{"vault.storage.get", "vercel POST", "vault.storage.put"})
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		ID:        "trace-revoke",
+		Storage:   storage,
+		Operation: logical.RevokeOperation,
+		Path:      pathPatternToken,
+		Secret: &logical.Secret{
+			InternalData: r.Secret.InternalData,
+		},
+	})
+	require.NoError(t, err)
+
+	root, children = requestSpans(sr, "trace-revoke")
+	require.NotNil(t, root)
+	require.Equal(t, "vercel.token.revoke", root.Name())
+	require.Subset(t, spanNames(children), []string{"vercel GET", "vercel DELETE"})
+
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		ID:        "trace-failure",
+		Storage:   storage,
+		Operation: logical.ReadOperation,
+		Path:      pathPatternToken,
+		Data: map[string]any{
+			"ttl": 100000,
+		},
+	})
+	require.Error(t, err)
+
+	root, _ = requestSpans(sr, "trace-failure")
+	require.NotNil(t, root)
+	require.Equal(t, codes.Error, root.Status().Code)
+}
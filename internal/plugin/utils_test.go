@@ -14,21 +14,7 @@ func newTestBackend(t *testing.T, disabledOps []logical.Operation) (*backend, lo
 
 	config := logical.TestBackendConfig()
 	sw := new(logical.InmemStorage)
-
-	for _, v := range disabledOps {
-		switch v {
-		case logical.ReadOperation:
-			sw.Underlying().FailGet(true)
-		case logical.UpdateOperation:
-			sw.Underlying().FailPut(true)
-		case logical.DeleteOperation:
-			sw.Underlying().FailDelete(true)
-		case logical.CreateOperation:
-			sw.Underlying().FailPut(true)
-		case logical.RevokeOperation:
-			sw.Underlying().FailDelete(true)
-		}
-	}
+	failStorageOps(sw, disabledOps)
 
 	config.StorageView = sw
 	config.Logger = hclog.NewNullLogger()
@@ -39,5 +25,26 @@ func newTestBackend(t *testing.T, disabledOps []logical.Operation) (*backend, lo
 	b, ok := br.(*backend)
 	require.Equal(t, ok, true)
 
+	b.allowMockClient = true
+
 	return b, config.StorageView
 }
+
+// failStorageOps makes the storage operations backing the given request
+// operations fail.
+func failStorageOps(s *logical.InmemStorage, ops []logical.Operation) {
+	for _, v := range ops {
+		switch v {
+		case logical.ReadOperation:
+			s.Underlying().FailGet(true)
+		case logical.UpdateOperation:
+			s.Underlying().FailPut(true)
+		case logical.DeleteOperation:
+			s.Underlying().FailDelete(true)
+		case logical.CreateOperation:
+			s.Underlying().FailPut(true)
+		case logical.RevokeOperation:
+			s.Underlying().FailDelete(true)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/client"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+var (
+	errVercelUnexpected = errors.New("unexpected error from vercel api")
+	errVercelTimeout    = errors.New("timed out waiting for vercel api")
+)
+
+// vercelError maps a Vercel API failure to a coded error carrying a
+// matching HTTP status, so callers can tell the cause apart.
+func vercelError(op error, err error) error {
+	status := http.StatusBadGateway
+	reason := errVercelUnexpected
+
+	for _, m := range []struct {
+		kind   error
+		status int
+	}{
+		{vercel.ErrInvalidAPIKey, http.StatusBadGateway},
+		{vercel.ErrSAMLReauthRequired, http.StatusForbidden},
+		{vercel.ErrForbidden, http.StatusForbidden},
+		{vercel.ErrNotFound, http.StatusNotFound},
+		{vercel.ErrRateLimited, http.StatusTooManyRequests},
+		{vercel.ErrBadRequest, http.StatusBadRequest},
+		{vercel.ErrUpstream, http.StatusBadGateway},
+		{client.ErrCircuitOpen, http.StatusServiceUnavailable},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+	} {
+		if errors.Is(err, m.kind) {
+			status, reason = m.status, m.kind
+
+			break
+		}
+	}
+
+	if errors.Is(reason, context.DeadlineExceeded) {
+		reason = errVercelTimeout
+	}
+
+	msg := fmt.Sprintf("%s: %s", op, reason)
+
+	var httpErr *vercel.HTTPError
+	if errors.As(err, &httpErr) {
+		if httpErr.Message != "" {
+			msg = fmt.Sprintf("%s: %s", msg, httpErr.Message)
+		}
+
+		if httpErr.RetryAfter > 0 {
+			msg = fmt.Sprintf("%s, retry after %s", msg, httpErr.RetryAfter.Round(time.Second))
+		}
+	}
+
+	var openErr *client.CircuitOpenError
+	if errors.As(err, &openErr) && openErr.RetryAfter > 0 {
+		msg = fmt.Sprintf("%s, retry after %s", msg, openErr.RetryAfter.Round(time.Second))
+	}
+
+	return logical.CodedError(status, msg)
+}
@@ -0,0 +1,77 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/client"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+func TestVercelError(t *testing.T) {
+	t.Parallel()
+
+	op := errors.New("failed to do things")
+
+	cases := map[string]struct {
+		err       error
+		expStatus int
+		expError  string
+	}{
+		"invalid api key": {
+			err:       &vercel.HTTPError{StatusCode: http.StatusUnauthorized},
+			expStatus: http.StatusBadGateway,
+			expError:  "failed to do things: vercel rejected the api key",
+		},
+		"rate limited with retry": {
+			err: &vercel.HTTPError{
+				StatusCode: http.StatusTooManyRequests,
+				Message:    "Rate limit exceeded",
+				RetryAfter: 1500 * time.Millisecond,
+			},
+			expStatus: http.StatusTooManyRequests,
+			expError:  "failed to do things: vercel rate limit exceeded: Rate limit exceeded, retry after 2s",
+		},
+		"not found": {
+			err:       &vercel.HTTPError{StatusCode: http.StatusNotFound},
+			expStatus: http.StatusNotFound,
+			expError:  "failed to do things: vercel resource not found",
+		},
+		"timeout": {
+			err:       fmt.Errorf("wrapped: %w", context.DeadlineExceeded),
+			expStatus: http.StatusGatewayTimeout,
+			expError:  "failed to do things: timed out waiting for vercel api",
+		},
+		"circuit open": {
+			err:       &client.CircuitOpenError{RetryAfter: 12 * time.Second},
+			expStatus: http.StatusServiceUnavailable,
+			expError:  "failed to do things: upstream unavailable: vercel api circuit breaker is open, retry after 12s",
+		},
+		"unexpected": {
+			err:       errors.New("boom"),
+			expStatus: http.StatusBadGateway,
+			expError:  "failed to do things: unexpected error from vercel api",
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := vercelError(op, tc.err)
+			require.EqualError(t, err, tc.expError)
+
+			var coded logical.HTTPCodedError
+
+			require.ErrorAs(t, err, &coded)
+			require.Equal(t, tc.expStatus, coded.Code())
+		})
+	}
+}
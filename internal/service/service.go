@@ -2,11 +2,21 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
-	"net/http"
 	"time"
 
 	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/client"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+const (
+	envTypeSensitive = "sensitive"
+	envTypeSecret    = "secret"
+	// bypassSecretLength is the length Vercel requires of protection bypass secrets.
+	bypassSecretLength   = 32
+	bypassSecretAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	teamsPageSize        = 100
 )
 
 var (
@@ -23,37 +33,24 @@ type Token struct {
 }
 
 func New(apiKey string) *Service {
-	c := &http.Client{}
-
-	var ac client.Client
-
-	if apiKey == "mock" {
-		ac = client.NewMockClient()
-	} else {
-		ac = client.NewAPIClient(apiKey, c)
-	}
-
-	return &Service{
-		client: ac,
-	}
+	return NewWithClient(vercel.NewClient(apiKey))
 }
 
 func NewWithBaseURL(apiKey string, baseURL string) *Service {
-	c := &http.Client{}
-
-	var ac client.Client
-
-	if apiKey == "mock" {
-		ac = client.NewMockClient()
-	} else {
-		ac = client.NewAPIClientWithBaseURL(apiKey, c, baseURL)
-	}
+	return NewWithClient(vercel.NewClient(apiKey, vercel.WithBaseURL(baseURL)))
+}
 
+func NewWithClient(c client.Client) *Service {
 	return &Service{
-		client: ac,
+		client: c,
 	}
 }
 
+// Client returns the Vercel API client used by the service.
+func (s *Service) Client() client.Client {
+	return s.client
+}
+
 func (s *Service) CreateAuthToken(ctx context.Context, name string, ttl int64, teamID string) (string, string, error) {
 	if ttl <= 0 {
 		return "", "", errInvalidTTL
@@ -61,7 +58,7 @@ func (s *Service) CreateAuthToken(ctx context.Context, name string, ttl int64, t
 
 	expiresAt := time.Now().Add(time.Duration(ttl) * time.Second).UTC().UnixMilli()
 
-	r, err := s.client.CreateAuthToken(ctx, &client.CreateAuthTokenRequest{
+	r, err := s.client.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{
 		Name:      name,
 		ExpiresAt: expiresAt,
 		TeamID:    teamID,
@@ -74,7 +71,7 @@ func (s *Service) CreateAuthToken(ctx context.Context, name string, ttl int64, t
 }
 
 func (s *Service) DeleteAuthToken(ctx context.Context, id string) (string, error) {
-	r, err := s.client.DeleteAuthToken(ctx, &client.DeleteAuthTokenRequest{
+	r, err := s.client.DeleteAuthToken(ctx, &vercel.DeleteAuthTokenRequest{
 		ID: id,
 	})
 	if err != nil {
@@ -83,3 +80,186 @@ func (s *Service) DeleteAuthToken(ctx context.Context, id string) (string, error
 
 	return r.ID, err
 }
+
+// AuthTokenLastUsed returns when the token was last used. The boolean result
+// is false for tokens that were never used after creation.
+func (s *Service) AuthTokenLastUsed(ctx context.Context, id string) (time.Time, bool, error) {
+	r, err := s.client.GetAuthToken(ctx, &vercel.GetAuthTokenRequest{
+		ID: id,
+	})
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return time.UnixMilli(r.Token.ActiveAt).UTC(), r.Token.ActiveAt > r.Token.CreatedAt, nil
+}
+
+// ProjectEnv returns the decrypted environment variables of a project for the
+// given target. Branch-specific values override generic ones when gitBranch is
+// set. Keys whose values Vercel does not reveal, such as sensitive variables,
+// are returned separately.
+func (s *Service) ProjectEnv(ctx context.Context, projectID, target, gitBranch,
+	teamID string) (map[string]string, []string, error) {
+	r, err := s.client.ListProjectEnv(ctx, &vercel.ListProjectEnvRequest{
+		ProjectID: projectID,
+		TeamID:    teamID,
+		GitBranch: gitBranch,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make(map[string]string, len(r.Envs))
+	skipped := make([]string, 0)
+	branchKeys := make(map[string]bool)
+
+	for _, e := range r.Envs {
+		if !e.Target.Has(target) {
+			continue
+		}
+
+		if e.GitBranch != "" && e.GitBranch != gitBranch {
+			continue
+		}
+
+		if e.Type == envTypeSensitive || e.Type == envTypeSecret {
+			skipped = append(skipped, e.Key)
+
+			continue
+		}
+
+		if e.GitBranch == "" && branchKeys[e.Key] {
+			continue
+		}
+
+		if e.GitBranch != "" {
+			branchKeys[e.Key] = true
+		}
+
+		values[e.Key] = e.Value
+	}
+
+	return values, skipped, nil
+}
+
+// CreateProtectionBypass generates a Protection Bypass for Automation secret
+// and adds it to the project. The secret is generated locally so that it is
+// known to the caller without diffing the project's existing secrets.
+func (s *Service) CreateProtectionBypass(ctx context.Context, projectID, teamID, note string) (string, error) {
+	secret, err := randomBypassSecret()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.client.CreateProtectionBypass(ctx, &vercel.CreateProtectionBypassRequest{
+		ProjectID: projectID,
+		TeamID:    teamID,
+		Secret:    secret,
+		Note:      note,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+func (s *Service) RevokeProtectionBypass(ctx context.Context, projectID, teamID, secret string) error {
+	_, err := s.client.RevokeProtectionBypass(ctx, &vercel.RevokeProtectionBypassRequest{
+		ProjectID: projectID,
+		TeamID:    teamID,
+		Secret:    secret,
+	})
+
+	return err
+}
+
+// randomBypassSecret returns a random alphanumeric secret. Bytes beyond the
+// largest multiple of the alphabet length are discarded to avoid modulo bias.
+func randomBypassSecret() (string, error) {
+	limit := 256 - 256%len(bypassSecretAlphabet)
+	out := make([]byte, 0, bypassSecretLength)
+	buf := make([]byte, bypassSecretLength)
+
+	for len(out) < bypassSecretLength {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+
+		for _, c := range buf {
+			if int(c) < limit && len(out) < bypassSecretLength {
+				out = append(out, bypassSecretAlphabet[int(c)%len(bypassSecretAlphabet)])
+			}
+		}
+	}
+
+	return string(out), nil
+}
+
+// CreateEdgeConfigToken creates a read token for an Edge Config store and
+// returns its value and ID.
+func (s *Service) CreateEdgeConfigToken(ctx context.Context, edgeConfigID, teamID,
+	label string) (string, string, error) {
+	r, err := s.client.CreateEdgeConfigToken(ctx, &vercel.CreateEdgeConfigTokenRequest{
+		EdgeConfigID: edgeConfigID,
+		TeamID:       teamID,
+		Label:        label,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return r.Token, r.ID, nil
+}
+
+func (s *Service) DeleteEdgeConfigToken(ctx context.Context, edgeConfigID, teamID, token string) error {
+	return s.client.DeleteEdgeConfigTokens(ctx, &vercel.DeleteEdgeConfigTokensRequest{
+		EdgeConfigID: edgeConfigID,
+		TeamID:       teamID,
+		Tokens:       []string{token},
+	})
+}
+
+// CreateWebhook registers a webhook and returns its ID and signing secret.
+func (s *Service) CreateWebhook(ctx context.Context, teamID, url string, events,
+	projectIDs []string) (string, string, error) {
+	r, err := s.client.CreateWebhook(ctx, &vercel.CreateWebhookRequest{
+		TeamID:     teamID,
+		URL:        url,
+		Events:     events,
+		ProjectIDs: projectIDs,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return r.ID, r.Secret, nil
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, id, teamID string) error {
+	return s.client.DeleteWebhook(ctx, &vercel.DeleteWebhookRequest{
+		ID:     id,
+		TeamID: teamID,
+	})
+}
+
+// ListTeams returns every team the API key has access to, walking all pages.
+func (s *Service) ListTeams(ctx context.Context) ([]vercel.Team, error) {
+	out := make([]vercel.Team, 0)
+
+	for t, err := range vercel.Teams(ctx, s.client, teamsPageSize) {
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, t)
+	}
+
+	return out, nil
+}
+
+// RateLimits returns the request budget per Vercel API endpoint, as last
+// reported by Vercel.
+func (s *Service) RateLimits() []vercel.RateLimit {
+	return s.client.RateLimits()
+}
@@ -3,9 +3,11 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/client"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
 )
 
 func TestService_New(t *testing.T) {
@@ -18,21 +20,16 @@ func TestService_New(t *testing.T) {
 	}{
 		"api client": {
 			apiKey: "12345asbd",
-			expURL: client.DefaultBaseURL,
+			expURL: vercel.DefaultBaseURL,
 		},
 		"api client with custom base url": {
 			apiKey:  "abbaacdc",
 			baseURL: "http://somethingelse",
 			expURL:  "http://somethingelse",
 		},
-		"mock client": {
+		"api key named mock": {
 			apiKey: "mock",
-			expURL: "",
-		},
-		"mock client with custom base url": {
-			apiKey:  "mock",
-			baseURL: "http://somethingelse",
-			expURL:  "",
+			expURL: vercel.DefaultBaseURL,
 		},
 	}
 	for name, tc := range cases {
@@ -51,6 +48,15 @@ func TestService_New(t *testing.T) {
 	}
 }
 
+func TestService_NewWithClient(t *testing.T) {
+	t.Parallel()
+
+	m := client.NewMockClient()
+	s := NewWithClient(m)
+	require.Same(t, m, s.client)
+	require.Empty(t, s.client.GetBaseURL())
+}
+
 func TestService_CreateToken(t *testing.T) {
 	t.Parallel()
 
@@ -84,7 +90,7 @@ func TestService_CreateToken(t *testing.T) {
 			t.Parallel()
 
 			ctx := context.Background()
-			s := New("mock")
+			s := NewWithClient(client.NewMockClient())
 			tid, bt, err := s.CreateAuthToken(ctx, tc.name, tc.ttl, tc.teamID)
 			if tc.expError != "" {
 				require.EqualError(t, err, tc.expError)
@@ -121,7 +127,7 @@ func TestService_DeleteToken(t *testing.T) {
 			t.Parallel()
 
 			ctx := context.Background()
-			s := New("mock")
+			s := NewWithClient(client.NewMockClient())
 
 			var id string
 			if tc.createToken {
@@ -139,3 +145,167 @@ func TestService_DeleteToken(t *testing.T) {
 		})
 	}
 }
+
+func TestService_AuthTokenLastUsed(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := client.NewMockClient()
+	s := NewWithClient(m)
+
+	id, _, err := s.CreateAuthToken(ctx, "foo", 60, "")
+	require.NoError(t, err)
+
+	_, used, err := s.AuthTokenLastUsed(ctx, id)
+	require.NoError(t, err)
+	require.False(t, used)
+
+	at := time.Now().Add(time.Minute).Truncate(time.Millisecond).UTC()
+	m.MarkAuthTokenUsed(id, at)
+
+	lastUsed, used, err := s.AuthTokenLastUsed(ctx, id)
+	require.NoError(t, err)
+	require.True(t, used)
+	require.Equal(t, at, lastUsed)
+
+	_, _, err = s.AuthTokenLastUsed(ctx, "bogus")
+	require.ErrorIs(t, err, vercel.ErrNotFound)
+}
+
+func TestService_ProjectEnv(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		projectID  string
+		target     string
+		gitBranch  string
+		expValues  map[string]string
+		expSkipped []string
+		expError   string
+	}{
+		"production": {
+			projectID:  "prj_a",
+			target:     "production",
+			expValues:  map[string]string{"MOCK_SHARED": "shared-value"},
+			expSkipped: []string{"MOCK_SENSITIVE"},
+		},
+		"preview": {
+			projectID: "prj_a",
+			target:    "preview",
+			expValues: map[string]string{"MOCK_SHARED": "shared-value", "MOCK_BRANCH": "preview-value"},
+		},
+		"preview with branch override": {
+			projectID: "prj_a",
+			target:    "preview",
+			gitBranch: "mock-branch",
+			expValues: map[string]string{"MOCK_SHARED": "shared-value", "MOCK_BRANCH": "branch-value"},
+		},
+		"preview with other branch": {
+			projectID: "prj_a",
+			target:    "preview",
+			gitBranch: "other",
+			expValues: map[string]string{"MOCK_SHARED": "shared-value", "MOCK_BRANCH": "preview-value"},
+		},
+		"missing project": {
+			target:   "preview",
+			expError: "empty project id",
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := NewWithClient(client.NewMockClient())
+			values, skipped, err := s.ProjectEnv(context.Background(), tc.projectID, tc.target, tc.gitBranch, "")
+
+			if tc.expError != "" {
+				require.EqualError(t, err, tc.expError)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expValues, values)
+			require.ElementsMatch(t, tc.expSkipped, skipped)
+		})
+	}
+}
+
+func TestService_ProtectionBypass(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := client.NewMockClient()
+	s := NewWithClient(m)
+
+	secret, err := s.CreateProtectionBypass(ctx, "prj_a", "team_a", "note")
+	require.NoError(t, err)
+	require.Regexp(t, `^[A-Za-z0-9]{32}$`, secret)
+	require.Equal(t, 1, m.ProtectionBypassCount("prj_a"))
+
+	other, err := s.CreateProtectionBypass(ctx, "prj_a", "team_a", "note")
+	require.NoError(t, err)
+	require.NotEqual(t, secret, other)
+
+	require.NoError(t, s.RevokeProtectionBypass(ctx, "prj_a", "team_a", secret))
+	require.Equal(t, 1, m.ProtectionBypassCount("prj_a"))
+	require.ErrorIs(t, s.RevokeProtectionBypass(ctx, "prj_a", "team_a", secret), vercel.ErrNotFound)
+
+	_, err = s.CreateProtectionBypass(ctx, "", "", "")
+	require.Error(t, err)
+}
+
+func TestService_EdgeConfigToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := client.NewMockClient()
+	s := NewWithClient(m)
+
+	token, id, err := s.CreateEdgeConfigToken(ctx, "ecfg_a", "team_a", "label")
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, id)
+	require.Equal(t, 1, m.EdgeConfigTokenCount("ecfg_a"))
+
+	require.NoError(t, s.DeleteEdgeConfigToken(ctx, "ecfg_a", "team_a", token))
+	require.Zero(t, m.EdgeConfigTokenCount("ecfg_a"))
+
+	_, _, err = s.CreateEdgeConfigToken(ctx, "", "", "label")
+	require.Error(t, err)
+}
+
+func TestService_Webhook(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := client.NewMockClient()
+	s := NewWithClient(m)
+
+	id, secret, err := s.CreateWebhook(ctx, "team_a", "https://example.com/hook",
+		[]string{"deployment.created"}, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+	require.NotEmpty(t, secret)
+	require.Equal(t, 1, m.WebhookCount())
+
+	require.NoError(t, s.DeleteWebhook(ctx, id, "team_a"))
+	require.Zero(t, m.WebhookCount())
+	require.ErrorIs(t, s.DeleteWebhook(ctx, id, "team_a"), vercel.ErrNotFound)
+}
+
+func TestService_ListTeams(t *testing.T) {
+	t.Parallel()
+
+	teams, err := NewWithClient(client.NewMockClient()).ListTeams(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, client.MockTeams, teams)
+}
+
+func TestService_RateLimits(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, NewWithClient(client.NewMockClient()).RateLimits())
+}
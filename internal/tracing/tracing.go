@@ -0,0 +1,99 @@
+// Package tracing wires OpenTelemetry tracing for the plugin. Spans are
+// exported through OTLP over HTTP when an OTLP endpoint is configured with the
+// standard OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT
+// variables. Otherwise spans are dropped.
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName  = "github.com/thevilledev/vault-plugin-secrets-vercel"
+	serviceName = "vault-plugin-secrets-vercel"
+
+	envOTLPEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	envOTLPTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+
+	// AttrVaultRequestID carries the ID of the Vault request a span belongs to.
+	AttrVaultRequestID = attribute.Key("vault.request_id")
+	// AttrVaultPath is the path of the Vault request, relative to the mount.
+	AttrVaultPath = attribute.Key("vault.path")
+	// AttrVaultOperation is the logical operation of the Vault request.
+	AttrVaultOperation = attribute.Key("vault.operation")
+	// AttrVaultMountPoint is the mount point of the plugin.
+	AttrVaultMountPoint = attribute.Key("vault.mount_point")
+)
+
+// Tracer returns the tracer used by the plugin. Until Setup installs an
+// exporting provider, spans created by it are no-ops.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// TracerProvider returns the tracer provider of the plugin, for clients that
+// create their own tracers, such as the Vercel API client.
+func TracerProvider() trace.TracerProvider {
+	return otel.GetTracerProvider()
+}
+
+// Enabled reports whether an OTLP endpoint is configured in the environment.
+func Enabled() bool {
+	return os.Getenv(envOTLPEndpoint) != "" || os.Getenv(envOTLPTracesEndpoint) != ""
+}
+
+// Setup installs a global tracer provider exporting spans through OTLP over
+// HTTP, if Enabled. The returned function flushes and stops the exporter.
+func Setup(ctx context.Context, version string) (func(context.Context) error, error) {
+	if !Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exp, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp.Shutdown, nil
+}
+
+// End records err on the span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup_Disabled(t *testing.T) {
+	t.Setenv(envOTLPEndpoint, "")
+	t.Setenv(envOTLPTracesEndpoint, "")
+
+	require.False(t, Enabled())
+
+	shutdown, err := Setup(context.Background(), "v1.0.0")
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetup_Enabled(t *testing.T) {
+	t.Setenv(envOTLPEndpoint, "")
+	t.Setenv(envOTLPTracesEndpoint, "http://127.0.0.1:4318/v1/traces")
+
+	require.True(t, Enabled())
+
+	shutdown, err := Setup(context.Background(), "v1.0.0")
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+}
+
+func TestEnd(t *testing.T) {
+	t.Parallel()
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	_, ok := tp.Tracer("test").Start(context.Background(), "ok")
+	End(ok, nil)
+
+	_, failed := tp.Tracer("test").Start(context.Background(), "failed")
+	End(failed, errors.New("boom"))
+
+	spans := sr.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+	require.Equal(t, "boom", spans[1].Status().Description)
+	require.Len(t, spans[1].Events(), 1)
+}
@@ -0,0 +1,244 @@
+// Package vaultexec runs a command with a Vercel token leased from the plugin
+// and revokes the lease as soon as the command exits.
+package vaultexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+const (
+	DefaultMount = "vercel-secrets"
+
+	EnvToken     = "VERCEL_TOKEN"
+	EnvOrgID     = "VERCEL_ORG_ID"
+	EnvProjectID = "VERCEL_PROJECT_ID"
+
+	// envVaultPrefix starts the Vault client settings, including the address
+	// and token of the wrapper, which are not passed on to the command.
+	envVaultPrefix = "VAULT_"
+
+	revokeTimeout = 30 * time.Second
+	// exitCodeFailure is returned when the command could not be run at all.
+	exitCodeFailure = 1
+	// exitCodeSignal is added to the signal number when the command was
+	// killed by a signal, following the shell convention.
+	exitCodeSignal = 128
+)
+
+var (
+	errMissingCommand     = errors.New("missing command to run")
+	errMissingBearerToken = errors.New("token response has no bearer_token")
+)
+
+// Config controls a single wrapped command run.
+type Config struct {
+	// Client is the Vault API client used to lease and revoke the token.
+	Client *api.Client
+	// Mount is the path the plugin is mounted at. Defaults to DefaultMount.
+	Mount string
+	// TTL, TeamID and ProjectID are passed to the token endpoint when set.
+	TTL       time.Duration
+	TeamID    string
+	ProjectID string
+	// Command and Args make up the child process.
+	Command string
+	Args    []string
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Run leases a token, runs the command with the token in its environment,
+// revokes the lease and returns the exit code of the command. The lease is
+// revoked even when the command fails.
+func Run(ctx context.Context, cfg Config) (int, error) {
+	if cfg.Command == "" {
+		return exitCodeFailure, errMissingCommand
+	}
+
+	if cfg.Mount == "" {
+		cfg.Mount = DefaultMount
+	}
+
+	// Signals are handled before leasing, so that an interrupted run revokes
+	// the lease instead of exiting with it outstanding. Signals arriving
+	// while the command starts are held in the channel and forwarded once it
+	// runs.
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	defer signal.Stop(sigs)
+
+	secret, env, err := lease(ctx, cfg)
+	if err != nil {
+		return exitCodeFailure, errors.Join(err, revoke(ctx, cfg.Client, secret))
+	}
+
+	// The command is not started when the run was interrupted while leasing.
+	select {
+	case sig := <-sigs:
+		return signalExitCode(sig), revoke(ctx, cfg.Client, secret)
+	default:
+	}
+
+	code, err := run(ctx, cfg, env, sigs)
+
+	return code, errors.Join(err, revoke(ctx, cfg.Client, secret))
+}
+
+// revoke revokes the lease of the secret, if any. It uses a fresh context so
+// that a cancelled run still cleans up.
+func revoke(ctx context.Context, c *api.Client, secret *api.Secret) error {
+	if secret == nil || secret.LeaseID == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), revokeTimeout)
+	defer cancel()
+
+	if err := c.Sys().RevokeWithContext(ctx, secret.LeaseID); err != nil {
+		return fmt.Errorf("failed to revoke lease %s: %w", secret.LeaseID, err)
+	}
+
+	return nil
+}
+
+// lease requests a token and returns the environment variables derived from it.
+func lease(ctx context.Context, cfg Config) (*api.Secret, []string, error) {
+	data := map[string]any{}
+
+	if cfg.TTL > 0 {
+		data["ttl"] = int64(cfg.TTL / time.Second)
+	}
+
+	if cfg.TeamID != "" {
+		data["team_id"] = cfg.TeamID
+	}
+
+	if cfg.ProjectID != "" {
+		data["project_id"] = cfg.ProjectID
+	}
+
+	secret, err := cfg.Client.Logical().WriteWithContext(ctx, cfg.Mount+"/token", data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lease token: %w", err)
+	}
+
+	if secret == nil {
+		return nil, nil, errMissingBearerToken
+	}
+
+	bearer, _ := secret.Data["bearer_token"].(string)
+	if bearer == "" {
+		return secret, nil, errMissingBearerToken
+	}
+
+	env := []string{EnvToken + "=" + bearer}
+
+	if v, _ := secret.Data["team_id"].(string); v != "" {
+		env = append(env, EnvOrgID+"="+v)
+	}
+
+	projectID, _ := secret.Data["project_id"].(string)
+	if projectID == "" {
+		projectID = cfg.ProjectID
+	}
+
+	if projectID != "" {
+		env = append(env, EnvProjectID+"="+projectID)
+	}
+
+	return secret, env, nil
+}
+
+// childEnv returns the environment of the command: the one of this process
+// without the Vault client settings and the inherited Vercel variables,
+// followed by the variables of the lease. A team or project inherited from the
+// caller would otherwise apply to a token not scoped to it.
+func childEnv(environ, leased []string) []string {
+	out := make([]string, 0, len(environ)+len(leased))
+
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+
+		switch {
+		case strings.HasPrefix(name, envVaultPrefix):
+		case name == EnvToken, name == EnvOrgID, name == EnvProjectID:
+		default:
+			out = append(out, kv)
+		}
+	}
+
+	return append(out, leased...)
+}
+
+// run starts the command and forwards the signals received on sigs to it
+// until it exits.
+func run(ctx context.Context, cfg Config, env []string, sigs <-chan os.Signal) (int, error) {
+	// #nosec G204 -- running a user supplied command is the purpose of this wrapper
+	cmd := exec.CommandContext(ctx, cfg.Command, cfg.Args...)
+	cmd.Env = childEnv(os.Environ(), env)
+	cmd.Stdin = cfg.Stdin
+	cmd.Stdout = cfg.Stdout
+	cmd.Stderr = cfg.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return exitCodeFailure, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	for {
+		select {
+		case sig := <-sigs:
+			_ = cmd.Process.Signal(sig)
+		case err := <-done:
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				return exitCode(exitErr), nil
+			}
+
+			if err != nil {
+				return exitCodeFailure, err
+			}
+
+			return 0, nil
+		}
+	}
+}
+
+// exitCode returns the exit code of the command, or 128 plus the signal
+// number if it was killed by a signal.
+func exitCode(err *exec.ExitError) int {
+	if ws, ok := err.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return exitCodeSignal + int(ws.Signal())
+	}
+
+	return err.ExitCode()
+}
+
+// signalExitCode returns 128 plus the signal number, for runs interrupted
+// before the command started.
+func signalExitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return exitCodeSignal + int(s)
+	}
+
+	return exitCodeFailure
+}
@@ -0,0 +1,296 @@
+package vaultexec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/require"
+)
+
+const testLeaseID = "vercel-secrets/token/lease-1"
+
+type fakeVault struct {
+	mu        sync.Mutex
+	tokenReq  map[string]any
+	revoked   []string
+	tokenData map[string]any
+	tokenCode int
+	// onToken is called while the token request is served.
+	onToken func()
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body map[string]any
+
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	switch r.URL.Path {
+	case "/v1/" + DefaultMount + "/token":
+		f.tokenReq = body
+
+		if f.onToken != nil {
+			f.onToken()
+		}
+
+		if f.tokenCode != 0 {
+			w.WriteHeader(f.tokenCode)
+			_, _ = w.Write([]byte(`{"errors":["backend not configured"]}`))
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"lease_id":       testLeaseID,
+			"lease_duration": 600,
+			"data":           f.tokenData,
+		})
+	case "/v1/sys/leases/revoke":
+		id, _ := body["lease_id"].(string)
+		f.revoked = append(f.revoked, id)
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestClient(t *testing.T, f *fakeVault) *api.Client {
+	t.Helper()
+
+	ts := httptest.NewServer(f)
+	t.Cleanup(ts.Close)
+
+	c, err := api.NewClient(&api.Config{Address: ts.URL})
+	require.NoError(t, err)
+
+	c.SetToken("root")
+
+	return c
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		tokenData  map[string]any
+		tokenCode  int
+		cfg        Config
+		expCode    int
+		expStdout  string
+		expTokenRq map[string]any
+		expRevoked []string
+		expError   string
+	}{
+		"exports token and ids": {
+			tokenData: map[string]any{
+				"bearer_token": "bearer-1",
+				"team_id":      "team_a",
+			},
+			cfg: Config{
+				TTL:       time.Minute,
+				TeamID:    "team_a",
+				ProjectID: "prj_a",
+				Command:   "sh",
+				Args:      []string{"-c", `printf '%s %s %s' "$VERCEL_TOKEN" "$VERCEL_ORG_ID" "$VERCEL_PROJECT_ID"`},
+			},
+			expStdout: "bearer-1 team_a prj_a",
+			expTokenRq: map[string]any{
+				"ttl":        float64(60),
+				"team_id":    "team_a",
+				"project_id": "prj_a",
+			},
+			expRevoked: []string{testLeaseID},
+		},
+		"personal scope": {
+			tokenData: map[string]any{
+				"bearer_token": "bearer-1",
+				"team_id":      "",
+			},
+			cfg: Config{
+				Command: "sh",
+				Args:    []string{"-c", `printf '%s:%s' "$VERCEL_TOKEN" "${VERCEL_ORG_ID-unset}"`},
+			},
+			expStdout:  "bearer-1:unset",
+			expTokenRq: map[string]any{},
+			expRevoked: []string{testLeaseID},
+		},
+		"child exit code": {
+			tokenData: map[string]any{
+				"bearer_token": "bearer-1",
+			},
+			cfg: Config{
+				Command: "sh",
+				Args:    []string{"-c", "exit 3"},
+			},
+			expCode:    3,
+			expTokenRq: map[string]any{},
+			expRevoked: []string{testLeaseID},
+		},
+		"child killed by signal": {
+			tokenData: map[string]any{
+				"bearer_token": "bearer-1",
+			},
+			cfg: Config{
+				Command: "sh",
+				Args:    []string{"-c", "kill -TERM $$"},
+			},
+			expCode:    exitCodeSignal + int(syscall.SIGTERM),
+			expTokenRq: map[string]any{},
+			expRevoked: []string{testLeaseID},
+		},
+		"command not found": {
+			tokenData: map[string]any{
+				"bearer_token": "bearer-1",
+			},
+			cfg: Config{
+				Command: "vercel-vault-exec-no-such-command",
+			},
+			expCode:    exitCodeFailure,
+			expTokenRq: map[string]any{},
+			expRevoked: []string{testLeaseID},
+			expError:   "failed to start command",
+		},
+		"missing bearer token": {
+			tokenData: map[string]any{},
+			cfg: Config{
+				Command: "true",
+			},
+			expCode:    exitCodeFailure,
+			expTokenRq: map[string]any{},
+			expRevoked: []string{testLeaseID},
+			expError:   errMissingBearerToken.Error(),
+		},
+		"lease failure": {
+			tokenCode: http.StatusBadRequest,
+			cfg: Config{
+				Command: "true",
+			},
+			expCode:    exitCodeFailure,
+			expTokenRq: map[string]any{},
+			expError:   "failed to lease token",
+		},
+		"missing command": {
+			expCode:  exitCodeFailure,
+			expError: errMissingCommand.Error(),
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f := &fakeVault{tokenData: tc.tokenData, tokenCode: tc.tokenCode}
+
+			var stdout bytes.Buffer
+
+			cfg := tc.cfg
+			cfg.Client = newTestClient(t, f)
+			cfg.Stdout = &stdout
+
+			code, err := Run(context.Background(), cfg)
+			if tc.expError != "" {
+				require.ErrorContains(t, err, tc.expError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.expCode, code)
+			require.Equal(t, tc.expStdout, stdout.String())
+
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			if tc.expTokenRq != nil {
+				require.Equal(t, tc.expTokenRq, f.tokenReq)
+			}
+
+			require.Equal(t, tc.expRevoked, f.revoked)
+		})
+	}
+}
+
+func TestRun_SignalWhileLeasing(t *testing.T) {
+	// Not parallel: the signal is delivered to the whole test process.
+	f := &fakeVault{tokenData: map[string]any{"bearer_token": "bearer-1"}}
+	f.onToken = func() {
+		received := make(chan os.Signal, 1)
+		signal.Notify(received, syscall.SIGTERM)
+
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+		<-received
+
+		// Stop waits until the signal has been delivered to every channel,
+		// including the one of Run.
+		signal.Stop(received)
+	}
+
+	var stdout bytes.Buffer
+
+	code, err := Run(context.Background(), Config{
+		Client:  newTestClient(t, f),
+		Command: "echo",
+		Args:    []string{"started"},
+		Stdout:  &stdout,
+	})
+	require.NoError(t, err)
+	require.Equal(t, exitCodeSignal+int(syscall.SIGTERM), code)
+	require.Empty(t, stdout.String())
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	require.Equal(t, []string{testLeaseID}, f.revoked)
+}
+
+func TestChildEnv(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		environ []string
+		leased  []string
+		exp     []string
+	}{
+		"drops vault settings": {
+			environ: []string{"PATH=/bin", "VAULT_ADDR=https://vault", "VAULT_TOKEN=s.root", "VAULT_NAMESPACE=ns"},
+			leased:  []string{EnvToken + "=bearer-1"},
+			exp:     []string{"PATH=/bin", EnvToken + "=bearer-1"},
+		},
+		"drops inherited vercel ids not set by the lease": {
+			environ: []string{EnvOrgID + "=team_b", EnvProjectID + "=prj_b", "HOME=/root"},
+			leased:  []string{EnvToken + "=bearer-1"},
+			exp:     []string{"HOME=/root", EnvToken + "=bearer-1"},
+		},
+		"lease overrides vercel variables": {
+			environ: []string{EnvToken + "=old", EnvOrgID + "=team_b", EnvProjectID + "=prj_b"},
+			leased:  []string{EnvToken + "=bearer-1", EnvOrgID + "=team_a", EnvProjectID + "=prj_a"},
+			exp:     []string{EnvToken + "=bearer-1", EnvOrgID + "=team_a", EnvProjectID + "=prj_a"},
+		},
+		"keeps similar names": {
+			environ: []string{"MY_VAULT_TOKEN=x", "VERCEL_TOKEN_FILE=/tmp/t"},
+			exp:     []string{"MY_VAULT_TOKEN=x", "VERCEL_TOKEN_FILE=/tmp/t"},
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.exp, childEnv(tc.environ, tc.leased))
+		})
+	}
+}
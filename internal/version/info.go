@@ -1,5 +1,13 @@
 package version
 
+import (
+	goversion "github.com/hashicorp/go-version"
+)
+
+// devVersion is reported to Vault when the build version is not valid semver,
+// e.g. for local builds tagged with a commit hash.
+const devVersion = "v0.0.0-dev"
+
 var (
 	BuildDate  string
 	Version    string
@@ -31,3 +39,18 @@ func New() *Info {
 		Dirty:      Dirty,
 	}
 }
+
+// RunningVersion returns the build version in the "v"-prefixed semver form
+// Vault expects in its plugin catalog.
+func RunningVersion() string {
+	return semver(Version)
+}
+
+func semver(v string) string {
+	parsed, err := goversion.NewSemver(v)
+	if err != nil {
+		return devVersion
+	}
+
+	return "v" + parsed.String()
+}
@@ -0,0 +1,34 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemver(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		in  string
+		exp string
+	}{
+		"empty":          {in: "", exp: devVersion},
+		"commit hash":    {in: "d912476", exp: devVersion},
+		"goreleaser":     {in: "0.5.0", exp: "v0.5.0"},
+		"git tag":        {in: "v0.5.0", exp: "v0.5.0"},
+		"prerelease":     {in: "v0.6.0-rc.1", exp: "v0.6.0-rc.1"},
+		"short version":  {in: "v1.2", exp: "v1.2.0"},
+		"build metadata": {in: "1.0.0+abc", exp: "v1.0.0+abc"},
+		"branch name":    {in: "main", exp: devVersion},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.exp, semver(tc.in))
+		})
+	}
+}
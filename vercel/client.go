@@ -0,0 +1,334 @@
+package vercel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultBaseURL is the origin of the Vercel REST API. Every endpoint
+	// carries its own API version, e.g. "/v10/projects".
+	DefaultBaseURL          = "https://api.vercel.com"
+	defaultHTTPTimeout      = 60 * time.Second
+	maxHTTPErrorBodyLength  = 1024
+	truncatedHTTPBodyMarker = "...(truncated)"
+	tracerName              = "github.com/thevilledev/vault-plugin-secrets-vercel/vercel"
+)
+
+var (
+	endpointVersionPrefix = regexp.MustCompile(`^/?v[0-9]+/`)
+	baseURLVersionSuffix  = regexp.MustCompile(`/v[0-9]+/*$`)
+)
+
+var (
+	errEmptyReq                             = errors.New("empty req")
+	errInvalidCreateAuthTokenResponse       = errors.New("invalid create auth token response")
+	errInvalidDeleteAuthTokenResponse       = errors.New("invalid delete auth token response")
+	errInvalidGetAuthTokenResponse          = errors.New("invalid get auth token response")
+	errMissingTokenID                       = errors.New("missing token id")
+	errMissingProjectID                     = errors.New("missing project id")
+	errMissingBypassSecret                  = errors.New("missing protection bypass secret")
+	errInvalidProtectionBypassResponse      = errors.New("invalid protection bypass response")
+	errMissingEdgeConfigID                  = errors.New("missing edge config id")
+	errInvalidCreateEdgeConfigTokenResponse = errors.New("invalid create edge config token response")
+	errInvalidCreateWebhookResponse         = errors.New("invalid create webhook response")
+	errMissingWebhookID                     = errors.New("missing webhook id")
+)
+
+// Client calls the Vercel REST API with an API key. It is safe for
+// concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	limiter    *rateLimiter
+	retry      RetryPolicy
+	tracer     trace.Tracer
+}
+
+// HTTPError is the error of a request that Vercel answered with a 4xx or 5xx
+// status. It matches exactly one of the sentinel errors, such as ErrNotFound,
+// with errors.Is.
+type HTTPError struct {
+	StatusCode int
+	// Body is the response body, with secrets redacted and truncated to 1 KiB.
+	Body string
+	// Code and Message are parsed from a Vercel {"error":{"code","message"}} body, if present.
+	Code    string
+	Message string
+	// RetryAfter is how long Vercel asked the client to wait, derived from rate limit headers.
+	RetryAfter time.Duration
+
+	saml         bool
+	invalidToken bool
+}
+
+// Error returns the status code and the redacted response body.
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("http error %d with response body %q", e.StatusCode, e.Body)
+}
+
+// NewClient returns a client authenticating with apiKey. Without options it
+// talks to DefaultBaseURL with a 60 second timeout and does not retry.
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: DefaultBaseURL,
+		token:   apiKey,
+		limiter: newRateLimiter(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.httpClient = configuredHTTPClient(c.httpClient)
+
+	if c.tracer == nil {
+		c.tracer = otel.GetTracerProvider().Tracer(tracerName)
+	}
+
+	return c
+}
+
+// GetBaseURL returns the base URL the client sends requests to.
+func (c *Client) GetBaseURL() string {
+	return c.baseURL
+}
+
+// do sends a request, retrying it as allowed by the retry policy. The
+// response of the last attempt is returned.
+func (c *Client) do(ctx context.Context, method, endpoint string, body []byte,
+	params map[string]string) (*http.Response, error) {
+	u, err := c.requestURL(endpoint, params)
+	if err != nil {
+		return nil, err
+	}
+
+	key := rateLimitKey(method, endpoint)
+
+	for attempt := 1; ; attempt++ {
+		res, sendErr := c.send(ctx, method, u, key, body)
+
+		delay, retry := c.retry.next(attempt, method, res, sendErr)
+		if !retry {
+			return res, sendErr
+		}
+
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			_ = res.Body.Close()
+		}
+
+		if err = sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send makes a single attempt of a request.
+func (c *Client) send(ctx context.Context, method, u, key string, body []byte) (*http.Response, error) {
+	if err := c.limiter.wait(ctx, key); err != nil {
+		return nil, err
+	}
+
+	bearer := fmt.Sprintf("Bearer %s", c.token)
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", bearer)
+
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = configuredHTTPClient(nil)
+	}
+
+	ctx, span := c.tracer.Start(ctx, "vercel "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.URLFull(u),
+			semconv.ServerAddress(req.URL.Hostname()),
+		),
+	)
+
+	res, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+
+		return nil, err
+	}
+
+	c.limiter.observe(key, res.StatusCode, res.Header)
+	span.SetAttributes(semconv.HTTPResponseStatusCode(res.StatusCode))
+
+	if res.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+	}
+
+	span.End()
+
+	return res, nil
+}
+
+func (c *Client) requestURL(endpoint string, params map[string]string) (string, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid base url %q", c.baseURL)
+	}
+
+	basePath := strings.TrimRight(u.EscapedPath(), "/")
+
+	endpointPath := strings.TrimLeft(endpoint, "/")
+
+	rawPath := endpointPath
+	if basePath != "" {
+		rawPath = fmt.Sprintf("%s/%s", basePath, endpointPath)
+	}
+
+	if rawPath == "" {
+		rawPath = "/"
+	}
+
+	decodedPath, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return "", err
+	}
+
+	u.Path = decodedPath
+	u.RawPath = rawPath
+
+	q := u.Query()
+	for key, val := range params {
+		q.Set(key, val)
+	}
+
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// TrimAPIVersion strips a trailing API version, e.g. "/v3", from a base URL.
+// Base URLs used to carry the version of all endpoints, while endpoints now
+// declare their own, so legacy base URLs are migrated with this.
+func TrimAPIVersion(baseURL string) string {
+	return baseURLVersionSuffix.ReplaceAllString(baseURL, "")
+}
+
+func configuredHTTPClient(client *http.Client) *http.Client {
+	if client == nil {
+		return &http.Client{Timeout: defaultHTTPTimeout}
+	}
+
+	if client.Timeout != 0 {
+		return client
+	}
+
+	clone := *client
+	clone.Timeout = defaultHTTPTimeout
+
+	return &clone
+}
+
+// NewHTTPError returns the error of a response with the given status code and
+// body. Secrets in the body are redacted, and the Vercel error code and message
+// are parsed from it. It is meant for clients and fakes implementing the
+// same behaviour as Client.
+func NewHTTPError(statusCode int, body []byte) *HTTPError {
+	e := &HTTPError{
+		StatusCode: statusCode,
+		Body:       sanitizeHTTPErrorBody(body),
+	}
+
+	var payload errorPayload
+	if err := json.Unmarshal(body, &payload); err == nil {
+		e.Code = payload.Error.Code
+		e.Message = payload.Error.Message
+		e.saml = payload.Error.SAML
+		e.invalidToken = payload.Error.InvalidToken
+	}
+
+	return e
+}
+
+func newHTTPErrorFromResponse(res *http.Response, body []byte) *HTTPError {
+	e := NewHTTPError(res.StatusCode, body)
+	e.RetryAfter = retryAfter(res.Header, time.Now())
+
+	return e
+}
+
+func sanitizeHTTPErrorBody(body []byte) string {
+	var payload any
+	if err := json.Unmarshal(body, &payload); err == nil {
+		redacted := redactJSON(payload)
+
+		b, marshalErr := json.Marshal(redacted)
+		if marshalErr == nil {
+			body = b
+		}
+	}
+
+	if len(body) <= maxHTTPErrorBodyLength {
+		return string(body)
+	}
+
+	return string(body[:maxHTTPErrorBodyLength]) + truncatedHTTPBodyMarker
+}
+
+func redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if isSensitiveKey(key) {
+				v[key] = "[REDACTED]"
+
+				continue
+			}
+
+			v[key] = redactJSON(child)
+		}
+
+		return v
+	case []any:
+		for i, child := range v {
+			v[i] = redactJSON(child)
+		}
+
+		return v
+	default:
+		return value
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	switch strings.ToLower(key) {
+	case "authorization", "apikey", "api_key", "bearertoken", "secret", "token":
+		return true
+	default:
+		return false
+	}
+}
@@ -1,4 +1,4 @@
-package client
+package vercel
 
 import (
 	"context"
@@ -25,7 +25,7 @@ func TestClient(t *testing.T) {
 
 	t.Run("create client", func(t *testing.T) {
 		hc := &http.Client{}
-		k := NewAPIClient("api key", hc)
+		k := NewClient("api key", WithHTTPClient(hc))
 		require.Equal(t, k.baseURL, DefaultBaseURL)
 		require.Equal(t, k.token, "api key")
 		require.Equal(t, defaultHTTPTimeout, k.httpClient.Timeout)
@@ -33,14 +33,14 @@ func TestClient(t *testing.T) {
 	})
 
 	t.Run("create client with nil http client", func(t *testing.T) {
-		k := NewAPIClient("api key", nil)
+		k := NewClient("api key")
 		require.NotNil(t, k.httpClient)
 		require.Equal(t, defaultHTTPTimeout, k.httpClient.Timeout)
 	})
 
 	t.Run("create client preserves custom timeout", func(t *testing.T) {
 		hc := &http.Client{Timeout: time.Second}
-		k := NewAPIClient("api key", hc)
+		k := NewClient("api key", WithHTTPClient(hc))
 		require.Same(t, hc, k.httpClient)
 		require.Equal(t, time.Second, k.httpClient.Timeout)
 	})
@@ -49,7 +49,7 @@ func TestClient(t *testing.T) {
 		ctx := context.Background()
 		hc := &http.Client{}
 
-		k := NewAPIClientWithBaseURL("foo", hc, "http://doesnotexist")
+		k := NewClient("foo", WithHTTPClient(hc), WithBaseURL("http://doesnotexist"))
 		u := k.GetBaseURL()
 		require.Equal(t, u, "http://doesnotexist")
 		_, err := k.do(ctx, http.MethodGet, "/", nil, nil)
@@ -75,22 +75,46 @@ func TestClient(t *testing.T) {
 		)
 		defer srv.Close()
 
-		k := NewAPIClientWithBaseURL("foo", nil, srv.URL+"/v3/")
-		_, err := k.do(ctx, http.MethodGet, "/user/tokens", nil, map[string]string{
+		k := NewClient("foo", WithBaseURL(srv.URL+"/proxy/"))
+		_, err := k.do(ctx, http.MethodGet, "/v3/user/tokens", nil, map[string]string{
 			"teamId": "team id",
 		})
 		require.NoError(t, err)
 		require.Equal(t, "Bearer foo", gotAuth)
-		require.Equal(t, "/v3/user/tokens", gotPath)
+		require.Equal(t, "/proxy/v3/user/tokens", gotPath)
 		require.Equal(t, "teamId=team+id", gotQuery)
 	})
 
 	t.Run("client without context", func(t *testing.T) {
 		hc := &http.Client{}
 
-		k := NewAPIClientWithBaseURL("foo", hc, ts.URL)
+		k := NewClient("foo", WithHTTPClient(hc), WithBaseURL(ts.URL))
 		//nolint:staticcheck
 		_, err := k.do(nil, http.MethodGet, "/", nil, nil)
 		require.Error(t, err)
 	})
 }
+
+func TestTrimAPIVersion(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input string
+		exp   string
+	}{
+		"legacy default":  {"https://api.vercel.com/v3", "https://api.vercel.com"},
+		"trailing slash":  {"https://api.vercel.com/v3/", "https://api.vercel.com"},
+		"origin":          {"https://api.vercel.com", "https://api.vercel.com"},
+		"proxy path":      {"https://example.com/vercel/v10", "https://example.com/vercel"},
+		"version in path": {"https://example.com/v3/proxy", "https://example.com/v3/proxy"},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.exp, TrimAPIVersion(tc.input))
+		})
+	}
+}
@@ -0,0 +1,30 @@
+// Package vercel is a client for the parts of the Vercel REST API used to
+// manage short-lived credentials: API tokens, teams, project environment
+// variables, deployment protection bypass secrets, Edge Config tokens and
+// webhooks. It is the client used by the Vault plugin, and is safe to import
+// from other programs.
+//
+// Create a client with an API key and options:
+//
+//	c := vercel.NewClient(apiKey, vercel.WithRetry(vercel.RetryPolicy{MaxAttempts: 3}))
+//
+//	res, err := c.CreateAuthToken(ctx, &vercel.CreateAuthTokenRequest{
+//		Name:   "ci",
+//		TeamID: "team_xxx",
+//	})
+//
+// Failed requests return an *HTTPError matching one of the sentinel errors,
+// such as ErrNotFound or ErrRateLimited, with errors.Is.
+//
+// List endpoints are paginated. AuthTokens and Teams iterate over all pages:
+//
+//	for t, err := range c.AuthTokens(ctx, 100) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(t.ID, t.Name)
+//	}
+//
+// The client tracks the rate limit budget Vercel reports per endpoint and
+// spreads requests out as a budget runs low. See Client.RateLimits.
+package vercel
@@ -0,0 +1,123 @@
+package vercel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// CreateEdgeConfigTokenRequest is the request of CreateEdgeConfigToken.
+type CreateEdgeConfigTokenRequest struct {
+	EdgeConfigID string `json:"-"`
+	// TeamID is the team owning the Edge Config store, if any.
+	TeamID string `json:"-"`
+	// Label describes the token in the Vercel dashboard.
+	Label string `json:"label"`
+}
+
+// CreateEdgeConfigTokenResponse is the response of CreateEdgeConfigToken.
+// Token is the secret value of the token and ID identifies it in the dashboard.
+type CreateEdgeConfigTokenResponse struct {
+	Token string `json:"token"`
+	ID    string `json:"id"`
+}
+
+// DeleteEdgeConfigTokensRequest is the request of DeleteEdgeConfigTokens.
+type DeleteEdgeConfigTokensRequest struct {
+	EdgeConfigID string `json:"-"`
+	// TeamID is the team owning the Edge Config store, if any.
+	TeamID string `json:"-"`
+	// Tokens are the values of the tokens to delete.
+	Tokens []string `json:"tokens"`
+}
+
+// CreateEdgeConfigToken creates a read access token for an Edge Config store.
+func (c *Client) CreateEdgeConfigToken(ctx context.Context,
+	req *CreateEdgeConfigTokenRequest) (*CreateEdgeConfigTokenResponse, error) {
+	resp := &CreateEdgeConfigTokenResponse{}
+
+	if req == nil {
+		return nil, errEmptyReq
+	}
+
+	if req.EdgeConfigID == "" {
+		return nil, errMissingEdgeConfigID
+	}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/v1/edge-config/%s/token", url.PathEscape(req.EdgeConfigID))
+
+	body, err := c.doEdgeConfig(ctx, http.MethodPost, path, req.TeamID, b)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Token == "" || resp.ID == "" {
+		return nil, errInvalidCreateEdgeConfigTokenResponse
+	}
+
+	return resp, nil
+}
+
+// DeleteEdgeConfigTokens deletes read access tokens of an Edge Config store.
+// Tokens are identified by their value.
+func (c *Client) DeleteEdgeConfigTokens(ctx context.Context, req *DeleteEdgeConfigTokensRequest) error {
+	if req == nil {
+		return errEmptyReq
+	}
+
+	if req.EdgeConfigID == "" {
+		return errMissingEdgeConfigID
+	}
+
+	if len(req.Tokens) == 0 {
+		return errMissingTokenID
+	}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/v1/edge-config/%s/tokens", url.PathEscape(req.EdgeConfigID))
+
+	_, err = c.doEdgeConfig(ctx, http.MethodDelete, path, req.TeamID, b)
+
+	return err
+}
+
+func (c *Client) doEdgeConfig(ctx context.Context, method, path, teamID string, b []byte) ([]byte, error) {
+	p := make(map[string]string, 1)
+	if teamID != "" {
+		p["teamId"] = teamID
+	}
+
+	res, err := c.do(ctx, method, path, b, p)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !successStatus(res.StatusCode) {
+		return nil, newHTTPErrorFromResponse(res, body)
+	}
+
+	return body, nil
+}
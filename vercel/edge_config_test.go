@@ -0,0 +1,109 @@
+package vercel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEdgeConfigToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("create and delete", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+				require.Equal(t, "team_a", r.URL.Query().Get("teamId"))
+
+				var body map[string]any
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+				switch r.Method {
+				case http.MethodPost:
+					require.Equal(t, "/v1/edge-config/ecfg_a/token", r.URL.EscapedPath())
+					require.Equal(t, map[string]any{"label": "foo"}, body)
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"token":"tok","id":"tok_id"}`))
+				case http.MethodDelete:
+					require.Equal(t, "/v1/edge-config/ecfg_a/tokens", r.URL.EscapedPath())
+					require.Equal(t, map[string]any{"tokens": []any{"tok"}}, body)
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}),
+		)
+		defer srv.Close()
+
+		c := NewClient("foo", WithBaseURL(srv.URL))
+
+		res, err := c.CreateEdgeConfigToken(ctx, &CreateEdgeConfigTokenRequest{
+			EdgeConfigID: "ecfg_a",
+			TeamID:       "team_a",
+			Label:        "foo",
+		})
+		require.NoError(t, err)
+		require.Equal(t, &CreateEdgeConfigTokenResponse{Token: "tok", ID: "tok_id"}, res)
+
+		require.NoError(t, c.DeleteEdgeConfigTokens(ctx, &DeleteEdgeConfigTokensRequest{
+			EdgeConfigID: "ecfg_a",
+			TeamID:       "team_a",
+			Tokens:       []string{"tok"},
+		}))
+	})
+
+	t.Run("invalid create response", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"id":"tok_id"}`))
+			}),
+		)
+		defer srv.Close()
+
+		c := NewClient("foo", WithBaseURL(srv.URL))
+		_, err := c.CreateEdgeConfigToken(ctx, &CreateEdgeConfigTokenRequest{EdgeConfigID: "ecfg_a", Label: "foo"})
+		require.ErrorIs(t, err, errInvalidCreateEdgeConfigTokenResponse)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":{"code":"not_found","message":"Edge Config not found"}}`))
+			}),
+		)
+		defer srv.Close()
+
+		c := NewClient("foo", WithBaseURL(srv.URL))
+		err := c.DeleteEdgeConfigTokens(ctx, &DeleteEdgeConfigTokensRequest{EdgeConfigID: "ecfg_a", Tokens: []string{"tok"}})
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("validates request", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewClient("foo", WithBaseURL("https://example.com"))
+
+		_, err := c.CreateEdgeConfigToken(ctx, nil)
+		require.ErrorIs(t, err, errEmptyReq)
+
+		_, err = c.CreateEdgeConfigToken(ctx, &CreateEdgeConfigTokenRequest{})
+		require.ErrorIs(t, err, errMissingEdgeConfigID)
+
+		require.ErrorIs(t, c.DeleteEdgeConfigTokens(ctx, &DeleteEdgeConfigTokensRequest{EdgeConfigID: "ecfg_a"}),
+			errMissingTokenID)
+	})
+}
@@ -0,0 +1,73 @@
+package vercel
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Sentinel errors classifying Vercel API failures. An *HTTPError matches
+// exactly one of them with errors.Is.
+var (
+	ErrInvalidAPIKey      = errors.New("vercel rejected the api key")
+	ErrForbidden          = errors.New("vercel denied access")
+	ErrSAMLReauthRequired = errors.New("vercel requires saml re-authentication")
+	ErrNotFound           = errors.New("vercel resource not found")
+	ErrRateLimited        = errors.New("vercel rate limit exceeded")
+	ErrBadRequest         = errors.New("vercel rejected the request")
+	ErrUpstream           = errors.New("vercel api unavailable")
+)
+
+type errorPayload struct {
+	Error struct {
+		Code         string `json:"code"`
+		Message      string `json:"message"`
+		SAML         bool   `json:"saml"`
+		InvalidToken bool   `json:"invalidToken"`
+	} `json:"error"`
+}
+
+// Kind returns the sentinel error matching the failure.
+func (e *HTTPError) Kind() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized, e.invalidToken:
+		return ErrInvalidAPIKey
+	case e.saml:
+		return ErrSAMLReauthRequired
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrUpstream
+	default:
+		return ErrBadRequest
+	}
+}
+
+// Unwrap returns the sentinel error matching the status, see Kind.
+func (e *HTTPError) Unwrap() error {
+	return e.Kind()
+}
+
+// retryAfter reads the wait time from Retry-After, falling back to X-RateLimit-Reset.
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	if v := h.Get("Retry-After"); v != "" {
+		if s, err := strconv.ParseInt(v, 10, 64); err == nil && s > 0 {
+			return time.Duration(s) * time.Second
+		}
+	}
+
+	if v := h.Get(headerRateLimitReset); v != "" {
+		if s, err := strconv.ParseInt(v, 10, 64); err == nil {
+			if d := time.Unix(s, 0).Sub(now); d > 0 {
+				return d
+			}
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,178 @@
+package vercel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
+)
+
+func TestHTTPError_Kind(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		status     int
+		body       string
+		expKind    error
+		expCode    string
+		expMessage string
+	}{
+		"unauthorized": {
+			status:  http.StatusUnauthorized,
+			expKind: ErrInvalidAPIKey,
+		},
+		"invalid token": {
+			status:     http.StatusForbidden,
+			body:       `{"error":{"code":"forbidden","message":"Not authorized","invalidToken":true}}`,
+			expKind:    ErrInvalidAPIKey,
+			expCode:    "forbidden",
+			expMessage: "Not authorized",
+		},
+		"team forbidden": {
+			status:  http.StatusForbidden,
+			body:    `{"error":{"code":"forbidden","message":"You don't have access to this team"}}`,
+			expKind: ErrForbidden,
+			expCode: "forbidden",
+		},
+		"saml": {
+			status:  http.StatusForbidden,
+			body:    `{"error":{"code":"forbidden","message":"SAML required","saml":true,"teamId":"team_a"}}`,
+			expKind: ErrSAMLReauthRequired,
+			expCode: "forbidden",
+		},
+		"not found": {
+			status:  http.StatusNotFound,
+			body:    `{"error":{"code":"not_found","message":"Token not found"}}`,
+			expKind: ErrNotFound,
+			expCode: "not_found",
+		},
+		"rate limited": {
+			status:  http.StatusTooManyRequests,
+			body:    `{"error":{"code":"rate_limited","message":"Rate limit exceeded"}}`,
+			expKind: ErrRateLimited,
+			expCode: "rate_limited",
+		},
+		"bad request": {
+			status:  http.StatusBadRequest,
+			body:    `{"error":{"code":"bad_request","message":"Invalid request"}}`,
+			expKind: ErrBadRequest,
+			expCode: "bad_request",
+		},
+		"upstream": {
+			status:  http.StatusBadGateway,
+			body:    `<html>bad gateway</html>`,
+			expKind: ErrUpstream,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := error(NewHTTPError(tc.status, []byte(tc.body)))
+			require.ErrorIs(t, err, tc.expKind)
+
+			var httpErr *HTTPError
+
+			require.True(t, errors.As(err, &httpErr))
+			require.Equal(t, tc.expCode, httpErr.Code)
+
+			if tc.expMessage != "" {
+				require.Equal(t, tc.expMessage, httpErr.Message)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+
+	cases := map[string]struct {
+		headers map[string]string
+		exp     time.Duration
+	}{
+		"no headers": {},
+		"retry after": {
+			headers: map[string]string{"Retry-After": "30"},
+			exp:     30 * time.Second,
+		},
+		"rate limit reset": {
+			headers: map[string]string{"X-RateLimit-Reset": "1700000010"},
+			exp:     10 * time.Second,
+		},
+		"rate limit reset in the past": {
+			headers: map[string]string{"X-RateLimit-Reset": "1699999990"},
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			h := http.Header{}
+			for k, v := range tc.headers {
+				h.Set(k, v)
+			}
+
+			require.Equal(t, tc.exp, retryAfter(h, now))
+		})
+	}
+}
+
+func TestErrors_FakeServer(t *testing.T) {
+	t.Parallel()
+
+	_, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{
+		APIKey: "root",
+		Teams:  []string{"team_a"},
+	})
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+
+	t.Run("invalid api key", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewClient("bogus", WithBaseURL(ts.URL))
+		_, err := c.CreateAuthToken(ctx, &CreateAuthTokenRequest{Name: "foo", TeamID: "team_a"})
+		require.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("team forbidden", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewClient("root", WithBaseURL(ts.URL))
+		_, err := c.CreateAuthToken(ctx, &CreateAuthTokenRequest{Name: "foo", TeamID: "team_b"})
+		require.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("rate limited and not found", func(t *testing.T) {
+		t.Parallel()
+
+		_, limited := fakevercel.NewHTTPTestServer(fakevercel.Config{
+			APIKey:    "root",
+			RateLimit: 1,
+		})
+		t.Cleanup(limited.Close)
+
+		c := NewClient("root", WithBaseURL(limited.URL))
+		_, err := c.DeleteAuthToken(ctx, &DeleteAuthTokenRequest{ID: "missing"})
+		require.ErrorIs(t, err, ErrNotFound)
+
+		_, err = c.DeleteAuthToken(ctx, &DeleteAuthTokenRequest{ID: "missing"})
+		require.ErrorIs(t, err, ErrRateLimited)
+
+		var httpErr *HTTPError
+
+		require.ErrorAs(t, err, &httpErr)
+		require.Positive(t, httpErr.RetryAfter)
+	})
+}
@@ -0,0 +1,138 @@
+package vercel
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL sets the base URL of the Vercel API, e.g. to talk to a fake
+// Vercel API in tests. The URL has no API version, as every endpoint
+// declares its own. Defaults to DefaultBaseURL.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to send requests. A client
+// without a timeout is copied and given a 60 second timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTracerProvider sets the OpenTelemetry tracer provider of the spans
+// created for every request. Defaults to the global tracer provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Client) {
+		c.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// WithRetry makes the client retry failed requests with the given policy.
+// Zero delays of the policy fall back to the defaults.
+func WithRetry(p RetryPolicy) Option {
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = defaultRetryBaseDelay
+	}
+
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = defaultRetryMaxDelay
+	}
+
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// RetryPolicy controls how failed requests are retried. Rate limited
+// requests are always retried, as Vercel did not process them. Requests that
+// failed with a 5xx status or a network error are retried only if they are
+// idempotent, so a token is never created twice.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts per request, including the
+	// first one. Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles with every
+	// further retry. Defaults to 500 milliseconds.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts. Requests Vercel asks to wait
+	// longer with Retry-After are not retried. Defaults to 30 seconds.
+	MaxDelay time.Duration
+}
+
+// next returns the delay before the next attempt, and whether to make one.
+func (p RetryPolicy) next(attempt int, method string, res *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	idempotent := method != http.MethodPost && method != http.MethodPatch
+
+	var wait time.Duration
+
+	switch {
+	case err != nil:
+		if !idempotent || !transient(err) {
+			return 0, false
+		}
+	case res.StatusCode == http.StatusTooManyRequests:
+		wait = retryAfter(res.Header, time.Now())
+	case res.StatusCode >= http.StatusInternalServerError:
+		if !idempotent {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+
+	d = min(d, p.MaxDelay)
+
+	if wait > p.MaxDelay {
+		return 0, false
+	}
+
+	return max(d, wait), true
+}
+
+// transient reports whether err is a network error worth retrying.
+func transient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
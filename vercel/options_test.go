@@ -0,0 +1,191 @@
+package vercel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewClient_Options(t *testing.T) {
+	t.Parallel()
+
+	hc := &http.Client{Timeout: time.Second}
+	c := NewClient("foo",
+		WithBaseURL("http://example.com"),
+		WithHTTPClient(hc),
+		WithRetry(RetryPolicy{MaxAttempts: 3}),
+	)
+
+	require.Equal(t, "http://example.com", c.GetBaseURL())
+	require.Same(t, hc, c.httpClient)
+	require.Equal(t, RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   defaultRetryBaseDelay,
+		MaxDelay:    defaultRetryMaxDelay,
+	}, c.retry)
+}
+
+func TestClient_TracerProvider(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(ts.Close)
+
+	sr := tracetest.NewSpanRecorder()
+	c := NewClient("foo",
+		WithBaseURL(ts.URL),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))),
+	)
+
+	_, err := c.GetAuthToken(context.Background(), &GetAuthTokenRequest{ID: "tok_a"})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "vercel GET", spans[0].Name())
+	require.Equal(t, tracerName, spans[0].InstrumentationScope().Name)
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestRetryPolicy_Next(t *testing.T) {
+	t.Parallel()
+
+	p := RetryPolicy{MaxAttempts: 4, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	response := func(status int, retryAfter string) *http.Response {
+		h := http.Header{}
+		if retryAfter != "" {
+			h.Set("Retry-After", retryAfter)
+		}
+
+		return &http.Response{StatusCode: status, Header: h}
+	}
+
+	netErr := &netError{}
+
+	cases := map[string]struct {
+		policy   RetryPolicy
+		attempt  int
+		method   string
+		res      *http.Response
+		err      error
+		expDelay time.Duration
+		expRetry bool
+	}{
+		"success": {
+			policy: p, attempt: 1, method: http.MethodGet, res: response(http.StatusOK, ""),
+		},
+		"not found": {
+			policy: p, attempt: 1, method: http.MethodGet, res: response(http.StatusNotFound, ""),
+		},
+		"get unavailable": {
+			policy: p, attempt: 1, method: http.MethodGet, res: response(http.StatusServiceUnavailable, ""),
+			expDelay: time.Second, expRetry: true,
+		},
+		"backoff doubles": {
+			policy: p, attempt: 3, method: http.MethodDelete, res: response(http.StatusBadGateway, ""),
+			expDelay: 4 * time.Second, expRetry: true,
+		},
+		"post unavailable": {
+			policy: p, attempt: 1, method: http.MethodPost, res: response(http.StatusServiceUnavailable, ""),
+		},
+		"post rate limited": {
+			policy: p, attempt: 1, method: http.MethodPost, res: response(http.StatusTooManyRequests, "2"),
+			expDelay: 2 * time.Second, expRetry: true,
+		},
+		"rate limited too long": {
+			policy: p, attempt: 1, method: http.MethodGet, res: response(http.StatusTooManyRequests, "60"),
+		},
+		"get network error": {
+			policy: p, attempt: 1, method: http.MethodGet, err: fmt.Errorf("get: %w", netErr),
+			expDelay: time.Second, expRetry: true,
+		},
+		"post network error": {
+			policy: p, attempt: 1, method: http.MethodPost, err: netErr,
+		},
+		"canceled": {
+			policy: p, attempt: 1, method: http.MethodGet, err: context.Canceled,
+		},
+		"other error": {
+			policy: p, attempt: 1, method: http.MethodGet, err: errors.New("invalid base url"),
+		},
+		"attempts exhausted": {
+			policy: p, attempt: 4, method: http.MethodGet, res: response(http.StatusServiceUnavailable, ""),
+		},
+		"retries disabled": {
+			attempt: 1, method: http.MethodGet, res: response(http.StatusServiceUnavailable, ""),
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			d, retry := tc.policy.next(tc.attempt, tc.method, tc.res, tc.err)
+			require.Equal(t, tc.expRetry, retry)
+			require.Equal(t, tc.expDelay, d)
+		})
+	}
+}
+
+type netError struct{}
+
+func (e *netError) Error() string   { return "connection reset" }
+func (e *netError) Timeout() bool   { return false }
+func (e *netError) Temporary() bool { return true }
+
+func TestClient_Retry(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+
+		if r.Method == http.MethodGet && n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"token":{"id":"tok_a","name":"foo"}}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	c := NewClient("foo",
+		WithBaseURL(ts.URL),
+		WithRetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+	)
+
+	res, err := c.GetAuthToken(ctx, &GetAuthTokenRequest{ID: "tok_a"})
+	require.NoError(t, err)
+	require.Equal(t, "tok_a", res.Token.ID)
+	require.EqualValues(t, 3, calls.Load())
+
+	// Token creation is not idempotent, so it is not retried.
+	calls.Store(0)
+
+	_, err = c.CreateAuthToken(ctx, &CreateAuthTokenRequest{Name: "foo"})
+	require.ErrorIs(t, err, ErrUpstream)
+	require.EqualValues(t, 1, calls.Load())
+}
@@ -0,0 +1,85 @@
+package vercel
+
+import (
+	"context"
+	"iter"
+)
+
+// AuthTokenLister lists a page of API tokens. It is implemented by Client.
+type AuthTokenLister interface {
+	ListAuthTokens(ctx context.Context, req *ListAuthTokensRequest) (*ListAuthTokensResponse, error)
+}
+
+// TeamLister lists a page of teams. It is implemented by Client.
+type TeamLister interface {
+	ListTeams(ctx context.Context, req *ListTeamsRequest) (*ListTeamsResponse, error)
+}
+
+// AuthTokens iterates over every API token of the API key, fetching pages of
+// pageSize tokens as needed. Zero uses the Vercel default page size. The
+// iteration stops after yielding the first error.
+func AuthTokens(ctx context.Context, l AuthTokenLister, pageSize int) iter.Seq2[Token, error] {
+	return paginate(func(until int64) ([]Token, *int64, error) {
+		res, err := l.ListAuthTokens(ctx, &ListAuthTokensRequest{Limit: pageSize, Until: until})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.Tokens, res.Pagination.Next, nil
+	})
+}
+
+// Teams iterates over every team the API key has access to, fetching pages of
+// pageSize teams as needed. Zero uses the Vercel default page size. The
+// iteration stops after yielding the first error.
+func Teams(ctx context.Context, l TeamLister, pageSize int) iter.Seq2[Team, error] {
+	return paginate(func(until int64) ([]Team, *int64, error) {
+		res, err := l.ListTeams(ctx, &ListTeamsRequest{Limit: pageSize, Until: until})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res.Teams, res.Pagination.Next, nil
+	})
+}
+
+// AuthTokens iterates over every API token of the API key. See AuthTokens.
+func (c *Client) AuthTokens(ctx context.Context, pageSize int) iter.Seq2[Token, error] {
+	return AuthTokens(ctx, c, pageSize)
+}
+
+// Teams iterates over every team the API key has access to. See Teams.
+func (c *Client) Teams(ctx context.Context, pageSize int) iter.Seq2[Team, error] {
+	return Teams(ctx, c, pageSize)
+}
+
+// paginate walks the pages returned by page, starting without a cursor and
+// continuing with the next cursor until a page is empty or has none.
+func paginate[T any](page func(until int64) ([]T, *int64, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var until int64
+
+		for {
+			items, next, err := page(until)
+			if err != nil {
+				var zero T
+
+				yield(zero, err)
+
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if next == nil || len(items) == 0 {
+				return
+			}
+
+			until = *next
+		}
+	}
+}
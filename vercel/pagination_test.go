@@ -0,0 +1,81 @@
+package vercel
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
+)
+
+func TestAuthTokens(t *testing.T) {
+	t.Parallel()
+
+	_, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{APIKey: "root"})
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	c := NewClient("root", WithBaseURL(ts.URL))
+
+	for i := 0; i < 5; i++ {
+		_, err := c.CreateAuthToken(ctx, &CreateAuthTokenRequest{Name: fmt.Sprintf("token-%d", i)})
+		require.NoError(t, err)
+	}
+
+	ids := make(map[string]bool)
+
+	for tok, err := range c.AuthTokens(ctx, 2) {
+		require.NoError(t, err)
+
+		ids[tok.ID] = true
+	}
+
+	require.Len(t, ids, 5)
+
+	// Iteration stops early when the loop breaks.
+	n := 0
+
+	for _, err := range c.AuthTokens(ctx, 2) {
+		require.NoError(t, err)
+
+		n++
+		if n == 3 {
+			break
+		}
+	}
+
+	require.Equal(t, 3, n)
+
+	var errs []error
+
+	for _, err := range NewClient("bogus", WithBaseURL(ts.URL)).AuthTokens(ctx, 2) {
+		errs = append(errs, err)
+	}
+
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], ErrInvalidAPIKey)
+}
+
+func TestTeams(t *testing.T) {
+	t.Parallel()
+
+	_, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{
+		APIKey: "root",
+		Teams:  []string{"team_a", "team_b", "team_c"},
+	})
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	c := NewClient("root", WithBaseURL(ts.URL))
+
+	var ids []string
+
+	for team, err := range c.Teams(ctx, 2) {
+		require.NoError(t, err)
+
+		ids = append(ids, team.ID)
+	}
+
+	require.ElementsMatch(t, []string{"team_a", "team_b", "team_c"}, ids)
+}
@@ -0,0 +1,120 @@
+package vercel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ListProjectEnvRequest is the request of ListProjectEnv.
+type ListProjectEnvRequest struct {
+	ProjectID string
+	// TeamID is the team owning the project, if any.
+	TeamID string
+	// GitBranch limits the variables to those of a preview branch.
+	GitBranch string
+}
+
+// ListProjectEnvResponse is the response of ListProjectEnv.
+type ListProjectEnvResponse struct {
+	Envs []ProjectEnv `json:"envs"`
+}
+
+// ProjectEnv is an environment variable of a project. Value holds the
+// decrypted value when Decrypted is set.
+type ProjectEnv struct {
+	ID        string    `json:"id"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	Type      string    `json:"type"`
+	Target    EnvTarget `json:"target"`
+	GitBranch string    `json:"gitBranch,omitempty"`
+	Decrypted bool      `json:"decrypted"`
+}
+
+// EnvTarget lists the environments a variable applies to. Vercel returns
+// either a single string or an array of strings.
+type EnvTarget []string
+
+// UnmarshalJSON accepts both a single target and a list of targets.
+func (t *EnvTarget) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = EnvTarget{s}
+
+		return nil
+	}
+
+	var ss []string
+	if err := json.Unmarshal(b, &ss); err != nil {
+		return err
+	}
+
+	*t = ss
+
+	return nil
+}
+
+// Has reports whether the variable applies to the given environment.
+func (t EnvTarget) Has(target string) bool {
+	for _, v := range t {
+		if v == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListProjectEnv returns the environment variables of a project with their values decrypted.
+func (c *Client) ListProjectEnv(ctx context.Context,
+	req *ListProjectEnvRequest) (*ListProjectEnvResponse, error) {
+	resp := &ListProjectEnvResponse{}
+
+	if req == nil {
+		return nil, errEmptyReq
+	}
+
+	if req.ProjectID == "" {
+		return nil, errMissingProjectID
+	}
+
+	p := map[string]string{
+		"decrypt": "true",
+	}
+
+	if req.TeamID != "" {
+		p["teamId"] = req.TeamID
+	}
+
+	if req.GitBranch != "" {
+		p["gitBranch"] = req.GitBranch
+	}
+
+	path := fmt.Sprintf("/v10/projects/%s/env", url.PathEscape(req.ProjectID))
+
+	res, err := c.do(ctx, http.MethodGet, path, nil, p)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !successStatus(res.StatusCode) {
+		return nil, newHTTPErrorFromResponse(res, body)
+	}
+
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
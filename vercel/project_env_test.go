@@ -0,0 +1,78 @@
+package vercel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListProjectEnv(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("decrypts and replaces base url version", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+				require.Equal(t, "/v10/projects/prj_a/env", r.URL.EscapedPath())
+				require.Equal(t, "true", r.URL.Query().Get("decrypt"))
+				require.Equal(t, "team_a", r.URL.Query().Get("teamId"))
+				require.Equal(t, "main", r.URL.Query().Get("gitBranch"))
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"envs":[` +
+					`{"id":"1","key":"A","value":"a","type":"encrypted","target":["production","preview"],"decrypted":true},` +
+					`{"id":"2","key":"B","value":"b","type":"plain","target":"preview","gitBranch":"main"}]}`))
+			}),
+		)
+		defer srv.Close()
+
+		c := NewClient("foo", WithBaseURL(srv.URL))
+		res, err := c.ListProjectEnv(ctx, &ListProjectEnvRequest{
+			ProjectID: "prj_a",
+			TeamID:    "team_a",
+			GitBranch: "main",
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Envs, 2)
+		require.Equal(t, EnvTarget{"production", "preview"}, res.Envs[0].Target)
+		require.True(t, res.Envs[1].Target.Has("preview"))
+		require.False(t, res.Envs[1].Target.Has("production"))
+		require.Equal(t, "main", res.Envs[1].GitBranch)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":{"code":"not_found","message":"Project not found"}}`))
+			}),
+		)
+		defer srv.Close()
+
+		c := NewClient("foo", WithBaseURL(srv.URL))
+		res, err := c.ListProjectEnv(ctx, &ListProjectEnvRequest{ProjectID: "prj_a"})
+		require.Nil(t, res)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("validates request", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewClient("foo", WithBaseURL("https://example.com"))
+
+		_, err := c.ListProjectEnv(ctx, nil)
+		require.ErrorIs(t, err, errEmptyReq)
+
+		_, err = c.ListProjectEnv(ctx, &ListProjectEnvRequest{})
+		require.ErrorIs(t, err, errMissingProjectID)
+	})
+}
@@ -0,0 +1,148 @@
+package vercel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// CreateProtectionBypassRequest is the request of CreateProtectionBypass.
+type CreateProtectionBypassRequest struct {
+	ProjectID string
+	// TeamID is the team owning the project, if any.
+	TeamID string
+	// Secret is the 32 character bypass secret to create. Vercel generates
+	// one when empty.
+	Secret string
+	// Note describes the secret in the Vercel dashboard.
+	Note string
+}
+
+// RevokeProtectionBypassRequest is the request of RevokeProtectionBypass.
+type RevokeProtectionBypassRequest struct {
+	ProjectID string
+	// TeamID is the team owning the project, if any.
+	TeamID string
+	// Secret is the bypass secret to remove.
+	Secret string
+}
+
+// ProtectionBypassResponse lists the bypass secrets of a project after the
+// update, keyed by secret.
+type ProtectionBypassResponse struct {
+	ProtectionBypass map[string]ProtectionBypass `json:"protectionBypass"`
+}
+
+// ProtectionBypass is a Protection Bypass for Automation secret of a project.
+type ProtectionBypass struct {
+	// CreatedAt is a Unix timestamp in milliseconds.
+	CreatedAt int64  `json:"createdAt"`
+	CreatedBy string `json:"createdBy"`
+	Scope     string `json:"scope"`
+	Note      string `json:"note,omitempty"`
+}
+
+type protectionBypassGenerate struct {
+	Secret string `json:"secret,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+type protectionBypassRevoke struct {
+	Secret     string `json:"secret"`
+	Regenerate bool   `json:"regenerate"`
+}
+
+type protectionBypassUpdate struct {
+	Generate *protectionBypassGenerate `json:"generate,omitempty"`
+	Revoke   *protectionBypassRevoke   `json:"revoke,omitempty"`
+}
+
+// CreateProtectionBypass adds a Protection Bypass for Automation secret to a project.
+func (c *Client) CreateProtectionBypass(ctx context.Context,
+	req *CreateProtectionBypassRequest) (*ProtectionBypassResponse, error) {
+	if req == nil {
+		return nil, errEmptyReq
+	}
+
+	resp, err := c.updateProtectionBypass(ctx, req.ProjectID, req.TeamID, &protectionBypassUpdate{
+		Generate: &protectionBypassGenerate{
+			Secret: req.Secret,
+			Note:   req.Note,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Secret != "" {
+		if _, ok := resp.ProtectionBypass[req.Secret]; !ok {
+			return nil, errInvalidProtectionBypassResponse
+		}
+	}
+
+	return resp, nil
+}
+
+// RevokeProtectionBypass removes a Protection Bypass for Automation secret from a project.
+func (c *Client) RevokeProtectionBypass(ctx context.Context,
+	req *RevokeProtectionBypassRequest) (*ProtectionBypassResponse, error) {
+	if req == nil {
+		return nil, errEmptyReq
+	}
+
+	if req.Secret == "" {
+		return nil, errMissingBypassSecret
+	}
+
+	return c.updateProtectionBypass(ctx, req.ProjectID, req.TeamID, &protectionBypassUpdate{
+		Revoke: &protectionBypassRevoke{
+			Secret: req.Secret,
+		},
+	})
+}
+
+func (c *Client) updateProtectionBypass(ctx context.Context, projectID, teamID string,
+	update *protectionBypassUpdate) (*ProtectionBypassResponse, error) {
+	resp := &ProtectionBypassResponse{}
+
+	if projectID == "" {
+		return nil, errMissingProjectID
+	}
+
+	b, err := json.Marshal(update)
+	if err != nil {
+		return nil, err
+	}
+
+	p := make(map[string]string, 1)
+	if teamID != "" {
+		p["teamId"] = teamID
+	}
+
+	path := fmt.Sprintf("/v1/projects/%s/protection-bypass", url.PathEscape(projectID))
+
+	res, err := c.do(ctx, http.MethodPatch, path, b, p)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !successStatus(res.StatusCode) {
+		return nil, newHTTPErrorFromResponse(res, body)
+	}
+
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,104 @@
+package vercel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtectionBypass(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("generate and revoke", func(t *testing.T) {
+		t.Parallel()
+
+		var bodies []map[string]any
+
+		srv := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+				require.Equal(t, http.MethodPatch, r.Method)
+				require.Equal(t, "/v1/projects/prj_a/protection-bypass", r.URL.EscapedPath())
+				require.Equal(t, "team_a", r.URL.Query().Get("teamId"))
+
+				var body map[string]any
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				bodies = append(bodies, body)
+
+				w.WriteHeader(http.StatusOK)
+
+				if _, ok := body["generate"]; ok {
+					_, _ = w.Write([]byte(`{"protectionBypass":{"s3cr3t":` +
+						`{"createdAt":1,"createdBy":"usr","scope":"automation-bypass","note":"e2e"}}}`))
+
+					return
+				}
+
+				_, _ = w.Write([]byte(`{"protectionBypass":{}}`))
+			}),
+		)
+		defer srv.Close()
+
+		c := NewClient("foo", WithBaseURL(srv.URL))
+
+		res, err := c.CreateProtectionBypass(ctx, &CreateProtectionBypassRequest{
+			ProjectID: "prj_a",
+			TeamID:    "team_a",
+			Secret:    "s3cr3t",
+			Note:      "e2e",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "automation-bypass", res.ProtectionBypass["s3cr3t"].Scope)
+
+		res, err = c.RevokeProtectionBypass(ctx, &RevokeProtectionBypassRequest{
+			ProjectID: "prj_a",
+			TeamID:    "team_a",
+			Secret:    "s3cr3t",
+		})
+		require.NoError(t, err)
+		require.Empty(t, res.ProtectionBypass)
+
+		require.Equal(t, []map[string]any{
+			{"generate": map[string]any{"secret": "s3cr3t", "note": "e2e"}},
+			{"revoke": map[string]any{"secret": "s3cr3t", "regenerate": false}},
+		}, bodies)
+	})
+
+	t.Run("generated secret missing from response", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"protectionBypass":{"other":{"scope":"automation-bypass"}}}`))
+			}),
+		)
+		defer srv.Close()
+
+		c := NewClient("foo", WithBaseURL(srv.URL))
+		_, err := c.CreateProtectionBypass(ctx, &CreateProtectionBypassRequest{ProjectID: "prj_a", Secret: "s3cr3t"})
+		require.ErrorIs(t, err, errInvalidProtectionBypassResponse)
+	})
+
+	t.Run("validates request", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewClient("foo", WithBaseURL("https://example.com"))
+
+		_, err := c.CreateProtectionBypass(ctx, nil)
+		require.ErrorIs(t, err, errEmptyReq)
+
+		_, err = c.CreateProtectionBypass(ctx, &CreateProtectionBypassRequest{})
+		require.ErrorIs(t, err, errMissingProjectID)
+
+		_, err = c.RevokeProtectionBypass(ctx, &RevokeProtectionBypassRequest{ProjectID: "prj_a"})
+		require.ErrorIs(t, err, errMissingBypassSecret)
+	})
+}
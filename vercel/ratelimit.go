@@ -0,0 +1,222 @@
+package vercel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+	// rateLimitLowWatermark is the share of an endpoint budget below which
+	// requests are spread evenly over the rest of the rate limit window.
+	rateLimitLowWatermark = 0.1
+	// maxRateLimitWait caps how long a request waits for budget. Requests
+	// that would wait longer fail right away.
+	maxRateLimitWait = 30 * time.Second
+)
+
+// idCollections are the path segments followed by a resource ID.
+var idCollections = map[string]bool{
+	"tokens":      true,
+	"projects":    true,
+	"edge-config": true,
+	"webhooks":    true,
+}
+
+// RateLimit is the request budget of an endpoint as last reported by Vercel.
+type RateLimit struct {
+	// Endpoint is the method and path of the endpoint, with resource IDs
+	// replaced by ":id", e.g. "DELETE /user/tokens/:id".
+	Endpoint  string
+	Limit     int
+	Remaining int
+	// Reset is when Vercel restores the budget.
+	Reset time.Time
+}
+
+type errorBody struct {
+	Error errorMessage `json:"error"`
+}
+
+type errorMessage struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type rateLimitBucket struct {
+	RateLimit
+	// next is the earliest time the next paced request may be sent.
+	next time.Time
+}
+
+// rateLimiter tracks the request budget per endpoint from the rate limit
+// headers of Vercel responses, and delays requests as a budget runs out.
+type rateLimiter struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	buckets map[string]*rateLimitBucket
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		now:     time.Now,
+		buckets: make(map[string]*rateLimitBucket),
+	}
+}
+
+// rateLimitKey returns the endpoint an API path counts against. Vercel
+// applies rate limits per endpoint regardless of API version and resource.
+func rateLimitKey(method, endpoint string) string {
+	path := strings.Trim(endpointVersionPrefix.ReplaceAllString(endpoint, ""), "/")
+	segs := strings.Split(path, "/")
+
+	for i := 1; i < len(segs); i++ {
+		if idCollections[segs[i-1]] {
+			segs[i] = ":id"
+		}
+	}
+
+	return method + " /" + strings.Join(segs, "/")
+}
+
+// reserve takes a request from the budget of the endpoint and returns how
+// long to wait before sending it. Endpoints without a known budget, or whose
+// window has reset, are not delayed.
+func (l *rateLimiter) reserve(key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	b, ok := l.buckets[key]
+	if !ok || !now.Before(b.Reset) {
+		return 0, nil
+	}
+
+	untilReset := b.Reset.Sub(now)
+
+	if b.Remaining <= 0 {
+		if untilReset > maxRateLimitWait {
+			return 0, rateLimitedLocally(key, untilReset)
+		}
+
+		return untilReset, nil
+	}
+
+	var wait time.Duration
+
+	if float64(b.Remaining) <= float64(b.Limit)*rateLimitLowWatermark {
+		slot := now
+		if b.next.After(slot) {
+			slot = b.next
+		}
+
+		b.next = slot.Add(untilReset / time.Duration(b.Remaining+1))
+		wait = slot.Sub(now)
+	}
+
+	b.Remaining--
+
+	return wait, nil
+}
+
+// wait blocks until the endpoint has budget for a request.
+func (l *rateLimiter) wait(ctx context.Context, key string) error {
+	d, err := l.reserve(key)
+	if err != nil || d <= 0 {
+		return err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(l.now().Add(d)) {
+		return rateLimitedLocally(key, d)
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// observe records the budget reported by a response. Rate limited responses
+// without rate limit headers exhaust the budget until Retry-After.
+func (l *rateLimiter) observe(key string, status int, h http.Header) {
+	now := l.now()
+
+	limit, limitErr := strconv.Atoi(h.Get(headerRateLimitLimit))
+	remaining, remainingErr := strconv.Atoi(h.Get(headerRateLimitRemaining))
+	reset, resetErr := strconv.ParseInt(h.Get(headerRateLimitReset), 10, 64)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &rateLimitBucket{RateLimit: RateLimit{Endpoint: key}}
+		l.buckets[key] = b
+	}
+
+	switch {
+	case limitErr == nil && remainingErr == nil && resetErr == nil:
+		b.Limit, b.Remaining, b.Reset = limit, remaining, time.Unix(reset, 0)
+	case status == http.StatusTooManyRequests:
+		b.Remaining, b.Reset = 0, now.Add(retryAfter(h, now))
+	default:
+		if !ok {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// snapshot returns the budgets of endpoints whose window has not reset yet.
+func (l *rateLimiter) snapshot() []RateLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	out := make([]RateLimit, 0, len(l.buckets))
+
+	for _, b := range l.buckets {
+		if now.Before(b.Reset) {
+			out = append(out, b.RateLimit)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Endpoint < out[j].Endpoint
+	})
+
+	return out
+}
+
+// rateLimitedLocally returns the error of a request held back by the client
+// because the endpoint budget is exhausted. It matches ErrRateLimited like a
+// rate limited response from Vercel.
+func rateLimitedLocally(key string, retryAfter time.Duration) *HTTPError {
+	msg := fmt.Sprintf("request budget of %s is exhausted, request was not sent", key)
+	body, _ := json.Marshal(errorBody{Error: errorMessage{Code: "client_rate_limited", Message: msg}})
+
+	e := NewHTTPError(http.StatusTooManyRequests, body)
+	e.RetryAfter = retryAfter
+
+	return e
+}
+
+// RateLimits returns the request budget of each endpoint used by the client,
+// as last reported by Vercel. Endpoints whose window has reset are omitted.
+func (c *Client) RateLimits() []RateLimit {
+	return c.limiter.snapshot()
+}
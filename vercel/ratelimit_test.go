@@ -0,0 +1,178 @@
+package vercel
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
+)
+
+func TestRateLimitKey(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		method   string
+		endpoint string
+		exp      string
+	}{
+		"create token":  {http.MethodPost, "/v3/user/tokens", "POST /user/tokens"},
+		"list tokens":   {http.MethodGet, "/v5/user/tokens", "GET /user/tokens"},
+		"delete token":  {http.MethodDelete, "/v3/user/tokens/tok_a", "DELETE /user/tokens/:id"},
+		"project env":   {http.MethodGet, "/v10/projects/prj_a/env", "GET /projects/:id/env"},
+		"edge config":   {http.MethodPost, "/v1/edge-config/ecfg_a/token", "POST /edge-config/:id/token"},
+		"delete hook":   {http.MethodDelete, "/v1/webhooks/hook_a", "DELETE /webhooks/:id"},
+		"list teams":    {http.MethodGet, "/v2/teams", "GET /teams"},
+		"without slash": {http.MethodGet, "v2/teams", "GET /teams"},
+	}
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.exp, rateLimitKey(tc.method, tc.endpoint))
+		})
+	}
+}
+
+func TestRateLimiter_Reserve(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	l := newRateLimiter()
+	l.now = func() time.Time { return now }
+
+	headers := func(limit, remaining int, reset time.Time) http.Header {
+		h := http.Header{}
+		h.Set(headerRateLimitLimit, strconv.Itoa(limit))
+		h.Set(headerRateLimitRemaining, strconv.Itoa(remaining))
+		h.Set(headerRateLimitReset, strconv.FormatInt(reset.Unix(), 10))
+
+		return h
+	}
+
+	const key = "POST /user/tokens"
+
+	// Unknown endpoints are not delayed.
+	d, err := l.reserve(key)
+	require.NoError(t, err)
+	require.Zero(t, d)
+
+	l.observe(key, http.StatusOK, headers(100, 50, now.Add(time.Minute)))
+
+	d, err = l.reserve(key)
+	require.NoError(t, err)
+	require.Zero(t, d)
+	require.Equal(t, []RateLimit{{Endpoint: key, Limit: 100, Remaining: 49, Reset: now.Add(time.Minute)}},
+		l.snapshot())
+
+	// Below the low watermark the rest of the budget is spread over the window.
+	l.observe(key, http.StatusOK, headers(100, 5, now.Add(time.Minute)))
+
+	for _, exp := range []time.Duration{0, 10 * time.Second, 22 * time.Second} {
+		d, err = l.reserve(key)
+		require.NoError(t, err)
+		require.Equal(t, exp, d)
+	}
+
+	// An exhausted budget waits for the reset, unless the reset is too far away.
+	l.observe(key, http.StatusOK, headers(100, 0, now.Add(10*time.Second)))
+
+	d, err = l.reserve(key)
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Second, d)
+
+	l.observe(key, http.StatusOK, headers(100, 0, now.Add(time.Hour)))
+
+	_, err = l.reserve(key)
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	var httpErr *HTTPError
+
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, time.Hour, httpErr.RetryAfter)
+
+	// Rate limited responses without headers use Retry-After.
+	h := http.Header{}
+	h.Set("Retry-After", "5")
+	l.observe("GET /teams", http.StatusTooManyRequests, h)
+
+	d, err = l.reserve("GET /teams")
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Second, d)
+
+	// Budgets whose window has reset no longer apply.
+	now = now.Add(2 * time.Hour)
+
+	d, err = l.reserve(key)
+	require.NoError(t, err)
+	require.Zero(t, d)
+	require.Empty(t, l.snapshot())
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	t.Parallel()
+
+	l := newRateLimiter()
+	l.buckets["GET /teams"] = &rateLimitBucket{RateLimit: RateLimit{
+		Endpoint: "GET /teams",
+		Limit:    10,
+		Reset:    time.Now().Add(20 * time.Second),
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// The budget cannot be available before the deadline, so the request fails right away.
+	err := l.wait(ctx, "GET /teams")
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	require.ErrorIs(t, l.wait(ctx, "GET /teams"), context.Canceled)
+}
+
+func TestClient_RateLimits(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{
+		APIKey:          "root",
+		RateLimit:       2,
+		RateLimitWindow: time.Hour,
+	})
+	t.Cleanup(ts.Close)
+
+	c := NewClient("root", WithBaseURL(ts.URL))
+	require.Empty(t, c.RateLimits())
+
+	for i := 0; i < 2; i++ {
+		_, err := c.ListTeams(ctx, &ListTeamsRequest{})
+		require.NoError(t, err)
+	}
+
+	limits := c.RateLimits()
+	require.Len(t, limits, 1)
+	require.Equal(t, "GET /teams", limits[0].Endpoint)
+	require.Equal(t, 2, limits[0].Limit)
+	require.Zero(t, limits[0].Remaining)
+
+	// The exhausted budget is known, so the request is not sent.
+	_, err := c.ListTeams(ctx, &ListTeamsRequest{})
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	var httpErr *HTTPError
+
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, "client_rate_limited", httpErr.Code)
+
+	// Other endpoints have their own budget.
+	_, err = c.ListAuthTokens(ctx, &ListAuthTokensRequest{})
+	require.NoError(t, err)
+}
@@ -0,0 +1,70 @@
+package vercel
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Team is a Vercel team the API key is a member of.
+type Team struct {
+	ID   string `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// ListTeamsRequest is the request of ListTeams.
+type ListTeamsRequest struct {
+	// Limit is the page size. Zero uses the Vercel default.
+	Limit int
+	// Until is the pagination cursor returned as Pagination.Next by the previous page.
+	Until int64
+}
+
+// ListTeamsResponse is a page of teams returned by ListTeams.
+type ListTeamsResponse struct {
+	Teams      []Team     `json:"teams"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// ListTeams returns a page of the teams the API key has access to.
+func (c *Client) ListTeams(ctx context.Context, req *ListTeamsRequest) (*ListTeamsResponse, error) {
+	resp := &ListTeamsResponse{}
+
+	if req == nil {
+		return nil, errEmptyReq
+	}
+
+	p := make(map[string]string, 2)
+	if req.Limit > 0 {
+		p["limit"] = strconv.Itoa(req.Limit)
+	}
+
+	if req.Until > 0 {
+		p["until"] = strconv.FormatInt(req.Until, 10)
+	}
+
+	res, err := c.do(ctx, http.MethodGet, "/v2/teams", nil, p)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !successStatus(res.StatusCode) {
+		return nil, newHTTPErrorFromResponse(res, body)
+	}
+
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,54 @@
+package vercel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTeams(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Helper()
+			require.Equal(t, http.MethodGet, r.Method)
+			require.Equal(t, "/v2/teams", r.URL.EscapedPath())
+			require.Equal(t, "10", r.URL.Query().Get("limit"))
+
+			if r.URL.Query().Get("until") == "" {
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"teams":[{"id":"team_a","slug":"acme","name":"Acme"}],` +
+					`"pagination":{"count":1,"next":1700000000000,"prev":1800000000000}}`))
+
+				return
+			}
+
+			require.Equal(t, "1700000000000", r.URL.Query().Get("until"))
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"teams":[{"id":"team_b","slug":"globex","name":"Globex"}],` +
+				`"pagination":{"count":1,"next":null,"prev":1700000000000}}`))
+		}),
+	)
+	defer srv.Close()
+
+	c := NewClient("foo", WithBaseURL(srv.URL))
+
+	res, err := c.ListTeams(ctx, &ListTeamsRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []Team{{ID: "team_a", Slug: "acme", Name: "Acme"}}, res.Teams)
+	require.NotNil(t, res.Pagination.Next)
+
+	res, err = c.ListTeams(ctx, &ListTeamsRequest{Limit: 10, Until: *res.Pagination.Next})
+	require.NoError(t, err)
+	require.Equal(t, []Team{{ID: "team_b", Slug: "globex", Name: "Globex"}}, res.Teams)
+	require.Nil(t, res.Pagination.Next)
+
+	_, err = c.ListTeams(ctx, nil)
+	require.ErrorIs(t, err, errEmptyReq)
+}
@@ -0,0 +1,285 @@
+package vercel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateAuthTokenRequest is the request of CreateAuthToken.
+type CreateAuthTokenRequest struct {
+	// Name is the name of the token, shown in the Vercel dashboard.
+	Name string `json:"name"`
+	// ExpiresAt is when the token expires, as a Unix timestamp in milliseconds.
+	// Zero creates a token that does not expire.
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+	// TeamID scopes the token to a team. Empty creates a token with personal
+	// account scope.
+	TeamID string `json:"-"`
+}
+
+// CreateAuthTokenResponse is the response of CreateAuthToken. BearerToken is
+// the secret value of the token and is returned only once.
+type CreateAuthTokenResponse struct {
+	Token       Token  `json:"token"`
+	BearerToken string `json:"bearerToken"`
+}
+
+// Token is the metadata of a Vercel access token. It never holds the secret
+// value of the token.
+type Token struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Origin string `json:"origin"`
+	// CreatedAt, ActiveAt and ExpiresAt are Unix timestamps in milliseconds.
+	// ActiveAt is when the token was last used, and equals CreatedAt for unused tokens.
+	CreatedAt int64        `json:"createdAt,omitempty"`
+	ActiveAt  int64        `json:"activeAt,omitempty"`
+	ExpiresAt int64        `json:"expiresAt,omitempty"`
+	Scopes    []TokenScope `json:"scopes,omitempty"`
+}
+
+// TokenScope is a scope of a token. Team scopes have the type "team" and
+// carry the TeamID.
+type TokenScope struct {
+	Type   string `json:"type"`
+	TeamID string `json:"teamId,omitempty"`
+}
+
+// TeamID returns the team the token is scoped to, or an empty string for
+// tokens with personal account scope.
+func (t Token) TeamID() string {
+	for _, s := range t.Scopes {
+		if s.Type == "team" && s.TeamID != "" {
+			return s.TeamID
+		}
+	}
+
+	return ""
+}
+
+// ListAuthTokensRequest is the request of ListAuthTokens.
+type ListAuthTokensRequest struct {
+	// Limit is the page size. Zero uses the Vercel default.
+	Limit int
+	// Until is the pagination cursor returned as Pagination.Next by the previous page.
+	Until int64
+}
+
+// ListAuthTokensResponse is a page of tokens returned by ListAuthTokens.
+type ListAuthTokensResponse struct {
+	Tokens     []Token    `json:"tokens"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Pagination describes a page of a list response. Next is the cursor of the
+// next page, and nil on the last page.
+type Pagination struct {
+	Count int    `json:"count"`
+	Next  *int64 `json:"next"`
+	Prev  *int64 `json:"prev"`
+}
+
+// GetAuthTokenRequest is the request of GetAuthToken.
+type GetAuthTokenRequest struct {
+	ID string `json:"id"`
+}
+
+// GetAuthTokenResponse is the response of GetAuthToken.
+type GetAuthTokenResponse struct {
+	Token Token `json:"token"`
+}
+
+// DeleteAuthTokenRequest is the request of DeleteAuthToken.
+type DeleteAuthTokenRequest struct {
+	ID string `json:"id"`
+}
+
+// DeleteAuthTokenResponse is the response of DeleteAuthToken, holding the ID
+// of the deleted token.
+type DeleteAuthTokenResponse struct {
+	ID string `json:"tokenId"`
+}
+
+// CreateAuthToken creates an access token. The request is not retried after a
+// 5xx status or a network error, so that a token is never created twice.
+func (c *Client) CreateAuthToken(ctx context.Context,
+	req *CreateAuthTokenRequest) (*CreateAuthTokenResponse, error) {
+	resp := &CreateAuthTokenResponse{}
+
+	if req == nil {
+		return nil, errEmptyReq
+	}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	p := make(map[string]string, 1)
+	if req.TeamID != "" {
+		p["teamId"] = req.TeamID
+	}
+
+	res, err := c.do(ctx, http.MethodPost, "/v3/user/tokens", b, p)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !successStatus(res.StatusCode) {
+		return nil, newHTTPErrorFromResponse(res, body)
+	}
+
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Token.ID == "" || resp.BearerToken == "" {
+		return nil, errInvalidCreateAuthTokenResponse
+	}
+
+	return resp, nil
+}
+
+// DeleteAuthToken deletes an access token. Deleting a token that does not
+// exist fails with an error matching ErrNotFound.
+func (c *Client) DeleteAuthToken(ctx context.Context,
+	req *DeleteAuthTokenRequest) (*DeleteAuthTokenResponse, error) {
+	resp := &DeleteAuthTokenResponse{}
+
+	if req == nil {
+		return nil, errEmptyReq
+	}
+
+	if req.ID == "" {
+		return nil, errMissingTokenID
+	}
+
+	path := fmt.Sprintf("%s/%s", "/v3/user/tokens", url.PathEscape(req.ID))
+
+	res, err := c.do(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !successStatus(res.StatusCode) {
+		return nil, newHTTPErrorFromResponse(res, body)
+	}
+
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.ID == "" {
+		return nil, errInvalidDeleteAuthTokenResponse
+	}
+
+	return resp, nil
+}
+
+// GetAuthToken returns the metadata of an access token.
+func (c *Client) GetAuthToken(ctx context.Context,
+	req *GetAuthTokenRequest) (*GetAuthTokenResponse, error) {
+	resp := &GetAuthTokenResponse{}
+
+	if req == nil {
+		return nil, errEmptyReq
+	}
+
+	if req.ID == "" {
+		return nil, errMissingTokenID
+	}
+
+	path := fmt.Sprintf("%s/%s", "/v5/user/tokens", url.PathEscape(req.ID))
+
+	res, err := c.do(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !successStatus(res.StatusCode) {
+		return nil, newHTTPErrorFromResponse(res, body)
+	}
+
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Token.ID == "" {
+		return nil, errInvalidGetAuthTokenResponse
+	}
+
+	return resp, nil
+}
+
+// ListAuthTokens returns a page of the access tokens of the account. Use
+// AuthTokens to iterate over every page.
+func (c *Client) ListAuthTokens(ctx context.Context,
+	req *ListAuthTokensRequest) (*ListAuthTokensResponse, error) {
+	resp := &ListAuthTokensResponse{}
+
+	if req == nil {
+		return nil, errEmptyReq
+	}
+
+	p := make(map[string]string, 2)
+	if req.Limit > 0 {
+		p["limit"] = strconv.Itoa(req.Limit)
+	}
+
+	if req.Until > 0 {
+		p["until"] = strconv.FormatInt(req.Until, 10)
+	}
+
+	res, err := c.do(ctx, http.MethodGet, "/v5/user/tokens", nil, p)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !successStatus(res.StatusCode) {
+		return nil, newHTTPErrorFromResponse(res, body)
+	}
+
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func successStatus(statusCode int) bool {
+	return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+}
@@ -1,4 +1,4 @@
-package client
+package vercel
 
 import (
 	"context"
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/thevilledev/vault-plugin-secrets-vercel/internal/fakevercel"
 	"gopkg.in/dnaeon/go-vcr.v3/recorder"
 )
 
@@ -31,7 +32,7 @@ func TestTokenMock(t *testing.T) {
 		ctx := context.Background()
 		hc := &http.Client{}
 
-		k := NewAPIClientWithBaseURL("foo", hc, ts.URL)
+		k := NewClient("foo", WithHTTPClient(hc), WithBaseURL(ts.URL))
 		r, err := k.CreateAuthToken(ctx, &CreateAuthTokenRequest{Name: "foo"})
 		require.Nil(t, r)
 		require.Error(t, err)
@@ -44,7 +45,7 @@ func TestTokenMock(t *testing.T) {
 	t.Run("create token bogus url", func(t *testing.T) {
 		ctx := context.Background()
 		hc := &http.Client{}
-		k := NewAPIClientWithBaseURL("foo", hc, "http://localhost:69696")
+		k := NewClient("foo", WithHTTPClient(hc), WithBaseURL("http://localhost:69696"))
 		r, err := k.CreateAuthToken(ctx, &CreateAuthTokenRequest{Name: "foo"})
 		require.Nil(t, r)
 		require.Error(t, err)
@@ -54,7 +55,7 @@ func TestTokenMock(t *testing.T) {
 		ctx := context.Background()
 		hc := &http.Client{}
 
-		k := NewAPIClientWithBaseURL("foo", hc, ts.URL)
+		k := NewClient("foo", WithHTTPClient(hc), WithBaseURL(ts.URL))
 		r, err := k.DeleteAuthToken(ctx, &DeleteAuthTokenRequest{ID: "foo"})
 		require.Nil(t, r)
 		require.Error(t, err)
@@ -67,7 +68,7 @@ func TestTokenMock(t *testing.T) {
 	t.Run("delete token bogus url", func(t *testing.T) {
 		ctx := context.Background()
 		hc := &http.Client{}
-		k := NewAPIClientWithBaseURL("foo", hc, "http://localhost:69696")
+		k := NewClient("foo", WithHTTPClient(hc), WithBaseURL("http://localhost:69696"))
 		r, err := k.DeleteAuthToken(ctx, &DeleteAuthTokenRequest{ID: "foo"})
 		require.Nil(t, r)
 		require.Error(t, err)
@@ -91,7 +92,7 @@ func TestTokenResponseHardening(t *testing.T) {
 		)
 		defer srv.Close()
 
-		c := NewAPIClientWithBaseURL("foo", nil, srv.URL)
+		c := NewClient("foo", WithBaseURL(srv.URL))
 		res, err := c.CreateAuthToken(ctx, &CreateAuthTokenRequest{Name: "foo"})
 		require.Nil(t, res)
 
@@ -116,7 +117,7 @@ func TestTokenResponseHardening(t *testing.T) {
 		)
 		defer srv.Close()
 
-		c := NewAPIClientWithBaseURL("foo", nil, srv.URL)
+		c := NewClient("foo", WithBaseURL(srv.URL))
 		res, err := c.CreateAuthToken(ctx, &CreateAuthTokenRequest{Name: "foo"})
 		require.Nil(t, res)
 
@@ -141,7 +142,7 @@ func TestTokenResponseHardening(t *testing.T) {
 		)
 		defer srv.Close()
 
-		c := NewAPIClientWithBaseURL("foo", nil, srv.URL)
+		c := NewClient("foo", WithBaseURL(srv.URL))
 		res, err := c.CreateAuthToken(ctx, &CreateAuthTokenRequest{Name: "foo"})
 		require.Nil(t, res)
 		require.ErrorIs(t, err, errInvalidCreateAuthTokenResponse)
@@ -163,7 +164,7 @@ func TestTokenResponseHardening(t *testing.T) {
 		)
 		defer srv.Close()
 
-		c := NewAPIClientWithBaseURL("foo", nil, srv.URL+"/v3/")
+		c := NewClient("foo", WithBaseURL(srv.URL))
 		res, err := c.DeleteAuthToken(ctx, &DeleteAuthTokenRequest{ID: tokenID})
 		require.NoError(t, err)
 		require.Equal(t, tokenID, res.ID)
@@ -173,7 +174,7 @@ func TestTokenResponseHardening(t *testing.T) {
 		t.Parallel()
 
 		ctx := context.Background()
-		c := NewAPIClientWithBaseURL("foo", nil, "https://example.com")
+		c := NewClient("foo", WithBaseURL("https://example.com"))
 		res, err := c.DeleteAuthToken(ctx, &DeleteAuthTokenRequest{})
 		require.Nil(t, res)
 		require.ErrorIs(t, err, errMissingTokenID)
@@ -193,7 +194,7 @@ func TestTokenResponseHardening(t *testing.T) {
 		)
 		defer srv.Close()
 
-		c := NewAPIClientWithBaseURL("foo", nil, srv.URL)
+		c := NewClient("foo", WithBaseURL(srv.URL))
 		res, err := c.DeleteAuthToken(ctx, &DeleteAuthTokenRequest{ID: "foo"})
 		require.Nil(t, res)
 		require.ErrorIs(t, err, errInvalidDeleteAuthTokenResponse)
@@ -202,7 +203,7 @@ func TestTokenResponseHardening(t *testing.T) {
 	t.Run("http error supports errors as", func(t *testing.T) {
 		t.Parallel()
 
-		err := error(newHTTPError(http.StatusTooManyRequests, []byte("rate limited")))
+		err := error(NewHTTPError(http.StatusTooManyRequests, []byte("rate limited")))
 		var httpErr *HTTPError
 		require.True(t, errors.As(err, &httpErr))
 		require.Equal(t, http.StatusTooManyRequests, httpErr.StatusCode)
@@ -212,7 +213,7 @@ func TestTokenResponseHardening(t *testing.T) {
 func TestCreateDeleteToken(t *testing.T) {
 	t.Parallel()
 
-	recordHelper(t, "auth_token", func(ctx context.Context, t *testing.T, rec *recorder.Recorder, c *APIClient) {
+	recordHelper(t, "auth_token", func(ctx context.Context, t *testing.T, rec *recorder.Recorder, c *Client) {
 		t.Helper()
 
 		require.NotNil(t, c.httpClient)
@@ -245,7 +246,7 @@ func TestCreateDeleteToken(t *testing.T) {
 
 func TestCreateDeleteTokenTeam(t *testing.T) {
 	t.Parallel()
-	recordHelper(t, "auth_token_team", func(ctx context.Context, t *testing.T, rec *recorder.Recorder, c *APIClient) {
+	recordHelper(t, "auth_token_team", func(ctx context.Context, t *testing.T, rec *recorder.Recorder, c *Client) {
 		t.Helper()
 
 		require.NotNil(t, c.httpClient)
@@ -267,3 +268,86 @@ func TestCreateDeleteTokenTeam(t *testing.T) {
 		require.Equal(t, res2.ID, res.Token.ID)
 	})
 }
+
+func TestGetAuthToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			t.Helper()
+
+			switch r.URL.EscapedPath() {
+			case "/v5/user/tokens/tok_a":
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"token":{"id":"tok_a","name":"a","createdAt":1000,"activeAt":2000}}`))
+			case "/v5/user/tokens/tok_empty":
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"token":{}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":{"code":"not_found","message":"Token not found"}}`))
+			}
+		}),
+	)
+	t.Cleanup(srv.Close)
+
+	c := NewClient("foo", WithBaseURL(srv.URL))
+
+	res, err := c.GetAuthToken(ctx, &GetAuthTokenRequest{ID: "tok_a"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), res.Token.CreatedAt)
+	require.Equal(t, int64(2000), res.Token.ActiveAt)
+
+	_, err = c.GetAuthToken(ctx, &GetAuthTokenRequest{ID: "tok_empty"})
+	require.ErrorIs(t, err, errInvalidGetAuthTokenResponse)
+
+	_, err = c.GetAuthToken(ctx, &GetAuthTokenRequest{ID: "tok_b"})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = c.GetAuthToken(ctx, &GetAuthTokenRequest{})
+	require.ErrorIs(t, err, errMissingTokenID)
+
+	_, err = c.GetAuthToken(ctx, nil)
+	require.ErrorIs(t, err, errEmptyReq)
+}
+
+func TestListAuthTokens(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	_, ts := fakevercel.NewHTTPTestServer(fakevercel.Config{APIKey: "foo", Teams: []string{"team_a"}})
+	t.Cleanup(ts.Close)
+
+	c := NewClient("foo", WithBaseURL(ts.URL))
+
+	for i := 0; i < 3; i++ {
+		req := &CreateAuthTokenRequest{Name: fmt.Sprintf("token-%d", i)}
+		if i == 0 {
+			req.TeamID = "team_a"
+		}
+
+		_, err := c.CreateAuthToken(ctx, req)
+		require.NoError(t, err)
+	}
+
+	res, err := c.ListAuthTokens(ctx, &ListAuthTokensRequest{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, res.Tokens, 2)
+	require.Equal(t, "token-2", res.Tokens[0].Name)
+	require.NotNil(t, res.Pagination.Next)
+
+	res, err = c.ListAuthTokens(ctx, &ListAuthTokensRequest{Limit: 2, Until: *res.Pagination.Next})
+	require.NoError(t, err)
+	require.Len(t, res.Tokens, 1)
+	require.Equal(t, "token-0", res.Tokens[0].Name)
+	require.Equal(t, "team_a", res.Tokens[0].TeamID())
+	require.Nil(t, res.Pagination.Next)
+
+	_, err = c.ListAuthTokens(ctx, nil)
+	require.ErrorIs(t, err, errEmptyReq)
+
+	_, err = NewClient("bogus", WithBaseURL(ts.URL)).ListAuthTokens(ctx, &ListAuthTokensRequest{})
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+}
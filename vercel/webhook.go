@@ -0,0 +1,127 @@
+package vercel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// CreateWebhookRequest is the request of CreateWebhook.
+type CreateWebhookRequest struct {
+	// TeamID is the team the webhook is registered for. Empty registers it
+	// for the personal account.
+	TeamID string `json:"-"`
+	// URL is the HTTPS endpoint the events are delivered to.
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// ProjectIDs limits the webhook to the events of the given projects.
+	// Empty delivers the events of every project.
+	ProjectIDs []string `json:"projectIds,omitempty"`
+}
+
+// CreateWebhookResponse is the response of CreateWebhook.
+type CreateWebhookResponse struct {
+	Webhook
+	// Secret is the signing secret of the webhook. It is only returned on creation.
+	Secret string `json:"secret"`
+}
+
+// Webhook is a webhook registered on Vercel.
+type Webhook struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	Events     []string `json:"events"`
+	ProjectIDs []string `json:"projectIds,omitempty"`
+	OwnerID    string   `json:"ownerId"`
+	// CreatedAt is a Unix timestamp in milliseconds.
+	CreatedAt int64 `json:"createdAt"`
+}
+
+// DeleteWebhookRequest is the request of DeleteWebhook.
+type DeleteWebhookRequest struct {
+	ID     string
+	TeamID string
+}
+
+// CreateWebhook registers a webhook for the given events.
+func (c *Client) CreateWebhook(ctx context.Context, req *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+	resp := &CreateWebhookResponse{}
+
+	if req == nil {
+		return nil, errEmptyReq
+	}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	p := make(map[string]string, 1)
+	if req.TeamID != "" {
+		p["teamId"] = req.TeamID
+	}
+
+	res, err := c.do(ctx, http.MethodPost, "/v1/webhooks", b, p)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if !successStatus(res.StatusCode) {
+		return nil, newHTTPErrorFromResponse(res, body)
+	}
+
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.ID == "" || resp.Secret == "" {
+		return nil, errInvalidCreateWebhookResponse
+	}
+
+	return resp, nil
+}
+
+// DeleteWebhook deletes a webhook. Deleting a webhook that does not exist
+// fails with an error matching ErrNotFound.
+func (c *Client) DeleteWebhook(ctx context.Context, req *DeleteWebhookRequest) error {
+	if req == nil {
+		return errEmptyReq
+	}
+
+	if req.ID == "" {
+		return errMissingWebhookID
+	}
+
+	p := make(map[string]string, 1)
+	if req.TeamID != "" {
+		p["teamId"] = req.TeamID
+	}
+
+	res, err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/v1/webhooks/%s", url.PathEscape(req.ID)), nil, p)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if !successStatus(res.StatusCode) {
+		return newHTTPErrorFromResponse(res, body)
+	}
+
+	return nil
+}
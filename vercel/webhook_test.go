@@ -0,0 +1,109 @@
+package vercel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("create and delete", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+				require.Equal(t, "team_a", r.URL.Query().Get("teamId"))
+
+				switch r.Method {
+				case http.MethodPost:
+					require.Equal(t, "/v1/webhooks", r.URL.EscapedPath())
+
+					var body map[string]any
+					require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+					require.Equal(t, map[string]any{
+						"url":        "https://example.com/hook",
+						"events":     []any{"deployment.created"},
+						"projectIds": []any{"prj_a"},
+					}, body)
+
+					w.WriteHeader(http.StatusOK)
+					_, _ = w.Write([]byte(`{"id":"hook_a","url":"https://example.com/hook",` +
+						`"events":["deployment.created"],"projectIds":["prj_a"],"ownerId":"team_a",` +
+						`"createdAt":1,"secret":"s3cr3t"}`))
+				case http.MethodDelete:
+					require.Equal(t, "/v1/webhooks/hook_a", r.URL.EscapedPath())
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}),
+		)
+		defer srv.Close()
+
+		c := NewClient("foo", WithBaseURL(srv.URL))
+
+		res, err := c.CreateWebhook(ctx, &CreateWebhookRequest{
+			TeamID:     "team_a",
+			URL:        "https://example.com/hook",
+			Events:     []string{"deployment.created"},
+			ProjectIDs: []string{"prj_a"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "hook_a", res.ID)
+		require.Equal(t, "s3cr3t", res.Secret)
+		require.Equal(t, []string{"prj_a"}, res.ProjectIDs)
+
+		require.NoError(t, c.DeleteWebhook(ctx, &DeleteWebhookRequest{ID: "hook_a", TeamID: "team_a"}))
+	})
+
+	t.Run("invalid create response", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"id":"hook_a"}`))
+			}),
+		)
+		defer srv.Close()
+
+		c := NewClient("foo", WithBaseURL(srv.URL))
+		_, err := c.CreateWebhook(ctx, &CreateWebhookRequest{URL: "https://example.com", Events: []string{"a"}})
+		require.ErrorIs(t, err, errInvalidCreateWebhookResponse)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, _ *http.Request) {
+				t.Helper()
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":{"code":"not_found","message":"Webhook not found"}}`))
+			}),
+		)
+		defer srv.Close()
+
+		c := NewClient("foo", WithBaseURL(srv.URL))
+		require.ErrorIs(t, c.DeleteWebhook(ctx, &DeleteWebhookRequest{ID: "hook_a"}), ErrNotFound)
+	})
+
+	t.Run("validates request", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewClient("foo", WithBaseURL("https://example.com"))
+
+		_, err := c.CreateWebhook(ctx, nil)
+		require.ErrorIs(t, err, errEmptyReq)
+		require.ErrorIs(t, c.DeleteWebhook(ctx, nil), errEmptyReq)
+		require.ErrorIs(t, c.DeleteWebhook(ctx, &DeleteWebhookRequest{}), errMissingWebhookID)
+	})
+}